@@ -335,7 +335,7 @@ var testRunnerFlag = &cli.StringFlag{
 var resultPathFlag = &cli.StringFlag{
 	Name:        "result-path",
 	Category:    "TEST RUNNER",
-	Usage:       "Path to the output file for the test runner",
+	Usage:       "Path to the output file for the test runner. The custom, cypress, minitest, gradle, maven, dotnet, jest and playwright runners also accept a glob matching several output files",
 	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_RESULT_PATH"),
 	Destination: &cfg.ResultPath,
 }
@@ -376,6 +376,54 @@ var locationPrefixFlag = &cli.StringFlag{
 	Destination: &cfg.LocationPrefix,
 }
 
+var goTestBinaryDirFlag = &cli.StringFlag{
+	Name:        "go-test-binary-dir",
+	Category:    "TEST RUNNER",
+	Usage:       "Directory to compile Go test binaries into. When set, the gotest runner builds each package once with `go test -c` and reuses the binaries across retries",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_GO_TEST_BINARY_DIR"),
+	Destination: &cfg.GoTestBinaryDir,
+}
+
+var runnerDefinitionsFlag = &cli.StringFlag{
+	Name:        "runner-definitions",
+	Category:    "TEST RUNNER",
+	Usage:       "Path to a YAML or JSON file defining additional test runners, which can be selected with --test-runner",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_RUNNER_DEFINITIONS"),
+	Destination: &cfg.RunnerDefinitionsFile,
+}
+
+var runnerPluginFlag = &cli.StringFlag{
+	Name:        "runner-plugin",
+	Category:    "TEST RUNNER",
+	Usage:       "Command running the executable of a runner plugin, used with --test-runner plugin. The plugin receives requests as JSON on stdin and responds on stdout",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_RUNNER_PLUGIN"),
+	Destination: &cfg.RunnerPlugin,
+}
+
+var planCacheFileFlag = &cli.StringFlag{
+	Name:        "plan-cache-file",
+	Category:    "TEST ENGINE",
+	Usage:       "Path to a local file to save the timings of the last test plan to. When the Test Engine API is unavailable, the fallback plan uses these timings to balance tests across nodes",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_PLAN_CACHE_FILE"),
+	Destination: &cfg.PlanCacheFile,
+}
+
+var timingsFileFlag = &cli.StringFlag{
+	Name:        "timings-file",
+	Category:    "TEST ENGINE",
+	Usage:       "Path to a JSON or CSV file of test paths and durations in milliseconds. When the Test Engine API is unavailable, the fallback plan uses these timings to balance tests across nodes",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_TIMINGS_FILE"),
+	Destination: &cfg.TimingsFile,
+}
+
+var quarantineFileFlag = &cli.StringFlag{
+	Name:        "quarantine-file",
+	Category:    "TEST ENGINE",
+	Usage:       "Path to a YAML or JSON file listing tests to mute by scope, name or path glob. Failures of these tests don't fail the build, including when the Test Engine API is unavailable",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_QUARANTINE_FILE"),
+	Destination: &cfg.QuarantineFile,
+}
+
 // Test Runner Retry Flags
 var testEngineRetryCountFlag = &cli.IntFlag{
 	Name:        "test-engine-retry-count",
@@ -437,6 +485,15 @@ var promiseFailureFlag = &cli.BoolFlag{
 	Destination: &cfg.PromiseFailure,
 }
 
+var annotateFlag = &cli.BoolFlag{
+	Name:        "annotate",
+	Category:    "TEST ENGINE",
+	Usage:       "Post a report of failed, flaky and muted tests as a Buildkite annotation via the `buildkite-agent annotate` CLI. Parallel nodes append to the same annotation. Opt-in.",
+	Value:       false,
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_ANNOTATE"),
+	Destination: &cfg.Annotate,
+}
+
 // `run` and `plan` command flags
 var planIdentifierFlag = &cli.StringFlag{
 	Name:        "plan-identifier",
@@ -446,6 +503,34 @@ var planIdentifierFlag = &cli.StringFlag{
 	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_PLAN_IDENTIFIER"),
 }
 
+// `run` command flags
+var dryRunFlag = &cli.BoolFlag{
+	Name:        "dry-run",
+	Usage:       "Resolve the test plan and print the test runner command and the tests assigned to this node, without running them",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_DRY_RUN"),
+	Destination: &cfg.DryRun,
+}
+
+var reportJUnitFlag = &cli.StringFlag{
+	Name:        "report-junit",
+	Usage:       "Write a JUnit XML report of the whole run, including retries, to this path",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_REPORT_JUNIT"),
+	Destination: &cfg.ReportJUnitPath,
+}
+
+var summaryJSONFlag = &cli.StringFlag{
+	Name:        "summary-json",
+	Usage:       "Write a JSON summary of the run, including statistics, failed, muted and skipped tests, to this path",
+	Sources:     cli.EnvVars("BUILDKITE_TEST_ENGINE_SUMMARY_JSON"),
+	Destination: &cfg.SummaryJSONPath,
+}
+
+var allNodesFlag = &cli.BoolFlag{
+	Name:        "all-nodes",
+	Usage:       "With --dry-run, print the command and tests of every node in the plan",
+	Destination: &cfg.AllNodes,
+}
+
 // `plan` command flags
 var maxParallelismFlag = &cli.IntFlag{
 	Name:        "max-parallelism",
@@ -554,6 +639,23 @@ func backfillCommitMetadataFlags() []cli.Flag {
 	}
 }
 
+var runFlag = &cli.BoolFlag{
+	Name:  "run",
+	Usage: "Run the discovered tests before checking that their results are parsed",
+}
+
+func checkRunnerPluginFlags() []cli.Flag {
+	return freshFlags([]cli.Flag{
+		runnerPluginFlag,
+		testCommandFlag,
+		retryCommandFlag,
+		testFilePatternFlag,
+		testFileExcludePatternFlag,
+		resultPathFlag,
+		runFlag,
+	})
+}
+
 var uploadFlag = &cli.StringFlag{
 	Name:        "upload",
 	Category:    "BACKFILL",
@@ -594,6 +696,9 @@ var runnerEnvironmentFlags = []cli.Flag{
 	selectorSplittingCompatibilityFlag,
 	selectorsFlag,
 	locationPrefixFlag,
+	goTestBinaryDirFlag,
+	runnerDefinitionsFlag,
+	runnerPluginFlag,
 	// Runner Retry Flags
 	disableRetryMutedFlag,
 	retryCommandFlag,
@@ -666,6 +771,13 @@ func runCommandFlags() []cli.Flag {
 		filesFlag,
 		tagFiltersFlag,
 		planIdentifierFlag,
+		planCacheFileFlag,
+		timingsFileFlag,
+		quarantineFileFlag,
+		dryRunFlag,
+		allNodesFlag,
+		reportJUnitFlag,
+		summaryJSONFlag,
 	}
 	flags = append(flags, buildEnvironmentFlags...)
 	flags = append(flags, testEngineFlags...)
@@ -673,6 +785,7 @@ func runCommandFlags() []cli.Flag {
 	flags = append(flags, parallelismFlag)
 	flags = append(flags, failOnNoTestsFlag)
 	flags = append(flags, promiseFailureFlag)
+	flags = append(flags, annotateFlag)
 	flags = append(flags, previewSelectionFlags()...)
 	return freshFlags(flags)
 }
@@ -727,13 +840,19 @@ var cliCommand = &cli.Command{
 			},
 		},
 		{
-			Name:   "tools",
-			Usage:  "Utility tools",
-			Hidden: !previewSelectionEnabled(),
+			Name:  "tools",
+			Usage: "Utility tools",
 			Commands: []*cli.Command{
+				{
+					Name:   "check-runner-plugin",
+					Usage:  "Check that a runner plugin implements the runner plugin protocol",
+					Action: checkRunnerPlugin,
+					Flags:  checkRunnerPluginFlags(),
+				},
 				{
 					Name:   "backfill-commit-metadata",
 					Usage:  "Collect historical git commit metadata and upload to Buildkite",
+					Hidden: !previewSelectionEnabled(),
 					Action: backfillCommitMetadata,
 					Flags:  backfillCommitMetadataFlags(),
 				},
@@ -250,10 +250,19 @@ func TestRunCommandEnvVarsBindToConfig(t *testing.T) {
 	t.Setenv("BUILDKITE_TEST_ENGINE_SELECTOR_FILE", "selectors.txt")
 	t.Setenv("BUILDKITE_TEST_ENGINE_FAIL_ON_NO_TESTS", "true")
 	t.Setenv("BUILDKITE_TEST_ENGINE_LOCATION_PREFIX", "app/")
+	t.Setenv("BUILDKITE_TEST_ENGINE_GO_TEST_BINARY_DIR", "tmp/go-test-binaries")
+	t.Setenv("BUILDKITE_TEST_ENGINE_RUNNER_DEFINITIONS", ".buildkite/runners.yml")
+	t.Setenv("BUILDKITE_TEST_ENGINE_RUNNER_PLUGIN", "bin/bazel-runner-plugin")
 	t.Setenv("BUILDKITE_TEST_ENGINE_RETRY_COUNT", "3")
 	t.Setenv("BUILDKITE_TEST_ENGINE_DISABLE_RETRY_FOR_MUTED_TEST", "true")
 	t.Setenv("BUILDKITE_TEST_ENGINE_RETRY_CMD", "go test -run .")
 	t.Setenv("BUILDKITE_TEST_ENGINE_PLAN_IDENTIFIER", "my-plan")
+	t.Setenv("BUILDKITE_TEST_ENGINE_PLAN_CACHE_FILE", "tmp/bktec-plan-cache.json")
+	t.Setenv("BUILDKITE_TEST_ENGINE_TIMINGS_FILE", "timings.csv")
+	t.Setenv("BUILDKITE_TEST_ENGINE_QUARANTINE_FILE", ".buildkite/quarantine.yml")
+	t.Setenv("BUILDKITE_TEST_ENGINE_REPORT_JUNIT", "tmp/bktec-junit.xml")
+	t.Setenv("BUILDKITE_TEST_ENGINE_SUMMARY_JSON", "tmp/bktec-summary.json")
+	t.Setenv("BUILDKITE_TEST_ENGINE_ANNOTATE", "true")
 	t.Setenv("BUILDKITE_TEST_ENGINE_DEBUG_ENABLED", "true")
 	t.Setenv("BUILDKITE_TEST_ENGINE_OIDC", "false")
 	t.Setenv("BUILDKITE_TEST_ENGINE_OIDC_LIFETIME", "1h")
@@ -303,11 +312,20 @@ func TestRunCommandEnvVarsBindToConfig(t *testing.T) {
 		{"SelectorListPath", cfg.SelectorListPath, "selectors.txt"},
 		{"FailOnNoTests", cfg.FailOnNoTests, true},
 		{"LocationPrefix", cfg.LocationPrefix, "app/"},
+		{"GoTestBinaryDir", cfg.GoTestBinaryDir, "tmp/go-test-binaries"},
+		{"RunnerDefinitionsFile", cfg.RunnerDefinitionsFile, ".buildkite/runners.yml"},
+		{"RunnerPlugin", cfg.RunnerPlugin, "bin/bazel-runner-plugin"},
 		{"MaxRetries", cfg.MaxRetries, 3},
 		// DISABLE_RETRY_FOR_MUTED_TEST=true means RetryForMutedTest should be false (flag Action inverts the bool)
 		{"RetryForMutedTest", cfg.RetryForMutedTest, false},
 		{"RetryCommand", cfg.RetryCommand, "go test -run ."},
 		{"Identifier", cfg.Identifier, "my-plan"},
+		{"PlanCacheFile", cfg.PlanCacheFile, "tmp/bktec-plan-cache.json"},
+		{"TimingsFile", cfg.TimingsFile, "timings.csv"},
+		{"QuarantineFile", cfg.QuarantineFile, ".buildkite/quarantine.yml"},
+		{"ReportJUnitPath", cfg.ReportJUnitPath, "tmp/bktec-junit.xml"},
+		{"SummaryJSONPath", cfg.SummaryJSONPath, "tmp/bktec-summary.json"},
+		{"Annotate", cfg.Annotate, true},
 		{"DebugEnabled", cfg.DebugEnabled, true},
 		{"OIDC", cfg.OIDC, false},
 		{"OIDCLifetime", cfg.OIDCLifetime, time.Hour},
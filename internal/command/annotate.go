@@ -0,0 +1,198 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+)
+
+// annotateTimeout bounds the best-effort annotate call so a slow or hung
+// agent can never stall job completion.
+const annotateTimeout = 10 * time.Second
+
+// maxAnnotatedTests is the maximum number of tests listed in each section of
+// the annotation, which keeps it well below the agent's annotation size limit.
+const maxAnnotatedTests = 50
+
+// maxAnnotatedFailureLines is the maximum number of lines of the failure message,
+// and of the backtrace, shown for each test.
+const maxAnnotatedFailureLines = 10
+
+// annotateIfNeeded posts the end-of-run report as a Buildkite annotation via the
+// `buildkite-agent annotate` CLI when the opt-in flag is enabled and there is
+// something worth reporting: failed tests, tests that passed on retry, muted failures,
+// or a runner error.
+//
+// All nodes of a parallel step use the same context and append to it, so they
+// share one annotation. Like promiseFailureIfNeeded, it is best-effort: any error
+// is logged and swallowed so it never changes the test run's real exit status.
+func annotateIfNeeded(ctx context.Context, cfg *config.Config, runResult runner.RunResult, runnerName string) {
+	if !cfg.Annotate {
+		return
+	}
+
+	body := renderAnnotation(cfg, runResult, runnerName)
+	if body == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, annotateTimeout)
+	defer cancel()
+
+	cmd := makeAnnotateCommand(ctx, cfg, annotationStyle(runResult), body)
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Buildkite Test Engine Client: Warning: failed to run annotate command: %v\n", err)
+	}
+}
+
+// annotationStyle returns the style of the annotation from the worst result of the run:
+// error when tests failed or the runner errored, warning when only muted tests failed,
+// and info otherwise, i.e. when tests only passed on retry.
+func annotationStyle(runResult runner.RunResult) string {
+	switch {
+	case runResult.Status() == runner.RunStatusFailed || runResult.Status() == runner.RunStatusError:
+		return "error"
+	case runResult.Statistics().MutedFailed > 0:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// annotationContext returns the annotation context shared by all nodes of the step.
+func annotationContext(cfg *config.Config) string {
+	if cfg.StepID == "" {
+		return "bktec"
+	}
+	return "bktec-" + cfg.StepID
+}
+
+// makeAnnotateCommand builds the `buildkite-agent annotate` invocation. The body is
+// passed on stdin to avoid the command line length limit. The CLI reads the build
+// and its auth from the agent env vars, all inherited from the current process.
+func makeAnnotateCommand(ctx context.Context, cfg *config.Config, style string, body string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, cfg.BuildkiteAgentCommand, "annotate", "--context", annotationContext(cfg), "--style", style, "--append") //nolint:gosec
+	cmd.Stdin = strings.NewReader(body)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// renderAnnotation renders the report of this node as Markdown.
+// It returns an empty string when there is nothing worth reporting.
+func renderAnnotation(cfg *config.Config, runResult runner.RunResult, runnerName string) string {
+	var failed, passedOnRetry, mutedFailed []runner.TestResult
+	for _, test := range runResult.Tests() {
+		switch {
+		case test.Muted && test.Status == runner.TestStatusFailed:
+			mutedFailed = append(mutedFailed, test)
+		case test.Muted:
+			continue
+		case test.Status == runner.TestStatusFailed:
+			failed = append(failed, test)
+		case test.Status == runner.TestStatusPassed && test.ExecutionCount > 1:
+			passedOnRetry = append(passedOnRetry, test)
+		}
+	}
+
+	runErr := runResult.Error()
+	if len(failed) == 0 && len(passedOnRetry) == 0 && len(mutedFailed) == 0 && runErr == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#### %s on node %d", runnerName, cfg.NodeIndex)
+	if cfg.Parallelism > 1 {
+		fmt.Fprintf(&b, " of %d", cfg.Parallelism)
+	}
+	b.WriteString("\n\n")
+
+	if runErr != nil {
+		fmt.Fprintf(&b, "🚨 %s\n\n", markdownEscape(runErr.Error()))
+	}
+
+	writeAnnotationSection(&b, "❌", "failed", failed)
+	writeAnnotationSection(&b, "🔁", "passed on retry", passedOnRetry)
+	writeAnnotationSection(&b, "🔇", "muted and failed", mutedFailed)
+
+	return b.String()
+}
+
+func writeAnnotationSection(b *strings.Builder, icon, title string, tests []runner.TestResult) {
+	if len(tests) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "<details>\n<summary>%s %d %s %s</summary>\n\n", icon, len(tests), pluralizeTests(len(tests)), title)
+	for i, test := range tests {
+		if i == maxAnnotatedTests {
+			fmt.Fprintf(b, "- …and %d more\n", len(tests)-maxAnnotatedTests)
+			break
+		}
+
+		fmt.Fprintf(b, "- %s", markdownEscape(strings.TrimSpace(test.Scope+" "+test.Name)))
+		if test.Path != "" {
+			fmt.Fprintf(b, " (`%s`)", strings.ReplaceAll(test.Path, "`", "'"))
+		}
+		if test.ExecutionCount > 1 {
+			fmt.Fprintf(b, " after %d attempts", test.ExecutionCount)
+		}
+		b.WriteString("\n")
+
+		if failure := test.Failure(); failure != nil {
+			writeAnnotationFailure(b, failure)
+		}
+	}
+	b.WriteString("\n</details>\n\n")
+}
+
+// writeAnnotationFailure writes the failure message and the top of the backtrace
+// as a code block nested in the list item of the test.
+func writeAnnotationFailure(b *strings.Builder, failure *runner.TestFailure) {
+	var lines []string
+	if failure.Exception != "" {
+		lines = append(lines, failure.Exception+":")
+	}
+	lines = append(lines, truncateLines(strings.Split(failure.Message, "\n"), maxAnnotatedFailureLines)...)
+	lines = append(lines, truncateLines(failure.Backtrace, maxAnnotatedFailureLines)...)
+
+	// The fence has to be longer than any run of backticks in the failure.
+	fence := "```"
+	for strings.Contains(strings.Join(lines, "\n"), fence) {
+		fence += "`"
+	}
+
+	fmt.Fprintf(b, "\n  %s\n", fence)
+	for _, line := range lines {
+		fmt.Fprintf(b, "  %s\n", line)
+	}
+	fmt.Fprintf(b, "  %s\n\n", fence)
+}
+
+func truncateLines(lines []string, n int) []string {
+	if len(lines) <= n {
+		return lines
+	}
+	return append(lines[:n:n], fmt.Sprintf("…and %d more lines", len(lines)-n))
+}
+
+// markdownEscape escapes the characters that would otherwise be rendered as
+// Markdown or HTML in test names.
+func markdownEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+		"*", `\*`,
+		"_", `\_`,
+		"[", `\[`,
+		"]", `\]`,
+		"<", "&lt;",
+		">", "&gt;",
+	).Replace(s)
+}
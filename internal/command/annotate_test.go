@@ -0,0 +1,198 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRenderAnnotation(t *testing.T) {
+	flaky := plan.TestCase{Scope: "Apple", Name: "is red", Path: "apple_spec.rb:1"}
+	broken := plan.TestCase{Scope: "Banana", Name: "is <yellow>", Path: "banana_spec.rb:2"}
+	muted := plan.TestCase{Scope: "Cherry", Name: "is_sweet", Path: "cherry_spec.rb:3"}
+	passed := plan.TestCase{Scope: "Durian", Name: "smells", Path: "durian_spec.rb:4"}
+
+	runResult := runner.NewRunResult([]plan.TestCase{muted})
+	runResult.RecordTestResult(flaky, runner.TestStatusFailed)
+	runResult.RecordTestResult(broken, runner.TestStatusFailed)
+	runResult.RecordTestResult(muted, runner.TestStatusFailed)
+	runResult.RecordTestResult(passed, runner.TestStatusPassed)
+	runResult.RecordTestResult(flaky, runner.TestStatusPassed)
+	runResult.RecordTestResult(broken, runner.TestStatusFailed)
+
+	cfg := config.Config{NodeIndex: 1, Parallelism: 3}
+	got := renderAnnotation(&cfg, *runResult, "RSpec")
+
+	want := "#### RSpec on node 1 of 3\n" +
+		"\n" +
+		"<details>\n" +
+		"<summary>❌ 1 test failed</summary>\n" +
+		"\n" +
+		"- Banana is &lt;yellow&gt; (`banana_spec.rb:2`) after 2 attempts\n" +
+		"\n" +
+		"</details>\n" +
+		"\n" +
+		"<details>\n" +
+		"<summary>🔁 1 test passed on retry</summary>\n" +
+		"\n" +
+		"- Apple is red (`apple_spec.rb:1`) after 2 attempts\n" +
+		"\n" +
+		"</details>\n" +
+		"\n" +
+		"<details>\n" +
+		"<summary>🔇 1 test muted and failed</summary>\n" +
+		"\n" +
+		"- Cherry is\\_sweet (`cherry_spec.rb:3`)\n" +
+		"\n" +
+		"</details>\n" +
+		"\n"
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("renderAnnotation() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRenderAnnotation_FailureDetails(t *testing.T) {
+	broken := plan.TestCase{Scope: "Banana", Name: "is yellow", Path: "banana_spec.rb:2"}
+
+	runResult := runner.NewRunResult([]plan.TestCase{})
+	runResult.RecordTestAttempt(broken, runner.TestAttempt{
+		Status: runner.TestStatusFailed,
+		Failure: &runner.TestFailure{
+			Message:   "expected: \"yellow\"\n     got: \"green\"",
+			Exception: "RSpec::Expectations::ExpectationNotMetError",
+			Backtrace: []string{"./banana_spec.rb:3:in `block (2 levels)'"},
+		},
+	})
+
+	got := renderAnnotation(&config.Config{}, *runResult, "RSpec")
+
+	want := "- Banana is yellow (`banana_spec.rb:2`)\n" +
+		"\n" +
+		"  ```\n" +
+		"  RSpec::Expectations::ExpectationNotMetError:\n" +
+		"  expected: \"yellow\"\n" +
+		"       got: \"green\"\n" +
+		"  ./banana_spec.rb:3:in `block (2 levels)'\n" +
+		"  ```\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("renderAnnotation() = %q, want it to contain %q", got, want)
+	}
+}
+
+func TestRenderAnnotation_FailureWithBackticks(t *testing.T) {
+	runResult := runner.NewRunResult([]plan.TestCase{})
+	runResult.RecordTestAttempt(plan.TestCase{Scope: "Apple", Name: "is red"}, runner.TestAttempt{
+		Status:  runner.TestStatusFailed,
+		Failure: &runner.TestFailure{Message: "```\nnot a fence\n```"},
+	})
+
+	got := renderAnnotation(&config.Config{}, *runResult, "RSpec")
+
+	if !strings.Contains(got, "  ````\n  ```\n  not a fence\n  ```\n  ````\n") {
+		t.Errorf("renderAnnotation() = %q, want the failure in a longer fence", got)
+	}
+}
+
+func TestRenderAnnotation_NothingToReport(t *testing.T) {
+	runResult := runner.NewRunResult([]plan.TestCase{})
+	runResult.RecordTestResult(plan.TestCase{Scope: "Apple", Name: "is red", Path: "apple_spec.rb:1"}, runner.TestStatusPassed)
+	runResult.RecordTestResult(plan.TestCase{Scope: "Banana", Name: "is yellow", Path: "banana_spec.rb:2"}, runner.TestStatusSkipped)
+
+	got := renderAnnotation(&config.Config{}, *runResult, "RSpec")
+	if got != "" {
+		t.Errorf("renderAnnotation() = %q, want empty", got)
+	}
+}
+
+func TestRenderAnnotation_TruncatesLongSections(t *testing.T) {
+	runResult := runner.NewRunResult([]plan.TestCase{})
+	for i := range maxAnnotatedTests + 2 {
+		runResult.RecordTestResult(plan.TestCase{Scope: "Apple", Name: fmt.Sprintf("test %03d", i)}, runner.TestStatusFailed)
+	}
+
+	got := renderAnnotation(&config.Config{}, *runResult, "RSpec")
+
+	if !strings.Contains(got, "<summary>❌ 52 tests failed</summary>") {
+		t.Errorf("renderAnnotation() = %q, want the summary to count all failed tests", got)
+	}
+	if !strings.Contains(got, "- Apple test 049\n- …and 2 more\n") {
+		t.Errorf("renderAnnotation() = %q, want the section to be truncated after %d tests", got, maxAnnotatedTests)
+	}
+}
+
+func TestAnnotationStyle(t *testing.T) {
+	test := plan.TestCase{Scope: "Apple", Name: "is red", Path: "apple_spec.rb:1"}
+	muted := plan.TestCase{Scope: "Cherry", Name: "is sweet", Path: "cherry_spec.rb:3"}
+
+	tests := []struct {
+		name     string
+		statuses []runner.TestStatus
+		muted    bool
+		want     string
+	}{
+		{name: "failed", statuses: []runner.TestStatus{runner.TestStatusFailed}, want: "error"},
+		{name: "muted and failed", statuses: []runner.TestStatus{runner.TestStatusFailed}, muted: true, want: "warning"},
+		{name: "passed on retry", statuses: []runner.TestStatus{runner.TestStatusFailed, runner.TestStatusPassed}, want: "info"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runResult := runner.NewRunResult([]plan.TestCase{muted})
+			testCase := test
+			if tc.muted {
+				testCase = muted
+			}
+			for _, status := range tc.statuses {
+				runResult.RecordTestResult(testCase, status)
+			}
+
+			if got := annotationStyle(*runResult); got != tc.want {
+				t.Errorf("annotationStyle() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAnnotationContext(t *testing.T) {
+	tests := []struct {
+		stepID string
+		want   string
+	}{
+		{stepID: "0190-step", want: "bktec-0190-step"},
+		{stepID: "", want: "bktec"},
+	}
+
+	for _, tc := range tests {
+		got := annotationContext(&config.Config{StepID: tc.stepID})
+		if got != tc.want {
+			t.Errorf("annotationContext(StepID: %q) = %q, want %q", tc.stepID, got, tc.want)
+		}
+	}
+}
+
+func TestMakeAnnotateCommand(t *testing.T) {
+	cfg := &config.Config{BuildkiteAgentCommand: "buildkite-agent", StepID: "0190-step"}
+
+	cmd := makeAnnotateCommand(context.Background(), cfg, "error", "#### RSpec on node 0\n")
+
+	want := []string{"buildkite-agent", "annotate", "--context", "bktec-0190-step", "--style", "error", "--append"}
+	if diff := cmp.Diff(want, cmd.Args); diff != "" {
+		t.Errorf("makeAnnotateCommand() args diff (-want +got):\n%s", diff)
+	}
+
+	body, err := io.ReadAll(cmd.Stdin)
+	if err != nil {
+		t.Fatalf("io.ReadAll(cmd.Stdin) error = %v", err)
+	}
+	if string(body) != "#### RSpec on node 0\n" {
+		t.Errorf("makeAnnotateCommand() stdin = %q, want %q", body, "#### RSpec on node 0\n")
+	}
+}
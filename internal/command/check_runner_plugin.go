@@ -0,0 +1,23 @@
+package command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+)
+
+// CheckRunnerPlugin checks that the runner plugin of the config implements the plugin protocol,
+// and reports the checks to out. When run is true, the discovered tests are run before their
+// results are parsed.
+func CheckRunnerPlugin(cfg *config.Config, out io.Writer, run bool) error {
+	cfg.TestRunner = "plugin"
+	testRunner, err := runner.DetectRunner(cfg)
+	if err != nil {
+		fmt.Fprintf(out, "❌ handshake: %v\n", err)
+		return err
+	}
+
+	return runner.CheckPluginConformance(testRunner.(runner.Plugin), out, run)
+}
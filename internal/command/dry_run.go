@@ -0,0 +1,88 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+	"github.com/kballard/go-shellquote"
+)
+
+// printDryRun prints the command the test runner would execute for this node,
+// or for every node when cfg.AllNodes is set, followed by the assigned test cases.
+// Nothing is executed.
+func printDryRun(w io.Writer, cfg *config.Config, testPlan plan.TestPlan, testRunner runner.TestRunner) error {
+	nodes := []int{cfg.NodeIndex}
+	if cfg.AllNodes {
+		nodes = planNodeNumbers(testPlan)
+	}
+
+	fmt.Fprintln(w, "+++ Buildkite Test Engine Client: Dry run, no tests will be run")
+
+	for _, node := range nodes {
+		task, ok := testPlan.Tasks[strconv.Itoa(node)]
+		if !ok || len(task.Tests) == 0 {
+			fmt.Fprintf(w, "\nNode %d: no tests assigned\n", node)
+			continue
+		}
+
+		// Same as bktec run, the location prefix is removed before the tests are passed to the runner.
+		locationPrefix := testRunner.LocationPrefix()
+		if locationPrefix != "" && !testPlan.Fallback {
+			if err := trimTaskLocationPrefix(task, locationPrefix); err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintf(w, "\nNode %d: %d %s\n", node, len(task.Tests), pluralizeTests(len(task.Tests)))
+
+		// Commands over the argument limit of the OS are run in chunks, each printed with its own command.
+		for _, chunk := range runner.CommandChunks(testRunner, task.Tests, false) {
+			name, args, err := testRunner.CommandNameAndArgs(chunk, false)
+			if err != nil {
+				return fmt.Errorf("failed to build the %s command for node %d: %w", testRunner.Name(), node, err)
+			}
+			fmt.Fprintf(w, "  $ %s\n", shellquote.Join(append([]string{name}, args...)...))
+		}
+		for _, tc := range task.Tests {
+			fmt.Fprintf(w, "  - %s\n", dryRunTestCaseLabel(tc))
+		}
+	}
+
+	return nil
+}
+
+// planNodeNumbers returns the node numbers of the plan's tasks in ascending order.
+// Fallback plans don't set the parallelism, so the tasks are used instead.
+func planNodeNumbers(testPlan plan.TestPlan) []int {
+	nodes := make([]int, 0, len(testPlan.Tasks))
+	for _, task := range testPlan.Tasks {
+		nodes = append(nodes, task.NodeNumber)
+	}
+	slices.Sort(nodes)
+	return nodes
+}
+
+func dryRunTestCaseLabel(tc plan.TestCase) string {
+	label := tc.Path
+	if tc.Format == plan.TestCaseFormatSelector && tc.Value != "" {
+		label = tc.Value
+	}
+
+	if tc.Name != "" {
+		label += " (" + strings.TrimSpace(tc.Scope+" "+tc.Name) + ")"
+	}
+	return label
+}
+
+func pluralizeTests(n int) string {
+	if n == 1 {
+		return "test"
+	}
+	return "tests"
+}
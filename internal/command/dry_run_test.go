@@ -0,0 +1,93 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+	"github.com/google/go-cmp/cmp"
+)
+
+func dryRunTestPlan() plan.TestPlan {
+	return plan.TestPlan{
+		Parallelism: 3,
+		Tasks: map[string]*plan.Task{
+			"0": {NodeNumber: 0, Tests: []plan.TestCase{
+				{Path: "app/spec/a_spec.rb", Format: plan.TestCaseFormatFile},
+				{Path: "app/spec/b spec.rb", Format: plan.TestCaseFormatFile},
+			}},
+			"1": {NodeNumber: 1, Tests: []plan.TestCase{
+				{Path: "app/spec/c_spec.rb[1:2]", Format: plan.TestCaseFormatExample, Scope: "C", Name: "works"},
+			}},
+			"2": {NodeNumber: 2, Tests: []plan.TestCase{}},
+		},
+	}
+}
+
+func TestPrintDryRun(t *testing.T) {
+	cfg := config.Config{
+		TestRunner:     "rspec",
+		NodeIndex:      0,
+		ResultPath:     "tmp/rspec.json",
+		LocationPrefix: "app",
+	}
+	testRunner, err := runner.DetectRunner(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := printDryRun(&buf, &cfg, dryRunTestPlan(), testRunner); err != nil {
+		t.Fatalf("printDryRun(...) error = %v", err)
+	}
+
+	want := `+++ Buildkite Test Engine Client: Dry run, no tests will be run
+
+Node 0: 2 tests
+  $ bundle exec rspec --format progress --format json --out tmp/rspec.json spec/a_spec.rb 'spec/b spec.rb'
+  - spec/a_spec.rb
+  - spec/b spec.rb
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("printDryRun(...) diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestPrintDryRun_AllNodes(t *testing.T) {
+	cfg := config.Config{
+		TestRunner:     "rspec",
+		NodeIndex:      0,
+		ResultPath:     "tmp/rspec.json",
+		LocationPrefix: "app",
+		DryRun:         true,
+		AllNodes:       true,
+	}
+	testRunner, err := runner.DetectRunner(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := printDryRun(&buf, &cfg, dryRunTestPlan(), testRunner); err != nil {
+		t.Fatalf("printDryRun(...) error = %v", err)
+	}
+
+	want := `+++ Buildkite Test Engine Client: Dry run, no tests will be run
+
+Node 0: 2 tests
+  $ bundle exec rspec --format progress --format json --out tmp/rspec.json spec/a_spec.rb 'spec/b spec.rb'
+  - spec/a_spec.rb
+  - spec/b spec.rb
+
+Node 1: 1 test
+  $ bundle exec rspec --format progress --format json --out tmp/rspec.json spec/c_spec.rb\[1:2]
+  - spec/c_spec.rb[1:2] (C works)
+
+Node 2: no tests assigned
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("printDryRun(...) diff (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/api"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+)
+
+// slowestTestsCount is the number of tests listed in the slowest tests section of the report.
+const slowestTestsCount = 10
+
+// printSlowestTests prints the tests that took the longest to run, as reported by the test runner.
+// Nothing is printed when the runner doesn't report durations.
+func printSlowestTests(w io.Writer, runResult runner.RunResult) {
+	slowest := runResult.SlowestTests(slowestTestsCount)
+	if len(slowest) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "+++ Slowest %d %s:\n", len(slowest), pluralizeTests(len(slowest)))
+	for _, test := range slowest {
+		fmt.Fprintf(w, "- %s %s", formatDuration(test.Duration()), strings.TrimSpace(test.Scope+" "+test.Name))
+		if test.Path != "" {
+			fmt.Fprintf(w, " (%s)", test.Path)
+		}
+		if test.ExecutionCount > 1 {
+			fmt.Fprintf(w, " across %d attempts", test.ExecutionCount)
+		}
+		fmt.Fprintln(w, "")
+	}
+}
+
+// printNodeDuration prints how long this node took to run its tests, compared with the duration
+// the test plan estimated for it, so a split that was far off the prediction can be spotted.
+// The estimate doesn't account for retries, so they are reported separately.
+func printNodeDuration(w io.Writer, estimated time.Duration, timeline []api.Timeline) {
+	testRun, ok := timelineDuration(timeline, "test_start", "test_end")
+	if !ok {
+		return
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Test run duration: %s", formatDuration(testRun))
+	if estimated > 0 {
+		fmt.Fprintf(w, " (estimated %s, %s)", formatDuration(estimated), compareDurations(testRun, estimated))
+	}
+	fmt.Fprintln(w, "")
+
+	var retries time.Duration
+	for attempt := 1; ; attempt++ {
+		d, ok := timelineDuration(timeline, fmt.Sprintf("retry_%d_start", attempt), fmt.Sprintf("retry_%d_end", attempt))
+		if !ok {
+			break
+		}
+		retries += d
+	}
+	if retries > 0 {
+		fmt.Fprintf(w, "Retry duration: %s\n", formatDuration(retries))
+	}
+}
+
+// timelineDuration returns the time between the start and end events of the timeline.
+func timelineDuration(timeline []api.Timeline, startEvent, endEvent string) (time.Duration, bool) {
+	var start, end time.Time
+	for _, t := range timeline {
+		switch t.Event {
+		case startEvent:
+			start, _ = time.Parse(time.RFC3339Nano, t.Timestamp)
+		case endEvent:
+			end, _ = time.Parse(time.RFC3339Nano, t.Timestamp)
+		}
+	}
+
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0, false
+	}
+	return end.Sub(start), true
+}
+
+// compareDurations describes how much slower or faster the actual duration was than the estimate.
+func compareDurations(actual, estimated time.Duration) string {
+	percent := math.Round(math.Abs(float64(actual-estimated)) / float64(estimated) * 100)
+	switch {
+	case percent == 0:
+		return "as estimated"
+	case actual > estimated:
+		return fmt.Sprintf("%.0f%% slower", percent)
+	default:
+		return fmt.Sprintf("%.0f%% faster", percent)
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
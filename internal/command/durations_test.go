@@ -0,0 +1,98 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/api"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrintSlowestTests(t *testing.T) {
+	runResult := runner.NewRunResult([]plan.TestCase{})
+	runResult.RecordTestAttempt(plan.TestCase{Scope: "Apple", Name: "is red", Path: "apple_spec.rb:1"}, runner.TestAttempt{Status: runner.TestStatusPassed, Duration: 1234 * time.Millisecond})
+	runResult.RecordTestAttempt(plan.TestCase{Scope: "Banana", Name: "is yellow", Path: "banana_spec.rb:2"}, runner.TestAttempt{Status: runner.TestStatusFailed, Duration: 2 * time.Second})
+	runResult.RecordTestAttempt(plan.TestCase{Scope: "Banana", Name: "is yellow", Path: "banana_spec.rb:2"}, runner.TestAttempt{Status: runner.TestStatusPassed, Duration: time.Second})
+	runResult.RecordTestAttempt(plan.TestCase{Scope: "Cherry", Name: "is small"}, runner.TestAttempt{Status: runner.TestStatusPassed, Duration: 25 * time.Millisecond})
+
+	var buf bytes.Buffer
+	printSlowestTests(&buf, *runResult)
+
+	want := `
++++ Slowest 3 tests:
+- 3s Banana is yellow (banana_spec.rb:2) across 2 attempts
+- 1.2s Apple is red (apple_spec.rb:1)
+- 25ms Cherry is small
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("printSlowestTests(...) diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrintSlowestTests_NoDurations(t *testing.T) {
+	runResult := runner.NewRunResult([]plan.TestCase{})
+	runResult.RecordTestResult(plan.TestCase{Scope: "Apple", Name: "is red"}, runner.TestStatusPassed)
+
+	var buf bytes.Buffer
+	printSlowestTests(&buf, *runResult)
+
+	if buf.Len() != 0 {
+		t.Errorf("printSlowestTests(...) = %q, want nothing", buf.String())
+	}
+}
+
+func TestPrintNodeDuration(t *testing.T) {
+	timeline := []api.Timeline{
+		{Event: "test_start", Timestamp: "2025-01-01T00:00:00Z"},
+		{Event: "test_end", Timestamp: "2025-01-01T00:01:30Z"},
+		{Event: "retry_1_start", Timestamp: "2025-01-01T00:01:31Z"},
+		{Event: "retry_1_end", Timestamp: "2025-01-01T00:01:41.5Z"},
+		{Event: "retry_2_start", Timestamp: "2025-01-01T00:01:42Z"},
+		{Event: "retry_2_end", Timestamp: "2025-01-01T00:01:52Z"},
+	}
+
+	cases := []struct {
+		name      string
+		estimated time.Duration
+		want      string
+	}{
+		{
+			name:      "slower than estimated",
+			estimated: time.Minute,
+			want:      "\nTest run duration: 1m30s (estimated 1m0s, 50% slower)\nRetry duration: 20.5s\n",
+		},
+		{
+			name:      "faster than estimated",
+			estimated: 2 * time.Minute,
+			want:      "\nTest run duration: 1m30s (estimated 2m0s, 25% faster)\nRetry duration: 20.5s\n",
+		},
+		{
+			name:      "no estimate",
+			estimated: 0,
+			want:      "\nTest run duration: 1m30s\nRetry duration: 20.5s\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printNodeDuration(&buf, tc.estimated, timeline)
+
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("printNodeDuration(...) diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrintNodeDuration_NoTestRun(t *testing.T) {
+	var buf bytes.Buffer
+	printNodeDuration(&buf, time.Minute, nil)
+
+	if buf.Len() != 0 {
+		t.Errorf("printNodeDuration(...) = %q, want nothing", buf.String())
+	}
+}
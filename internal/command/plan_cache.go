@@ -0,0 +1,102 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+)
+
+// createFallbackPlan creates the fallback plan used by bktec run when the server can't provide one.
+// If a timings file is configured, or a plan cache file has timings for the suite and runner,
+// the tests are balanced across nodes using those timings. The timings file takes precedence.
+// Like the plan cache, an unreadable timings file only prints a warning, and the tests are split
+// without timings, since the fallback plan is what keeps the build running without the server.
+func createFallbackPlan(cfg *config.Config, testTargets []string) (plan.TestPlan, error) {
+	if cfg.TimingsFile != "" {
+		durations, err := plan.LoadTimingsFile(cfg.TimingsFile)
+		if err != nil {
+			printWarning(os.Stderr, fmt.Sprintf("Failed to read the timings file, splitting tests without timings: %v", err))
+			return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+		}
+
+		fmt.Fprintf(os.Stderr, "Buildkite Test Engine Client: Splitting tests using %d timings from %s\n", len(durations), cfg.TimingsFile)
+		return plan.CreateTimedFallbackPlan(testTargets, cfg.Parallelism, durations), nil
+	}
+
+	if cfg.PlanCacheFile == "" {
+		return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+	}
+
+	cache, err := plan.LoadTimingCache(cfg.PlanCacheFile)
+	if err != nil {
+		printWarning(os.Stderr, fmt.Sprintf("Failed to read the plan cache, splitting tests without timings: %v", err))
+		return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+	}
+
+	durations := cache.Durations(cfg.SuiteSlug, cfg.TestRunner)
+	if len(durations) == 0 {
+		debug.Printf("No cached timings found in %s for suite %q and runner %q", cfg.PlanCacheFile, cfg.SuiteSlug, cfg.TestRunner)
+		return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Buildkite Test Engine Client: Splitting tests using %d cached timings from %s\n", len(durations), cfg.PlanCacheFile)
+	return plan.CreateTimedFallbackPlan(testTargets, cfg.Parallelism, durations), nil
+}
+
+// savePlanCache stores the estimated durations of a plan from the server in the plan cache file,
+// so a later fallback plan can be balanced with them.
+// Failing to save the cache doesn't fail the build, since it is only used when the server is unavailable.
+// A dry run doesn't save the cache, as it only prints what would run.
+func savePlanCache(cfg *config.Config, testPlan plan.TestPlan, locationPrefix string) {
+	if cfg.PlanCacheFile == "" || cfg.DryRun {
+		return
+	}
+
+	durations := planDurations(testPlan, locationPrefix)
+	if len(durations) == 0 {
+		debug.Println("Test plan has no estimated durations, not updating the plan cache")
+		return
+	}
+
+	cache, err := plan.LoadTimingCache(cfg.PlanCacheFile)
+	if err != nil {
+		// An unreadable cache would never be used, so it's replaced.
+		debug.Printf("Replacing unreadable plan cache: %v", err)
+	}
+
+	cache.Set(cfg.SuiteSlug, cfg.TestRunner, durations, time.Now().UTC())
+	if err := cache.Save(cfg.PlanCacheFile); err != nil {
+		printWarning(os.Stderr, fmt.Sprintf("Failed to save the plan cache: %v", err))
+		return
+	}
+
+	debug.Printf("Saved %d timings to the plan cache %s", len(durations), cfg.PlanCacheFile)
+}
+
+// planDurations returns the estimated duration of each test in the plan,
+// keyed by the path or selector the runner receives.
+// Paths in a plan from the server include the location prefix, which is trimmed
+// so the keys match the test targets discovered by the runner.
+func planDurations(testPlan plan.TestPlan, locationPrefix string) map[string]int {
+	durations := make(map[string]int)
+	for _, task := range testPlan.Tasks {
+		for _, tc := range task.Tests {
+			if tc.EstimatedDuration <= 0 {
+				continue
+			}
+
+			key := tc.Path
+			if tc.Format == plan.TestCaseFormatSelector && tc.Value != "" {
+				key = tc.Value
+			} else if path, err := trimFilePathPrefix(tc.Path, locationPrefix); err == nil {
+				key = path
+			}
+			durations[key] += tc.EstimatedDuration
+		}
+	}
+	return durations
+}
@@ -0,0 +1,169 @@
+package command
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+)
+
+// junitReportTestSuites is the root element of the combined JUnit XML report.
+type junitReportTestSuites struct {
+	XMLName  xml.Name           `xml:"testsuites"`
+	Name     string             `xml:"name,attr"`
+	Tests    int                `xml:"tests,attr"`
+	Failures int                `xml:"failures,attr"`
+	Errors   int                `xml:"errors,attr"`
+	Skipped  int                `xml:"skipped,attr"`
+	Suites   []junitReportSuite `xml:"testsuite"`
+}
+
+type junitReportSuite struct {
+	Name      string                `xml:"name,attr"`
+	Tests     int                   `xml:"tests,attr"`
+	Failures  int                   `xml:"failures,attr"`
+	Errors    int                   `xml:"errors,attr"`
+	Skipped   int                   `xml:"skipped,attr"`
+	TestCases []junitReportTestCase `xml:"testcase"`
+}
+
+type junitReportTestCase struct {
+	Classname  string                `xml:"classname,attr"`
+	Name       string                `xml:"name,attr"`
+	File       string                `xml:"file,attr,omitempty"`
+	Properties []junitReportProperty `xml:"properties>property"`
+	Failure    *junitReportMessage   `xml:"failure"`
+	Error      *junitReportMessage   `xml:"error"`
+	Skipped    *junitReportMessage   `xml:"skipped"`
+	// FlakyFailures and RerunFailures follow the Maven Surefire convention for retried tests.
+	// A flaky failure is a failed attempt of a test that passed on retry,
+	// and a rerun failure is a failed retry of a test that failed on every attempt.
+	FlakyFailures []junitReportMessage `xml:"flakyFailure"`
+	RerunFailures []junitReportMessage `xml:"rerunFailure"`
+}
+
+type junitReportProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitReportMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	// Content is the stack trace of the failure.
+	Content string `xml:",chardata"`
+}
+
+// newJUnitReportFailure returns the failure element of an attempt. The failure message and
+// stack trace reported by the test runner are used when available, otherwise the attempt
+// number is used as the message.
+func newJUnitReportFailure(attempt runner.TestAttempt, attemptNumber int) junitReportMessage {
+	if attempt.Failure == nil || attempt.Failure.Message == "" && attempt.Failure.Exception == "" {
+		return junitReportMessage{Message: fmt.Sprintf("Failed on attempt %d", attemptNumber)}
+	}
+
+	return junitReportMessage{
+		Message: attempt.Failure.Message,
+		Type:    attempt.Failure.Exception,
+		Content: strings.Join(attempt.Failure.Backtrace, "\n"),
+	}
+}
+
+// writeJUnitReport writes the results of all attempts in the run to path as a single JUnit XML report.
+func writeJUnitReport(path string, runResult runner.RunResult, runnerName string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating JUnit report directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating JUnit report: %w", err)
+	}
+	defer f.Close()
+
+	if err := encodeJUnitReport(f, runResult, runnerName); err != nil {
+		return fmt.Errorf("writing JUnit report: %w", err)
+	}
+	return f.Close()
+}
+
+func encodeJUnitReport(w io.Writer, runResult runner.RunResult, runnerName string) error {
+	suite := junitReportSuite{Name: runnerName}
+
+	for _, test := range runResult.Tests() {
+		testCase := junitReportTestCase{
+			Classname: test.Scope,
+			Name:      test.Name,
+			File:      test.Path,
+			Properties: []junitReportProperty{
+				{Name: "status", Value: string(test.Status)},
+				{Name: "execution_count", Value: strconv.Itoa(test.ExecutionCount)},
+				{Name: "muted", Value: strconv.FormatBool(test.Muted)},
+				{Name: "passed_on_retry", Value: strconv.FormatBool(test.Status == runner.TestStatusPassed && test.ExecutionCount > 1)},
+			},
+		}
+
+		// Only failed tests are retried, so every attempt before the last one failed.
+		previousFailures := make([]junitReportMessage, max(test.ExecutionCount-1, 0))
+		for i := range previousFailures {
+			var attempt runner.TestAttempt
+			if i < len(test.Attempts) {
+				attempt = test.Attempts[i]
+			}
+			previousFailures[i] = newJUnitReportFailure(attempt, i+1)
+		}
+
+		switch {
+		case test.Status == runner.TestStatusFailed && test.Muted:
+			// Muted failures don't fail the build, so they are reported as skipped
+			// rather than failed for tools that read this report.
+			skipped := junitReportMessage{Message: "Muted test failed"}
+			if failure := test.Failure(); failure != nil && failure.Message != "" {
+				skipped.Message += ": " + failure.Message
+			}
+			testCase.Skipped = &skipped
+			suite.Skipped++
+		case test.Status == runner.TestStatusFailed:
+			failure := newJUnitReportFailure(test.LastAttempt(), test.ExecutionCount)
+			testCase.Failure = &failure
+			testCase.RerunFailures = previousFailures
+			suite.Failures++
+		case test.Status == runner.TestStatusPassed:
+			testCase.FlakyFailures = previousFailures
+		case test.Status == runner.TestStatusSkipped:
+			testCase.Skipped = &junitReportMessage{}
+			suite.Skipped++
+		default:
+			testCase.Error = &junitReportMessage{Message: "Test result is unknown"}
+			suite.Errors++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	report := junitReportTestSuites{
+		Name:     "bktec",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Suites:   []junitReportSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,143 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+	"github.com/google/go-cmp/cmp"
+)
+
+func junitReportRunResult() runner.RunResult {
+	flaky := plan.TestCase{Scope: "Apple", Name: "is red", Path: "apple_spec.rb:1"}
+	broken := plan.TestCase{Scope: "Banana", Name: "is yellow", Path: "banana_spec.rb:2"}
+	muted := plan.TestCase{Scope: "Cherry", Name: "is <sweet>", Path: "cherry_spec.rb:3"}
+	skipped := plan.TestCase{Scope: "Durian", Name: "smells", Path: "durian_spec.rb:4"}
+	passed := plan.TestCase{Scope: "Elderberry", Name: "is tiny", Path: "elderberry_spec.rb:5"}
+
+	runResult := runner.NewRunResult([]plan.TestCase{muted})
+	runResult.RecordTestResult(flaky, runner.TestStatusFailed)
+	runResult.RecordTestResult(broken, runner.TestStatusFailed)
+	runResult.RecordTestResult(muted, runner.TestStatusFailed)
+	runResult.RecordTestResult(skipped, runner.TestStatusSkipped)
+	runResult.RecordTestResult(passed, runner.TestStatusPassed)
+
+	// first retry
+	runResult.RecordTestResult(flaky, runner.TestStatusPassed)
+	runResult.RecordTestResult(broken, runner.TestStatusFailed)
+	runResult.RecordTestResult(muted, runner.TestStatusFailed)
+
+	// second retry
+	runResult.RecordTestResult(broken, runner.TestStatusFailed)
+
+	return *runResult
+}
+
+func TestEncodeJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeJUnitReport(&buf, junitReportRunResult(), "RSpec"); err != nil {
+		t.Fatalf("encodeJUnitReport(...) error = %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="bktec" tests="5" failures="1" errors="0" skipped="2">
+  <testsuite name="RSpec" tests="5" failures="1" errors="0" skipped="2">
+    <testcase classname="Apple" name="is red" file="apple_spec.rb:1">
+      <properties>
+        <property name="status" value="passed"></property>
+        <property name="execution_count" value="2"></property>
+        <property name="muted" value="false"></property>
+        <property name="passed_on_retry" value="true"></property>
+      </properties>
+      <flakyFailure message="Failed on attempt 1"></flakyFailure>
+    </testcase>
+    <testcase classname="Banana" name="is yellow" file="banana_spec.rb:2">
+      <properties>
+        <property name="status" value="failed"></property>
+        <property name="execution_count" value="3"></property>
+        <property name="muted" value="false"></property>
+        <property name="passed_on_retry" value="false"></property>
+      </properties>
+      <failure message="Failed on attempt 3"></failure>
+      <rerunFailure message="Failed on attempt 1"></rerunFailure>
+      <rerunFailure message="Failed on attempt 2"></rerunFailure>
+    </testcase>
+    <testcase classname="Cherry" name="is &lt;sweet&gt;" file="cherry_spec.rb:3">
+      <properties>
+        <property name="status" value="failed"></property>
+        <property name="execution_count" value="2"></property>
+        <property name="muted" value="true"></property>
+        <property name="passed_on_retry" value="false"></property>
+      </properties>
+      <skipped message="Muted test failed"></skipped>
+    </testcase>
+    <testcase classname="Durian" name="smells" file="durian_spec.rb:4">
+      <properties>
+        <property name="status" value="skipped"></property>
+        <property name="execution_count" value="1"></property>
+        <property name="muted" value="false"></property>
+        <property name="passed_on_retry" value="false"></property>
+      </properties>
+      <skipped></skipped>
+    </testcase>
+    <testcase classname="Elderberry" name="is tiny" file="elderberry_spec.rb:5">
+      <properties>
+        <property name="status" value="passed"></property>
+        <property name="execution_count" value="1"></property>
+        <property name="muted" value="false"></property>
+        <property name="passed_on_retry" value="false"></property>
+      </properties>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("encodeJUnitReport(...) diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestEncodeJUnitReport_FailureDetails(t *testing.T) {
+	flaky := plan.TestCase{Scope: "Apple", Name: "is red", Path: "apple_spec.rb:1"}
+
+	runResult := runner.NewRunResult([]plan.TestCase{})
+	runResult.RecordTestAttempt(flaky, runner.TestAttempt{
+		Status: runner.TestStatusFailed,
+		Failure: &runner.TestFailure{
+			Message:   "expected red, got green",
+			Exception: "RSpec::Expectations::ExpectationNotMetError",
+			Backtrace: []string{"./apple_spec.rb:2:in `block'", "./spec_helper.rb:10:in `run'"},
+		},
+	})
+	runResult.RecordTestAttempt(flaky, runner.TestAttempt{Status: runner.TestStatusPassed})
+
+	var buf bytes.Buffer
+	if err := encodeJUnitReport(&buf, *runResult, "RSpec"); err != nil {
+		t.Fatalf("encodeJUnitReport(...) error = %v", err)
+	}
+
+	want := `<flakyFailure message="expected red, got green" type="RSpec::Expectations::ExpectationNotMetError">./apple_spec.rb:2:in ` + "`block&#39;&#xA;./spec_helper.rb:10:in `run&#39;" + `</flakyFailure>`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("encodeJUnitReport(...) = %s, want it to contain %s", buf.String(), want)
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "junit.xml")
+
+	if err := writeJUnitReport(path, junitReportRunResult(), "RSpec"); err != nil {
+		t.Fatalf("writeJUnitReport(%q, ...) error = %v", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) error = %v", path, err)
+	}
+
+	if !strings.HasPrefix(string(data), `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Errorf("writeJUnitReport(%q, ...) wrote %q, want a JUnit XML report", path, data)
+	}
+}
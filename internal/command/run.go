@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -62,6 +63,10 @@ func Run(ctx context.Context, cfg *config.Config, testListFilename string) error
 
 	printSplitSummary(os.Stdout, testPlan)
 
+	if cfg.DryRun {
+		return printDryRun(os.Stdout, cfg, testPlan, testRunner)
+	}
+
 	// get plan for this node
 	thisNodeTask := testPlan.Tasks[strconv.Itoa(cfg.NodeIndex)]
 
@@ -74,6 +79,10 @@ func Run(ctx context.Context, cfg *config.Config, testListFilename string) error
 		}
 	}
 
+	// The tests of the task are replaced by the failed tests on retry,
+	// so the estimate has to be taken before running them.
+	estimatedDuration := thisNodeTask.EstimatedDuration()
+
 	// execute tests
 	var timeline []api.Timeline
 	runResult, runErr := runTestsWithRetry(ctx, apiClient, cfg, testRunner, &thisNodeTask.Tests, cfg.MaxRetries, testPlan.MutedTests, &timeline, cfg.RetryForMutedTest, cfg.FailOnNoTests)
@@ -89,7 +98,21 @@ func Run(ctx context.Context, cfg *config.Config, testListFilename string) error
 	// the build can cascade to failing before this job actually exits.
 	promiseFailureIfNeeded(ctx, cfg, runResult)
 
-	printReport(runResult, testPlan.SkippedTests, testRunner.Name())
+	if cfg.ReportJUnitPath != "" {
+		if err := writeJUnitReport(cfg.ReportJUnitPath, runResult, testRunner.Name()); err != nil {
+			printWarning(os.Stderr, fmt.Sprintf("Failed to write the JUnit report: %v", err))
+		}
+	}
+
+	if cfg.SummaryJSONPath != "" {
+		summary := newRunSummary(cfg, testPlan, runResult, testRunner.Name(), timeline)
+		if err := writeSummaryJSON(cfg.SummaryJSONPath, summary); err != nil {
+			printWarning(os.Stderr, fmt.Sprintf("Failed to write the JSON summary: %v", err))
+		}
+	}
+
+	printReport(runResult, testPlan.SkippedTests, testRunner.Name(), estimatedDuration, timeline)
+	annotateIfNeeded(ctx, cfg, runResult, testRunner.Name())
 	if !testPlan.Fallback {
 		sendMetadata(ctx, apiClient, cfg, timeline, runResult.Statistics())
 	}
@@ -99,7 +122,7 @@ func Run(ctx context.Context, cfg *config.Config, testListFilename string) error
 		// since runners like rspec or jest can exit with code 1 for non-test-failure reasons too.
 		// However, checking for exit code 1 alongside a passing report is a best-effort approximation
 		// to reduce the risk of incorrectly suppressing a real error.
-		if exitError.ExitCode() == 1 && runResult.OnlyMutedFailures() {
+		if isTestFailureExitCode(testRunner, exitError.ExitCode()) && runResult.OnlyMutedFailures() {
 			return nil
 		}
 		return fmt.Errorf("%s exited with error: %w", testRunner.Name(), runErr)
@@ -108,6 +131,15 @@ func Run(ctx context.Context, cfg *config.Config, testListFilename string) error
 	return runErr
 }
 
+// isTestFailureExitCode reports whether the runner's exit code can be caused by test failures.
+// Most runners exit with 1, but some (e.g. Cypress) use a different convention.
+func isTestFailureExitCode(testRunner runner.TestRunner, code int) bool {
+	if r, ok := testRunner.(runner.TestFailureExitCoder); ok {
+		return r.IsTestFailureExitCode(code)
+	}
+	return code == 1
+}
+
 func trimTaskLocationPrefix(task *plan.Task, locationPrefix string) error {
 	for i, test := range task.Tests {
 		if test.Format == plan.TestCaseFormatSelector {
@@ -204,7 +236,7 @@ func printStartUpMessage() {
 	fmt.Println(green + Logo + reset)
 }
 
-func printReport(runResult runner.RunResult, testsSkippedByTestEngine []plan.TestCase, runnerName string) {
+func printReport(runResult runner.RunResult, testsSkippedByTestEngine []plan.TestCase, runnerName string, estimatedDuration time.Duration, timeline []api.Timeline) {
 	status := runResult.Status()
 	if status == runner.RunStatusUnknown {
 		return
@@ -259,8 +291,17 @@ func printReport(runResult runner.RunResult, testsSkippedByTestEngine []plan.Tes
 		if len(failedTests) > 0 {
 			fmt.Println("")
 			fmt.Println("+++ Failed Tests:")
-			for _, failedTests := range runResult.FailedTests() {
-				fmt.Printf("- %s %s\n", failedTests.Scope, failedTests.Name)
+			for _, test := range runResult.Tests() {
+				if test.Status != runner.TestStatusFailed || test.Muted {
+					continue
+				}
+				fmt.Printf("- %s %s\n", test.Scope, test.Name)
+				// Only the message is printed, the test runner has already printed the backtrace.
+				if failure := test.Failure(); failure != nil && failure.Message != "" {
+					for _, line := range strings.Split(failure.Message, "\n") {
+						fmt.Printf("    %s\n", line)
+					}
+				}
 			}
 		}
 
@@ -280,6 +321,9 @@ func printReport(runResult runner.RunResult, testsSkippedByTestEngine []plan.Tes
 				fmt.Printf("- %s %s\n", skippedTest.Scope, skippedTest.Name)
 			}
 		}
+
+		printSlowestTests(os.Stdout, runResult)
+		printNodeDuration(os.Stdout, estimatedDuration, timeline)
 	}
 	fmt.Println("===================================================")
 }
@@ -296,12 +340,16 @@ func uploadResults(ctx context.Context, apiClient *api.Client, cfg *config.Confi
 	if format == "" {
 		return
 	}
-	if _, err := os.Stat(testRunner.ResultFilePath()); err != nil {
+	// The result path can be a glob matching several report files, which are uploaded one by one.
+	files, err := runner.ResultFiles(testRunner.ResultFilePath())
+	if err != nil || len(files) == 0 {
 		return
 	}
 	fmt.Println("Buildkite Test Engine Client: Uploading test results to Test Engine")
-	if err := apiClient.UploadTestResults(ctx, cfg.UploadToken, testRunner.ResultFilePath(), format, cfg.TestRunner, testRunner.LocationPrefix(), cfg.UploadTags); err != nil {
-		fmt.Printf("Buildkite Test Engine Client: Failed to upload test results to Test Engine: %v\n", err)
+	for _, file := range files {
+		if err := apiClient.UploadTestResults(ctx, cfg.UploadToken, file, format, cfg.TestRunner, testRunner.LocationPrefix(), cfg.UploadTags); err != nil {
+			fmt.Printf("Buildkite Test Engine Client: Failed to upload test results from %s to Test Engine: %v\n", file, err)
+		}
 	}
 }
 
@@ -329,9 +377,35 @@ func sendMetadata(ctx context.Context, apiClient *api.Client, cfg *config.Config
 func runTestsWithRetry(ctx context.Context, apiClient *api.Client, cfg *config.Config, testRunner runner.TestRunner, testsCases *[]plan.TestCase, maxRetries int, mutedTests []plan.TestCase, timeline *[]api.Timeline, retryForMutedTest bool, failOnNoTests bool) (runner.RunResult, error) {
 	attemptCount := 0
 
+	// Runner definitions and plugins can turn off muting and retries, in which case
+	// failed tests fail the run, and the tests run once.
+	features := testRunner.SupportedFeatures()
+	if !features.Mute {
+		if len(mutedTests) > 0 || cfg.QuarantineFile != "" {
+			fmt.Printf("Buildkite Test Engine Client: The %s runner doesn't support muting tests, muted and quarantined tests will fail the run\n", testRunner.Name())
+		}
+		mutedTests = nil
+	}
+	if !features.AutoRetry {
+		if maxRetries > 0 {
+			fmt.Printf("Buildkite Test Engine Client: The %s runner doesn't support retrying tests, failed tests will not be retried\n", testRunner.Name())
+		}
+		maxRetries = 0
+	}
+
 	// Create a new run result with muted tests to keep track of the results.
 	runResult := runner.NewRunResult(mutedTests)
 
+	// The quarantine file doesn't depend on Test Engine, so it is honoured by fallback plans as well.
+	if cfg.QuarantineFile != "" && features.Mute {
+		quarantine, err := runner.LoadQuarantineFile(cfg.QuarantineFile)
+		if err != nil {
+			return *runResult, err
+		}
+		fmt.Printf("Buildkite Test Engine Client: Muting %d quarantined %s from %s\n", len(quarantine.Tests), pluralizeTests(len(quarantine.Tests)), cfg.QuarantineFile)
+		runResult.MuteQuarantinedTests(quarantine)
+	}
+
 	// If there are no test cases to run, skip invoking the test runner
 	if len(*testsCases) == 0 {
 		if failOnNoTests {
@@ -360,9 +434,11 @@ func runTestsWithRetry(ctx context.Context, apiClient *api.Client, cfg *config.C
 			})
 		}
 
-		err := testRunner.Run(runResult, *testsCases, attemptCount > 0)
-
-		uploadResults(ctx, apiClient, cfg, testRunner)
+		// The results are uploaded after each command, as a command over the argument limit is split in
+		// several commands, which replace the result files of the previous one.
+		err := runner.RunChunked(testRunner, runResult, *testsCases, attemptCount > 0, func() {
+			uploadResults(ctx, apiClient, cfg, testRunner)
+		})
 
 		if attemptCount == 0 {
 			*timeline = append(*timeline, api.Timeline{
@@ -431,7 +507,7 @@ func fetchOrCreateTestPlan(ctx context.Context, apiClient *api.Client, cfg *conf
 		if handledErr := handleError(err); handledErr != nil {
 			return plan.TestPlan{}, handledErr
 		}
-		return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+		return createFallbackPlan(cfg, testTargets)
 	}
 
 	if cachedPlan != nil {
@@ -439,10 +515,11 @@ func fetchOrCreateTestPlan(ctx context.Context, apiClient *api.Client, cfg *conf
 		// In this case, we should create a fallback plan.
 		if len(cachedPlan.Tasks) == 0 {
 			warnErrorPlan()
-			return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+			return createFallbackPlan(cfg, testTargets)
 		}
 
 		debug.Printf("Test plan found. Identifier: %q", cfg.Identifier)
+		savePlanCache(cfg, *cachedPlan, testRunner.LocationPrefix())
 		return *cachedPlan, nil
 	}
 
@@ -459,7 +536,7 @@ func fetchOrCreateTestPlan(ctx context.Context, apiClient *api.Client, cfg *conf
 		if handledErr := handleError(err); handledErr != nil {
 			return plan.TestPlan{}, handledErr
 		}
-		return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+		return createFallbackPlan(cfg, testTargets)
 	}
 
 	debug.Println("Creating test plan")
@@ -469,16 +546,17 @@ func fetchOrCreateTestPlan(ctx context.Context, apiClient *api.Client, cfg *conf
 		if handledErr := handleError(err); handledErr != nil {
 			return plan.TestPlan{}, handledErr
 		}
-		return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+		return createFallbackPlan(cfg, testTargets)
 	}
 
 	// The server can return an "error" plan indicated by an empty task list (i.e. `{"tasks": {}}`).
 	// In this case, we should create a fallback plan.
 	if len(testPlan.Tasks) == 0 {
 		warnErrorPlan()
-		return plan.CreateFallbackPlan(testTargets, cfg.Parallelism), nil
+		return createFallbackPlan(cfg, testTargets)
 	}
 
 	debug.Printf("Test plan created. Identifier: %q", cfg.Identifier)
+	savePlanCache(cfg, testPlan, testRunner.LocationPrefix())
 	return testPlan, nil
 }
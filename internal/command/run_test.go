@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -746,6 +747,212 @@ func TestFetchOrCreateTestPlan_InternalServerError(t *testing.T) {
 	assert.Contains(t, stderr, "Test Engine API timed out")
 }
 
+func TestFetchOrCreateTestPlan_SavesPlanCache(t *testing.T) {
+	response := `{
+	"tasks": {
+		"0": {
+			"node_number": 0,
+			"tests": [
+				{"path": "app/apple_spec.rb", "format": "file", "estimated_duration": 3000},
+				{"path": "app/banana_spec.rb[1:1]", "format": "example", "estimated_duration": 500}
+			]
+		},
+		"1": {
+			"node_number": 1,
+			"tests": [
+				{"path": "app/banana_spec.rb[1:2]", "format": "example", "estimated_duration": 700},
+				{"path": "app/cherry_spec.rb", "format": "file"}
+			]
+		}
+	}
+}`
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, response)
+	}))
+	defer svr.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "cache", "plan-cache.json")
+	cfg := config.Config{
+		NodeIndex:      0,
+		Parallelism:    2,
+		Identifier:     "identifier",
+		ServerBaseURL:  svr.URL,
+		SuiteSlug:      "suite",
+		TestRunner:     "rspec",
+		PlanCacheFile:  cacheFile,
+		LocationPrefix: "app",
+	}
+	apiClient := api.NewClient(api.ClientConfig{
+		ServerBaseURL: cfg.ServerBaseURL,
+	})
+	files := []string{"apple_spec.rb", "banana_spec.rb", "cherry_spec.rb"}
+	testRunner, err := runner.DetectRunner(&config.Config{TestRunner: "rspec", LocationPrefix: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fetchOrCreateTestPlan(context.Background(), apiClient, &cfg, files, testRunner); err != nil {
+		t.Fatalf("fetchOrCreateTestPlan(ctx, %v, %v) error = %v", cfg, files, err)
+	}
+
+	cache, err := plan.LoadTimingCache(cacheFile)
+	if err != nil {
+		t.Fatalf("plan.LoadTimingCache(%q) error = %v", cacheFile, err)
+	}
+
+	want := map[string]int{
+		"apple_spec.rb":       3000,
+		"banana_spec.rb[1:1]": 500,
+		"banana_spec.rb[1:2]": 700,
+	}
+	if diff := cmp.Diff(cache.Durations("suite", "rspec"), want); diff != "" {
+		t.Errorf("cache.Durations(%q, %q) diff (-got +want):\n%s", "suite", "rspec", diff)
+	}
+}
+
+func TestSavePlanCache_DryRun(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "plan-cache.json")
+	cfg := config.Config{
+		SuiteSlug:     "suite",
+		TestRunner:    "rspec",
+		PlanCacheFile: cacheFile,
+		DryRun:        true,
+	}
+	testPlan := plan.TestPlan{
+		Tasks: map[string]*plan.Task{
+			"0": {NodeNumber: 0, Tests: []plan.TestCase{{Path: "apple_spec.rb", Format: plan.TestCaseFormatFile, EstimatedDuration: 3000}}},
+		},
+	}
+
+	savePlanCache(&cfg, testPlan, "")
+
+	if _, err := os.Stat(cacheFile); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(%q) error = %v, want the plan cache not to be written on a dry run", cacheFile, err)
+	}
+}
+
+func TestFetchOrCreateTestPlan_PlanErrorUsesPlanCache(t *testing.T) {
+	files := []string{"apple", "banana", "cherry", "mango"}
+	testRunner := runner.Rspec{}
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tasks": {}}`)
+	}))
+	defer svr.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "plan-cache.json")
+	cache := plan.TimingCache{}
+	cache.Set("suite", "rspec", map[string]int{"apple": 9000, "banana": 1000, "cherry": 4000, "mango": 3000}, time.Now())
+	if err := cache.Save(cacheFile); err != nil {
+		t.Fatalf("cache.Save(%q) error = %v", cacheFile, err)
+	}
+
+	getStderr := captureStderr(t)
+
+	cfg := config.Config{
+		NodeIndex:     0,
+		Parallelism:   2,
+		Identifier:    "identifier",
+		ServerBaseURL: svr.URL,
+		SuiteSlug:     "suite",
+		TestRunner:    "rspec",
+		PlanCacheFile: cacheFile,
+	}
+	apiClient := api.NewClient(api.ClientConfig{
+		ServerBaseURL: cfg.ServerBaseURL,
+	})
+
+	want := plan.CreateTimedFallbackPlan(files, cfg.Parallelism, cache.Durations("suite", "rspec"))
+
+	got, err := fetchOrCreateTestPlan(context.Background(), apiClient, &cfg, files, testRunner)
+	if err != nil {
+		t.Errorf("fetchOrCreateTestPlan(ctx, %v, %v) error = %v", cfg, files, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("fetchOrCreateTestPlan(ctx, %v, %v) diff (-got +want):\n%s", cfg, files, diff)
+	}
+
+	assert.Contains(t, getStderr(), "Splitting tests using 4 cached timings")
+}
+
+func TestFetchOrCreateTestPlan_InternalServerErrorUsesTimingsFile(t *testing.T) {
+	files := []string{"apple", "banana", "cherry", "mango"}
+	testRunner := runner.Rspec{}
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}))
+	defer svr.Close()
+
+	timingsFile := filepath.Join(t.TempDir(), "timings.csv")
+	if err := os.WriteFile(timingsFile, []byte("path,duration\napple,9000\nbanana,1000\ncherry,4000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	getStderr := captureStderr(t)
+
+	fetchCtx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
+	defer cancel()
+
+	cfg := config.Config{
+		NodeIndex:     0,
+		Parallelism:   2,
+		Identifier:    "identifier",
+		ServerBaseURL: svr.URL,
+		TimingsFile:   timingsFile,
+	}
+	apiClient := api.NewClient(api.ClientConfig{
+		ServerBaseURL: cfg.ServerBaseURL,
+	})
+
+	want := plan.CreateTimedFallbackPlan(files, cfg.Parallelism, map[string]int{"apple": 9000, "banana": 1000, "cherry": 4000})
+
+	got, err := fetchOrCreateTestPlan(fetchCtx, apiClient, &cfg, files, testRunner)
+	if err != nil {
+		t.Errorf("fetchOrCreateTestPlan(ctx, %v, %v) error = %v", cfg, files, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("fetchOrCreateTestPlan(ctx, %v, %v) diff (-got +want):\n%s", cfg, files, diff)
+	}
+
+	assert.Contains(t, getStderr(), "Splitting tests using 3 timings")
+}
+
+func TestFetchOrCreateTestPlan_InvalidTimingsFile(t *testing.T) {
+	files := []string{"apple"}
+	testRunner := runner.Rspec{}
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tasks": {}}`)
+	}))
+	defer svr.Close()
+
+	getStderr := captureStderr(t)
+
+	cfg := config.Config{
+		NodeIndex:     0,
+		Parallelism:   2,
+		Identifier:    "identifier",
+		ServerBaseURL: svr.URL,
+		TimingsFile:   filepath.Join(t.TempDir(), "missing.json"),
+	}
+	apiClient := api.NewClient(api.ClientConfig{
+		ServerBaseURL: cfg.ServerBaseURL,
+	})
+
+	want := plan.CreateFallbackPlan(files, cfg.Parallelism)
+
+	got, err := fetchOrCreateTestPlan(context.Background(), apiClient, &cfg, files, testRunner)
+	if err != nil {
+		t.Errorf("fetchOrCreateTestPlan(ctx, %v, %v) error = %v", cfg, files, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("fetchOrCreateTestPlan(ctx, %v, %v) diff (-got +want):\n%s", cfg, files, diff)
+	}
+
+	assert.Contains(t, getStderr(), "Failed to read the timings file, splitting tests without timings")
+}
+
 func TestFetchOrCreateTestPlan_SelectorOptInFallbackUsesPathTasks(t *testing.T) {
 	packages := []string{
 		"github.com/buildkite/test-engine-client/internal/api",
@@ -1166,6 +1373,47 @@ func TestRunTestsWithRetry_UploadsResults(t *testing.T) {
 	assert.Equal(t, "./", gotLocationPrefix)
 }
 
+func TestRunTestsWithRetry_UploadsEachResultFile(t *testing.T) {
+	var uploadedFiles []string
+
+	uploadSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("parsing multipart form: %v", err)
+		}
+		_, header, err := r.FormFile("data")
+		if err != nil {
+			t.Fatalf("reading uploaded file: %v", err)
+		}
+		uploadedFiles = append(uploadedFiles, header.Filename)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer uploadSvr.Close()
+
+	resultDir := t.TempDir()
+	testRunner, err := runner.NewCustom(runner.RunnerConfig{
+		TestCommand:     fmt.Sprintf(`sh -c 'cp testdata/results/*.json "$0"' %s`, resultDir),
+		TestFilePattern: "tests/*.sh",
+		ResultPath:      filepath.Join(resultDir, "result-*.json"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Custom runner: %v", err)
+	}
+
+	testCases := []plan.TestCase{{Path: "tests/math.sh"}}
+	timeline := []api.Timeline{}
+	cfg := &config.Config{
+		UploadResults: true,
+		UploadToken:   "test-token",
+		UploadBaseURL: uploadSvr.URL,
+	}
+	apiClient := api.NewClient(api.ClientConfig{UploadBaseURL: uploadSvr.URL})
+
+	runResult, err := runTestsWithRetry(context.Background(), apiClient, cfg, testRunner, &testCases, 0, []plan.TestCase{}, &timeline, true, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, runResult.Statistics().Total)
+	assert.Equal(t, []string{"result-1.json", "result-2.json"}, uploadedFiles)
+}
+
 func TestRunTestsWithRetry_SkipsUploadWhenNoToken(t *testing.T) {
 	var uploadRequests int
 	uploadSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1250,3 +1498,43 @@ func TestRunTestsWithRetry_UploadErrorDoesNotFailBuild(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, runner.RunStatusPassed, result.Status(), "build should pass even when upload fails")
 }
+
+func TestRunTestsWithRetry_RunnerWithoutRetryAndMute(t *testing.T) {
+	resultDir := t.TempDir()
+	disabled := false
+	testRunner := runner.NewDefinedRunner(runner.RunnerConfig{}, runner.RunnerDefinition{
+		Name: "bats",
+		// Counts the runs, and writes a result with a failed test.
+		TestCommand:  fmt.Sprintf(`sh -c 'echo run >> "$0/runs"; echo "[{\"scope\": \"math\", \"name\": \"adds\", \"file_name\": \"tests/math.sh\", \"result\": \"failed\"}]" > "$0/result.json"' %s`, resultDir),
+		ResultPath:   filepath.Join(resultDir, "result.json"),
+		ResultFormat: runner.ResultFormatTestEngineJSON,
+		Features: runner.RunnerDefinitionFeatures{
+			AutoRetry: &disabled,
+			Mute:      &disabled,
+		},
+	})
+
+	testCases := []plan.TestCase{{Path: "tests/math.sh"}}
+	mutedTests := []plan.TestCase{{Scope: "math", Name: "adds", Path: "tests/math.sh"}}
+	timeline := []api.Timeline{}
+	cfg := &config.Config{}
+	apiClient := api.NewClient(api.ClientConfig{})
+
+	runResult, err := runTestsWithRetry(context.Background(), apiClient, cfg, testRunner, &testCases, 2, mutedTests, &timeline, true, false)
+	assert.NoError(t, err)
+
+	runs, err := os.ReadFile(filepath.Join(resultDir, "runs"))
+	if err != nil {
+		t.Fatalf("os.ReadFile(runs) error = %v", err)
+	}
+	if got := strings.Count(string(runs), "run"); got != 1 {
+		t.Errorf("test command runs = %d, want 1", got)
+	}
+
+	if runResult.Status() != runner.RunStatusFailed {
+		t.Errorf("runTestsWithRetry() RunResult.Status = %v, want %v", runResult.Status(), runner.RunStatusFailed)
+	}
+	if got := runResult.Statistics().MutedFailed; got != 0 {
+		t.Errorf("runTestsWithRetry() RunResult.Statistics().MutedFailed = %d, want 0", got)
+	}
+}
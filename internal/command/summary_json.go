@@ -0,0 +1,123 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/buildkite/test-engine-client/v3/internal/api"
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+)
+
+// runSummary is the machine-readable summary of a run written by --summary-json.
+type runSummary struct {
+	Status         runner.RunStatus     `json:"status"`
+	Runner         string               `json:"runner"`
+	PlanIdentifier string               `json:"plan_identifier"`
+	Fallback       bool                 `json:"fallback"`
+	NodeIndex      int                  `json:"node_index"`
+	Statistics     runner.RunStatistics `json:"statistics"`
+	FailedTests    []runSummaryTest     `json:"failed_tests"`
+	MutedTests     []runSummaryTest     `json:"muted_tests"`
+	// SkippedTests are the tests skipped by the test runner.
+	SkippedTests []runSummaryTest `json:"skipped_tests"`
+	// TestEngineSkippedTests are the tests Test Engine skipped in the test plan, which were not run.
+	TestEngineSkippedTests []runSummaryTest `json:"test_engine_skipped_tests"`
+	Timeline               []api.Timeline   `json:"timeline"`
+}
+
+type runSummaryTest struct {
+	Scope          string            `json:"scope"`
+	Name           string            `json:"name"`
+	Path           string            `json:"path"`
+	Status         runner.TestStatus `json:"status,omitempty"`
+	ExecutionCount int               `json:"execution_count,omitempty"`
+	// Failure is the failure of the most recent failed attempt, when the test runner reported it.
+	Failure *runSummaryFailure `json:"failure,omitempty"`
+}
+
+type runSummaryFailure struct {
+	Message   string   `json:"message"`
+	Exception string   `json:"exception,omitempty"`
+	Backtrace []string `json:"backtrace,omitempty"`
+}
+
+func newRunSummaryTest(test runner.TestResult) runSummaryTest {
+	summaryTest := runSummaryTest{
+		Scope:          test.Scope,
+		Name:           test.Name,
+		Path:           test.Path,
+		Status:         test.Status,
+		ExecutionCount: test.ExecutionCount,
+	}
+
+	if failure := test.Failure(); failure != nil {
+		summaryTest.Failure = &runSummaryFailure{
+			Message:   failure.Message,
+			Exception: failure.Exception,
+			Backtrace: failure.Backtrace,
+		}
+	}
+	return summaryTest
+}
+
+func newRunSummary(cfg *config.Config, testPlan plan.TestPlan, runResult runner.RunResult, runnerName string, timeline []api.Timeline) runSummary {
+	summary := runSummary{
+		Status:                 runResult.Status(),
+		Runner:                 runnerName,
+		PlanIdentifier:         cfg.Identifier,
+		Fallback:               testPlan.Fallback,
+		NodeIndex:              cfg.NodeIndex,
+		Statistics:             runResult.Statistics(),
+		FailedTests:            []runSummaryTest{},
+		MutedTests:             []runSummaryTest{},
+		SkippedTests:           []runSummaryTest{},
+		TestEngineSkippedTests: []runSummaryTest{},
+		Timeline:               timeline,
+	}
+
+	if summary.Timeline == nil {
+		summary.Timeline = []api.Timeline{}
+	}
+
+	for _, test := range runResult.Tests() {
+		switch {
+		case test.Muted:
+			summary.MutedTests = append(summary.MutedTests, newRunSummaryTest(test))
+		case test.Status == runner.TestStatusFailed:
+			summary.FailedTests = append(summary.FailedTests, newRunSummaryTest(test))
+		case test.Status == runner.TestStatusSkipped:
+			summary.SkippedTests = append(summary.SkippedTests, newRunSummaryTest(test))
+		}
+	}
+
+	for _, tc := range testPlan.SkippedTests {
+		summary.TestEngineSkippedTests = append(summary.TestEngineSkippedTests, runSummaryTest{
+			Scope: tc.Scope,
+			Name:  tc.Name,
+			Path:  tc.Path,
+		})
+	}
+
+	return summary
+}
+
+// writeSummaryJSON writes the summary of the run to path as JSON.
+func writeSummaryJSON(path string, summary runSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding summary: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating summary directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/api"
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/buildkite/test-engine-client/v3/internal/runner"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteSummaryJSON(t *testing.T) {
+	flaky := plan.TestCase{Scope: "Apple", Name: "is red", Path: "apple_spec.rb:1"}
+	broken := plan.TestCase{Scope: "Banana", Name: "is yellow", Path: "banana_spec.rb:2"}
+	muted := plan.TestCase{Scope: "Cherry", Name: "is sweet", Path: "cherry_spec.rb:3"}
+	skipped := plan.TestCase{Scope: "Durian", Name: "smells", Path: "durian_spec.rb:4"}
+
+	runResult := runner.NewRunResult([]plan.TestCase{muted})
+	runResult.RecordTestResult(flaky, runner.TestStatusFailed)
+	runResult.RecordTestResult(broken, runner.TestStatusFailed)
+	runResult.RecordTestResult(muted, runner.TestStatusFailed)
+	runResult.RecordTestResult(skipped, runner.TestStatusSkipped)
+	runResult.RecordTestResult(flaky, runner.TestStatusPassed)
+	runResult.RecordTestResult(broken, runner.TestStatusFailed)
+
+	cfg := config.Config{Identifier: "build/step", NodeIndex: 2}
+	testPlan := plan.TestPlan{
+		Fallback:     true,
+		SkippedTests: []plan.TestCase{{Scope: "Elderberry", Name: "is slow", Path: "elderberry_spec.rb:5"}},
+	}
+	timeline := []api.Timeline{
+		{Timestamp: "2025-01-01T00:00:00Z", Event: "test_start"},
+		{Timestamp: "2025-01-01T00:01:00Z", Event: "test_end"},
+	}
+
+	path := filepath.Join(t.TempDir(), "reports", "summary.json")
+	summary := newRunSummary(&cfg, testPlan, *runResult, "RSpec", timeline)
+	if err := writeSummaryJSON(path, summary); err != nil {
+		t.Fatalf("writeSummaryJSON(%q, ...) error = %v", path, err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) error = %v", path, err)
+	}
+
+	want := `{
+  "status": "failed",
+  "runner": "RSpec",
+  "plan_identifier": "build/step",
+  "fallback": true,
+  "node_index": 2,
+  "statistics": {
+    "total": 4,
+    "passed_on_first_run": 0,
+    "passed_on_retry": 1,
+    "muted_passed": 0,
+    "muted_failed": 1,
+    "failed": 1,
+    "skipped": 1
+  },
+  "failed_tests": [
+    {
+      "scope": "Banana",
+      "name": "is yellow",
+      "path": "banana_spec.rb:2",
+      "status": "failed",
+      "execution_count": 2
+    }
+  ],
+  "muted_tests": [
+    {
+      "scope": "Cherry",
+      "name": "is sweet",
+      "path": "cherry_spec.rb:3",
+      "status": "failed",
+      "execution_count": 1
+    }
+  ],
+  "skipped_tests": [
+    {
+      "scope": "Durian",
+      "name": "smells",
+      "path": "durian_spec.rb:4",
+      "status": "skipped",
+      "execution_count": 1
+    }
+  ],
+  "test_engine_skipped_tests": [
+    {
+      "scope": "Elderberry",
+      "name": "is slow",
+      "path": "elderberry_spec.rb:5"
+    }
+  ],
+  "timeline": [
+    {
+      "timestamp": "2025-01-01T00:00:00Z",
+      "event": "test_start"
+    },
+    {
+      "timestamp": "2025-01-01T00:01:00Z",
+      "event": "test_end"
+    }
+  ]
+}
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("writeSummaryJSON(%q, ...) diff (-got +want):\n%s", path, diff)
+	}
+}
+
+func TestNewRunSummary_Empty(t *testing.T) {
+	summary := newRunSummary(&config.Config{}, plan.TestPlan{}, *runner.NewRunResult(nil), "RSpec", nil)
+
+	if summary.FailedTests == nil || summary.MutedTests == nil || summary.SkippedTests == nil ||
+		summary.TestEngineSkippedTests == nil || summary.Timeline == nil {
+		t.Errorf("newRunSummary(...) = %+v, want empty lists instead of nil", summary)
+	}
+}
+
+func TestNewRunSummary_FailureDetails(t *testing.T) {
+	broken := plan.TestCase{Scope: "Banana", Name: "is yellow", Path: "banana_spec.rb:2"}
+
+	runResult := runner.NewRunResult(nil)
+	runResult.RecordTestAttempt(broken, runner.TestAttempt{
+		Status: runner.TestStatusFailed,
+		Failure: &runner.TestFailure{
+			Message:   "expected yellow, got green",
+			Exception: "RSpec::Expectations::ExpectationNotMetError",
+			Backtrace: []string{"./banana_spec.rb:3:in `block'"},
+		},
+	})
+
+	summary := newRunSummary(&config.Config{}, plan.TestPlan{}, *runResult, "RSpec", nil)
+
+	want := []runSummaryTest{{
+		Scope:          "Banana",
+		Name:           "is yellow",
+		Path:           "banana_spec.rb:2",
+		Status:         runner.TestStatusFailed,
+		ExecutionCount: 1,
+		Failure: &runSummaryFailure{
+			Message:   "expected yellow, got green",
+			Exception: "RSpec::Expectations::ExpectationNotMetError",
+			Backtrace: []string{"./banana_spec.rb:3:in `block'"},
+		},
+	}}
+	if diff := cmp.Diff(summary.FailedTests, want); diff != "" {
+		t.Errorf("newRunSummary(...).FailedTests diff (-got +want):\n%s", diff)
+	}
+}
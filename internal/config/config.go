@@ -12,6 +12,11 @@ import "time"
 type Config struct {
 	// AccessToken is the access token for the API.
 	AccessToken string `json:"-"`
+	// AllNodes makes a dry run print the command of every node in the plan, not only this node.
+	AllNodes bool `json:"-"`
+	// Annotate, when true, makes bktec post a report of failed, flaky and muted tests
+	// as a Buildkite annotation via the `buildkite-agent annotate` CLI.
+	Annotate bool `json:"-"`
 	// PromiseFailure, when true, makes bktec declare an early failure via the
 	// `buildkite-agent job promise-failure` CLI once retries are exhausted and
 	// hard failures remain.
@@ -30,8 +35,13 @@ type Config struct {
 	Days int `json:"-"`
 	// Enable debug output
 	DebugEnabled bool `json:"-"`
+	// DryRun makes `bktec run` print the test runner command and the assigned tests instead of running them.
+	DryRun bool `json:"-"`
 	// FailOnNoTests causes the client to exit with an error if no tests are assigned to the node
 	FailOnNoTests bool `json:"-"`
+	// GoTestBinaryDir is the directory where the Go runner compiles each package into a test binary,
+	// which is reused across retries. When it's empty, the Go runner uses the test command instead.
+	GoTestBinaryDir string `json:"-"`
 	// Identifier is the identifier of the build.
 	Identifier string `json:"-"`
 	JobID      string `json:"-"`
@@ -59,12 +69,18 @@ type Config struct {
 	Output string `json:"-"`
 	// Parallelism is the number of parallel tasks to run.
 	Parallelism int `json:"-"`
+	// PlanCacheFile is the path to a local file where bktec run saves the estimated durations
+	// of the last test plan from the server. When the server can't provide a plan,
+	// the fallback plan uses them to balance the tests across nodes.
+	PlanCacheFile string `json:"-"`
 	// PlanOut is the destination for the `bktec plan --plan-out` output: "-" for
 	// stdout, or a file path. The full test plan is written as the server's
 	// response, unmodified.
 	PlanOut string `json:"-"`
 	// Remote is the git remote name for fetching missing commits and detecting default branch (default "origin").
 	Remote string `json:"-"`
+	// ReportJUnitPath is the path to write a JUnit XML report of the whole run, including retries, to.
+	ReportJUnitPath string `json:"-"`
 	// ResultPath is the path to the result file.
 	ResultPath string `json:"-"`
 	// RetryCommand is the command to run the retry tests.
@@ -72,6 +88,11 @@ type Config struct {
 	// RetryForMutedTest indicates whether a failed muted test should be retried.
 	// This is default to true because we want more signal for our flaky detection system.
 	RetryForMutedTest bool `json:"-"`
+	// RunnerDefinitionsFile is the path to a file defining additional test runners,
+	// which can be selected with TestRunner alongside the built-in runners.
+	RunnerDefinitionsFile string `json:"-"`
+	// RunnerPlugin is the command running the executable of the runner plugin used by the plugin test runner.
+	RunnerPlugin string `json:"-"`
 	// SelectionParams are additional key/value parameters for the strategy.
 	SelectionParams map[string]string `json:"-"`
 	// SelectionStrategy is the selection strategy sent to the test plan API.
@@ -93,6 +114,8 @@ type Config struct {
 	// SplitByExample is the flag to enable split the test by example.
 	SplitByExample bool   `json:"-"`
 	StepID         string `json:"-"`
+	// SummaryJSONPath is the path to write a JSON summary of the run to.
+	SummaryJSONPath string `json:"-"`
 	// SuiteSlug is the slug of the suite.
 	SuiteSlug string `json:"-"`
 	// TagFilters filters test examples by execution tags.
@@ -107,6 +130,11 @@ type Config struct {
 	TestFilePattern string `json:"-"`
 	// TestRunner is the name of the runner.
 	TestRunner string `json:"-"`
+	// QuarantineFile is the path to a YAML or JSON file of tests to mute, in addition to the muted tests from Test Engine.
+	QuarantineFile string `json:"-"`
+	// TimingsFile is the path to a JSON or CSV file of test paths and their durations in milliseconds.
+	// When the server can't provide a plan, the fallback plan uses them to balance the tests across nodes.
+	TimingsFile string `json:"-"`
 
 	// Set to true if AccessToken was unset and an OIDC token was generated instead
 	accessTokenIsOIDC bool
@@ -82,6 +82,10 @@ func (c *Config) validate() error {
 		}
 	}
 
+	if c.TestRunner == "plugin" && c.RunnerPlugin == "" {
+		c.errs.appendFieldError("BUILDKITE_TEST_ENGINE_RUNNER_PLUGIN", "must not be blank when using the plugin test runner")
+	}
+
 	if c.TagFilters != "" && c.TestRunner != "pytest" {
 		c.errs.appendFieldError(
 			"BUILDKITE_TEST_ENGINE_TAG_FILTERS",
@@ -116,11 +120,37 @@ func (c *Config) ValidateForRun() error {
 		"playwright": true,
 		"gotest":     true,
 		"cucumber":   true,
+		"mocha":      true,
+		"phpunit":    true,
+		"dotnet":     true,
+		"exunit":     true,
 	}
 	if c.ResultPath == "" && runnersWithResultPath[c.TestRunner] {
 		c.errs.appendFieldError("BUILDKITE_TEST_ENGINE_RESULT_PATH", "must not be blank")
 	}
 
+	// These runners give the result path to the test runner as the path of its report, or read a
+	// single report, so the result path can't be a glob. The other runners read every report
+	// matching a glob.
+	runnersWithSingleResultFile := map[string]bool{
+		"rspec":    true,
+		"vitest":   true,
+		"pytest":   true,
+		"gotest":   true,
+		"cucumber": true,
+		"mocha":    true,
+		"phpunit":  true,
+		"nextest":  true,
+		"exunit":   true,
+	}
+	if strings.ContainsAny(c.ResultPath, "*?[") && runnersWithSingleResultFile[c.TestRunner] {
+		c.errs.appendFieldError("BUILDKITE_TEST_ENGINE_RESULT_PATH", "can't be a glob for the %s runner, which writes a single result file", c.TestRunner)
+	}
+
+	if c.AllNodes && !c.DryRun {
+		c.errs.appendFieldError("all-nodes", "can only be used with --dry-run")
+	}
+
 	// Upload token could come from the env BUILDKITE_ANALYTICS_TOKEN, but may be blank ...
 	if c.UploadToken == "" {
 		if c.accessTokenIsOIDC {
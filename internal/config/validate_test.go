@@ -2,9 +2,12 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func createConfig() Config {
@@ -17,7 +20,7 @@ func createConfig() Config {
 		SuiteSlug:        "my_suite",
 		AccessToken:      "my_token",
 		MaxRetries:       3,
-		ResultPath:       "tmp/result-*.json",
+		ResultPath:       "tmp/result.json",
 		errs:             InvalidConfigError{},
 		TestRunner:       "rspec",
 	}
@@ -60,6 +63,20 @@ func TestConfigValidate_CustomRunnerFilePatternWithSelectorList(t *testing.T) {
 	})
 }
 
+func TestConfigValidate_PluginRunnerWithoutPlugin(t *testing.T) {
+	c := createConfig()
+	c.TestRunner = "plugin"
+
+	err := c.validate()
+	var invConfigError InvalidConfigError
+	if !errors.As(err, &invConfigError) {
+		t.Fatalf("config.validate() error = %v, want InvalidConfigError", err)
+	}
+	if _, ok := invConfigError["BUILDKITE_TEST_ENGINE_RUNNER_PLUGIN"]; !ok {
+		t.Errorf("config.validate() = %v, want a RUNNER_PLUGIN error", invConfigError)
+	}
+}
+
 func TestConfigValidate_Empty(t *testing.T) {
 	c := Config{errs: InvalidConfigError{}}
 	err := c.validate()
@@ -229,7 +246,7 @@ func TestConfigValidateForRun_ResultPathOptionalWithPytest(t *testing.T) {
 }
 
 func TestConfigValidateForRun_ResultPathRequiredWithResultParsingRunners(t *testing.T) {
-	for _, testRunner := range []string{"rspec", "jest", "vitest", "playwright", "gotest", "cucumber"} {
+	for _, testRunner := range []string{"rspec", "jest", "vitest", "playwright", "gotest", "cucumber", "mocha", "phpunit", "dotnet", "exunit"} {
 		t.Run(testRunner, func(t *testing.T) {
 			c := createConfig()
 			c.ResultPath = ""
@@ -249,6 +266,70 @@ func TestConfigValidateForRun_ResultPathRequiredWithResultParsingRunners(t *test
 	}
 }
 
+func TestConfigValidateForRun_ResultPathGlob(t *testing.T) {
+	for _, testRunner := range []string{"custom", "cypress", "minitest", "gradle", "maven", "dotnet", "jest", "playwright"} {
+		t.Run(testRunner, func(t *testing.T) {
+			c := createConfig()
+			c.ResultPath = "tmp/results/*.json"
+			c.TestRunner = testRunner
+			c.TestCommand = "bin/test {{testExamples}}"
+			c.TestFilePattern = "tests/**/*_test.sh"
+
+			if err := c.ValidateForRun(); err != nil {
+				t.Errorf("ValidateForRun() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestConfigValidateForRun_ResultPathGlobWithSingleResultFileRunners(t *testing.T) {
+	for _, testRunner := range []string{"rspec", "vitest", "pytest", "gotest", "cucumber", "mocha", "phpunit", "nextest", "exunit"} {
+		t.Run(testRunner, func(t *testing.T) {
+			c := createConfig()
+			c.ResultPath = "tmp/results/*.json"
+			c.TestRunner = testRunner
+
+			err := c.ValidateForRun()
+
+			var invConfigError InvalidConfigError
+			if !errors.As(err, &invConfigError) {
+				t.Fatalf("ValidateForRun() error = %v, want InvalidConfigError", err)
+			}
+
+			want := fmt.Sprintf("can't be a glob for the %s runner, which writes a single result file", testRunner)
+			if diff := cmp.Diff(invConfigError["BUILDKITE_TEST_ENGINE_RESULT_PATH"], []error{errors.New(want)}, cmp.Comparer(func(a, b error) bool { return a.Error() == b.Error() })); diff != "" {
+				t.Errorf("ValidateForRun() BUILDKITE_TEST_ENGINE_RESULT_PATH errors diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigValidateForRun_AllNodesRequiresDryRun(t *testing.T) {
+	c := createConfig()
+	c.AllNodes = true
+
+	err := c.ValidateForRun()
+
+	var invConfigError InvalidConfigError
+	if !errors.As(err, &invConfigError) {
+		t.Fatalf("ValidateForRun() error = %v, want InvalidConfigError", err)
+	}
+
+	if _, ok := invConfigError["all-nodes"]; !ok {
+		t.Errorf("ValidateForRun() errors = %v, want all-nodes error", invConfigError)
+	}
+}
+
+func TestConfigValidateForRun_AllNodesWithDryRun(t *testing.T) {
+	c := createConfig()
+	c.DryRun = true
+	c.AllNodes = true
+
+	if err := c.ValidateForRun(); err != nil {
+		t.Errorf("ValidateForRun() error = %v, want nil", err)
+	}
+}
+
 func TestConfigValidateForPlan_ResultPathNotRequired(t *testing.T) {
 	c := createConfig()
 	c.ResultPath = ""
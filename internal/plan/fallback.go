@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"slices"
 	"strconv"
+	"strings"
 )
 
 // CreateFallbackPlan creates a fallback test plan for the given tests and parallelism.
@@ -36,3 +37,125 @@ func CreateFallbackPlan(testTargets []string, parallelism int) TestPlan {
 		Fallback: true,
 	}
 }
+
+// CreateTimedFallbackPlan creates a fallback test plan that balances the given tests
+// across the tasks using their known durations in milliseconds, e.g. from a TimingCache.
+// Tests are assigned longest first to the task with the least total duration.
+// Tests without a known duration are assumed to take the median of the known durations.
+// When none of the tests have a known duration, it behaves like CreateFallbackPlan.
+func CreateTimedFallbackPlan(testTargets []string, parallelism int, durations map[string]int) TestPlan {
+	targetDurations := durationsByTarget(testTargets, durations)
+	if len(targetDurations) == 0 {
+		return CreateFallbackPlan(testTargets, parallelism)
+	}
+
+	known := make([]int, 0, len(targetDurations))
+	for _, d := range targetDurations {
+		known = append(known, d)
+	}
+	defaultDuration := median(known)
+
+	testCases := make([]TestCase, len(testTargets))
+	for i, target := range testTargets {
+		testCases[i] = TestCase{Path: target, EstimatedDuration: defaultDuration}
+		if d, ok := targetDurations[target]; ok {
+			// A known duration counts as a single sample, so the split summary
+			// can tell the tests with a known duration from the others.
+			testCases[i].EstimatedDuration = d
+			testCases[i].TimingSampleSize = 1
+		}
+	}
+
+	// Sort by duration, longest first, and by path to keep the plan deterministic.
+	slices.SortStableFunc(testCases, func(a, b TestCase) int {
+		if c := cmp.Compare(b.EstimatedDuration, a.EstimatedDuration); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	tasks := make(map[string]*Task)
+	loads := make([]int, parallelism)
+	for i := 0; i < parallelism; i++ {
+		tasks[strconv.Itoa(i)] = &Task{
+			NodeNumber: i,
+			Tests:      []TestCase{},
+		}
+	}
+
+	for _, tc := range testCases {
+		nodeNumber := 0
+		for i, load := range loads {
+			if load < loads[nodeNumber] {
+				nodeNumber = i
+			}
+		}
+		loads[nodeNumber] += tc.EstimatedDuration
+		task := tasks[strconv.Itoa(nodeNumber)]
+		task.Tests = append(task.Tests, tc)
+	}
+
+	medianDuration := float64(defaultDuration)
+	return TestPlan{
+		Parallelism: parallelism,
+		Tasks:       tasks,
+		Fallback:    true,
+		TimingMetadata: &TimingMetadata{
+			File: &FormatTimingMetadata{
+				MedianDuration:  &medianDuration,
+				DefaultDuration: medianDuration,
+			},
+		},
+	}
+}
+
+// durationsByTarget returns the durations of the test targets that have one.
+// When a plan is split by example, the durations are keyed by examples
+// (e.g. "a_spec.rb[1:2]" or "test_a.py::test_b") rather than files,
+// so the durations of the examples are added up for their file.
+func durationsByTarget(testTargets []string, durations map[string]int) map[string]int {
+	targets := make(map[string]bool, len(testTargets))
+	for _, target := range testTargets {
+		targets[target] = true
+	}
+
+	exact := make(map[string]int)
+	partial := make(map[string]int)
+	for key, d := range durations {
+		if targets[key] {
+			exact[key] = d
+			continue
+		}
+		if file := exampleFile(key); file != key && targets[file] {
+			partial[file] += d
+		}
+	}
+
+	for file, d := range partial {
+		if _, ok := exact[file]; !ok {
+			exact[file] = d
+		}
+	}
+	return exact
+}
+
+// exampleFile returns the file part of an example path, or the path itself if it isn't an example.
+func exampleFile(path string) string {
+	if i := strings.Index(path, "["); i > 0 {
+		return path[:i]
+	}
+	if i := strings.Index(path, "::"); i > 0 {
+		return path[:i]
+	}
+	return path
+}
+
+func median(values []int) int {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
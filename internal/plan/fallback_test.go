@@ -71,3 +71,79 @@ func TestCreateFallbackPlan(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateTimedFallbackPlan(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		files       []string
+		parallelism int
+		durations   map[string]int
+		want        [][]TestCase
+	}{
+		{
+			name:        "balances by duration",
+			files:       []string{"a", "b", "c", "d", "e"},
+			parallelism: 2,
+			durations:   map[string]int{"a": 10, "b": 50, "c": 20, "d": 20, "e": 10},
+			want: [][]TestCase{
+				{{Path: "b", EstimatedDuration: 50, TimingSampleSize: 1}, {Path: "e", EstimatedDuration: 10, TimingSampleSize: 1}},
+				{{Path: "c", EstimatedDuration: 20, TimingSampleSize: 1}, {Path: "d", EstimatedDuration: 20, TimingSampleSize: 1}, {Path: "a", EstimatedDuration: 10, TimingSampleSize: 1}},
+			},
+		},
+		{
+			name:        "uses the median for unknown files",
+			files:       []string{"a", "b", "c", "new"},
+			parallelism: 2,
+			durations:   map[string]int{"a": 10, "b": 30, "c": 60, "deleted": 1000},
+			want: [][]TestCase{
+				{{Path: "c", EstimatedDuration: 60, TimingSampleSize: 1}, {Path: "a", EstimatedDuration: 10, TimingSampleSize: 1}},
+				{{Path: "b", EstimatedDuration: 30, TimingSampleSize: 1}, {Path: "new", EstimatedDuration: 30}},
+			},
+		},
+		{
+			name:        "adds up example durations",
+			files:       []string{"a_spec.rb", "test_b.py", "c"},
+			parallelism: 2,
+			durations: map[string]int{
+				"a_spec.rb[1:1]":     30,
+				"a_spec.rb[1:2]":     40,
+				"test_b.py::test_x":  20,
+				"test_b.py::test_y":  20,
+				"c":                  50,
+				"unknown_spec.rb[1]": 1000,
+			},
+			want: [][]TestCase{
+				{{Path: "a_spec.rb", EstimatedDuration: 70, TimingSampleSize: 1}},
+				{{Path: "c", EstimatedDuration: 50, TimingSampleSize: 1}, {Path: "test_b.py", EstimatedDuration: 40, TimingSampleSize: 1}},
+			},
+		},
+		{
+			name:        "without known durations",
+			files:       []string{"a", "b", "c"},
+			parallelism: 2,
+			durations:   map[string]int{"deleted": 10},
+			want: [][]TestCase{
+				{{Path: "a"}, {Path: "c"}},
+				{{Path: "b"}},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			plan := CreateTimedFallbackPlan(s.files, s.parallelism, s.durations)
+			got := make([][]TestCase, s.parallelism)
+			for _, task := range plan.Tasks {
+				got[task.NodeNumber] = task.Tests
+			}
+
+			if !plan.Fallback {
+				t.Errorf("CreateTimedFallbackPlan(%v, %v, %v) Fallback is %v, want %v", s.files, s.parallelism, s.durations, plan.Fallback, true)
+			}
+
+			if diff := cmp.Diff(got, s.want); diff != "" {
+				t.Errorf("CreateTimedFallbackPlan(%v, %v, %v) diff (-got +want):\n%s", s.files, s.parallelism, s.durations, diff)
+			}
+		})
+	}
+}
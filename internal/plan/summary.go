@@ -11,10 +11,12 @@ import (
 // to w (typically os.Stderr). At parallelism > 1 it uses per-format
 // TimingMetadata to break down known vs unknown cases. At parallelism == 1 the
 // server skips the per-format timing fetch and emits an empty TimingMetadata,
-// so only the header and case count are printed. Skipped for fallback plans and
-// plans without TimingMetadata at all (e.g. error or older cached plans).
+// so only the header and case count are printed. Skipped for plans without
+// TimingMetadata at all (e.g. error or older cached plans), and for fallback
+// plans unless they were created from local timings. Those also print the
+// expected duration of each node, as no server-side estimate is available.
 func PrintSplitSummary(w io.Writer, p TestPlan) {
-	if p.Fallback || p.TimingMetadata == nil {
+	if p.TimingMetadata == nil || (p.Fallback && !p.hasKnownTimings()) {
 		return
 	}
 
@@ -51,9 +53,31 @@ func PrintSplitSummary(w io.Writer, p TestPlan) {
 	if selectorTotal > 0 {
 		printFormatBreakdown(w, selectorTotal, selectorKnown, "selector", p.TimingMetadata.Selector, mixed)
 	}
+	if p.Fallback {
+		printNodeDurations(w, p, noun)
+	}
 	fmt.Fprintln(w)
 }
 
+// printNodeDurations writes the expected duration of each node, ordered by node number.
+func printNodeDurations(w io.Writer, p TestPlan, noun string) {
+	fmt.Fprintln(w, "Expected duration per node:")
+	width := len(strconv.Itoa(p.Parallelism - 1))
+	for i := 0; i < p.Parallelism; i++ {
+		task, ok := p.Tasks[strconv.Itoa(i)]
+		if !ok {
+			continue
+		}
+
+		duration := 0
+		for _, tc := range task.Tests {
+			duration += tc.EstimatedDuration
+		}
+		fmt.Fprintf(w, "  Node %*d: %s (%d %s)\n",
+			width, i, formatDurationMS(float64(duration)), len(task.Tests), pluralize(len(task.Tests), noun))
+	}
+}
+
 // HasNoSelectorTimingHistory reports whether a multi-node selector plan used
 // default durations because no historical selector timings were available.
 func (p TestPlan) HasNoSelectorTimingHistory() bool {
@@ -66,6 +90,18 @@ func (p TestPlan) HasNoSelectorTimingHistory() bool {
 	return selectorTotal > 0 && selectorKnown == 0
 }
 
+// hasKnownTimings reports whether any case in the plan has a historical duration.
+func (p TestPlan) hasKnownTimings() bool {
+	for _, task := range p.Tasks {
+		for _, tc := range task.Tests {
+			if tc.TimingSampleSize > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // countByFormat returns (total, known) for cases of the given format. The
 // empty (default) Format value is treated as TestCaseFormatFile.
 func countByFormat(p TestPlan, format TestCaseFormat) (total, known int) {
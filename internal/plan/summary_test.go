@@ -359,6 +359,43 @@ func TestPrintSplitSummary_SkipsFallback(t *testing.T) {
 	}
 }
 
+func TestPrintSplitSummary_TimedFallback(t *testing.T) {
+	p := CreateTimedFallbackPlan([]string{"a", "b", "c", "d"}, 2, map[string]int{"a": 4000, "b": 1500, "c": 2000})
+
+	var buf bytes.Buffer
+	PrintSplitSummary(&buf, p)
+	got := buf.String()
+
+	for _, want := range []string{
+		"+++ Buildkite Test Engine Client: 📊 Split summary\n4 files across 2 nodes",
+		"3 files (75%) estimated from past historical durations",
+		"1 file (25%) had no history — assumed median (2.0s)",
+		"Expected duration per node:\n  Node 0: 5.5s (2 files)\n  Node 1: 4.0s (2 files)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q\nfull output:\n%s", want, got)
+		}
+	}
+}
+
+func TestPrintSplitSummary_NoNodeDurationsForServerPlan(t *testing.T) {
+	p := TestPlan{
+		Parallelism: 1,
+		Tasks: map[string]*Task{
+			"0": {NodeNumber: 0, Tests: []TestCase{{Path: "a", EstimatedDuration: 1000, TimingSampleSize: 1}}},
+		},
+		TimingMetadata: &TimingMetadata{
+			File: &FormatTimingMetadata{MedianDuration: fp(1000), DefaultDuration: 1000},
+		},
+	}
+
+	var buf bytes.Buffer
+	PrintSplitSummary(&buf, p)
+	if got := buf.String(); strings.Contains(got, "Expected duration per node") {
+		t.Errorf("unexpected node durations for server plan, got:\n%s", got)
+	}
+}
+
 func TestPercentOf(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,104 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TimingCache is a local copy of the estimated durations from the last
+// successful test plans. It is used to balance a fallback plan when the
+// Test Engine API can't be reached.
+//
+// One file can hold the timings of several suites and runners, so it can be
+// shared between steps when it's restored from a cache or an artifact.
+type TimingCache struct {
+	Entries map[string]TimingCacheEntry `json:"entries"`
+}
+
+// TimingCacheEntry holds the timings of a single suite and runner.
+type TimingCacheEntry struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	// Durations is the estimated duration in milliseconds of each test target,
+	// keyed by the path (or selector value) that the runner receives.
+	Durations map[string]int `json:"durations"`
+}
+
+func timingCacheKey(suiteSlug, runner string) string {
+	return suiteSlug + "/" + runner
+}
+
+// LoadTimingCache reads the timing cache at path.
+// A missing file is not an error, and returns an empty cache.
+func LoadTimingCache(path string) (TimingCache, error) {
+	cache := TimingCache{Entries: map[string]TimingCacheEntry{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, fmt.Errorf("reading timing cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return TimingCache{Entries: map[string]TimingCacheEntry{}}, fmt.Errorf("parsing timing cache %s: %w", path, err)
+	}
+
+	if cache.Entries == nil {
+		cache.Entries = map[string]TimingCacheEntry{}
+	}
+	return cache, nil
+}
+
+// Durations returns the cached durations for the suite and runner, or nil if there are none.
+func (c TimingCache) Durations(suiteSlug, runner string) map[string]int {
+	return c.Entries[timingCacheKey(suiteSlug, runner)].Durations
+}
+
+// Set replaces the cached durations for the suite and runner.
+func (c *TimingCache) Set(suiteSlug, runner string, durations map[string]int, updatedAt time.Time) {
+	if c.Entries == nil {
+		c.Entries = map[string]TimingCacheEntry{}
+	}
+	c.Entries[timingCacheKey(suiteSlug, runner)] = TimingCacheEntry{
+		UpdatedAt: updatedAt,
+		Durations: durations,
+	}
+}
+
+// Save writes the timing cache to path, creating the parent directory if needed.
+// The file is replaced atomically, so parallel jobs sharing a path never read a partial file.
+func (c TimingCache) Save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encoding timing cache: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating timing cache directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing timing cache: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing timing cache: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing timing cache: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("writing timing cache: %w", err)
+	}
+	return nil
+}
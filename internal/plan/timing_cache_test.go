@@ -0,0 +1,63 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTimingCache_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "cache.json")
+	updatedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cache := TimingCache{}
+	cache.Set("suite", "rspec", map[string]int{"a_spec.rb": 100}, updatedAt)
+	cache.Set("suite", "jest", map[string]int{"a.test.js": 200}, updatedAt)
+	if err := cache.Save(path); err != nil {
+		t.Fatalf("TimingCache.Save(%q) error = %v", path, err)
+	}
+
+	got, err := LoadTimingCache(path)
+	if err != nil {
+		t.Fatalf("LoadTimingCache(%q) error = %v", path, err)
+	}
+
+	if diff := cmp.Diff(got, cache); diff != "" {
+		t.Errorf("LoadTimingCache(%q) diff (-got +want):\n%s", path, diff)
+	}
+
+	if diff := cmp.Diff(got.Durations("suite", "rspec"), map[string]int{"a_spec.rb": 100}); diff != "" {
+		t.Errorf("TimingCache.Durations(%q, %q) diff (-got +want):\n%s", "suite", "rspec", diff)
+	}
+
+	if durations := got.Durations("other-suite", "rspec"); durations != nil {
+		t.Errorf("TimingCache.Durations(%q, %q) = %v, want nil", "other-suite", "rspec", durations)
+	}
+}
+
+func TestLoadTimingCache_FileNotExist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	got, err := LoadTimingCache(path)
+	if err != nil {
+		t.Errorf("LoadTimingCache(%q) error = %v", path, err)
+	}
+
+	if len(got.Entries) != 0 {
+		t.Errorf("LoadTimingCache(%q) entries = %v, want empty", path, got.Entries)
+	}
+}
+
+func TestLoadTimingCache_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadTimingCache(path); err == nil {
+		t.Errorf("LoadTimingCache(%q) error = nil, want error", path)
+	}
+}
@@ -0,0 +1,116 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// timingsFileRow is a row of a JSON timings file.
+type timingsFileRow struct {
+	Path     string  `json:"path"`
+	Duration float64 `json:"duration"`
+}
+
+// LoadTimingsFile reads the durations in milliseconds of test paths from a timings file.
+// Files ending in ".csv" have a path and a duration per row, with an optional header row.
+// Other files are JSON, either an array of {"path": ..., "duration": ...} objects
+// or an object mapping paths to durations.
+// When a path appears more than once, the last duration is used.
+func LoadTimingsFile(path string) (map[string]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading timings file: %w", err)
+	}
+
+	var durations map[string]int
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		durations, err = parseTimingsCSV(data)
+	} else {
+		durations, err = parseTimingsJSON(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing timings file %s: %w", path, err)
+	}
+
+	return durations, nil
+}
+
+func parseTimingsJSON(data []byte) (map[string]int, error) {
+	durations := make(map[string]int)
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var m map[string]float64
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		for path, d := range m {
+			if err := setDuration(durations, path, d); err != nil {
+				return nil, err
+			}
+		}
+		return durations, nil
+	}
+
+	var rows []timingsFileRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		if row.Path == "" {
+			return nil, fmt.Errorf("row %d: missing path", i+1)
+		}
+		if err := setDuration(durations, row.Path, row.Duration); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+	return durations, nil
+}
+
+func parseTimingsCSV(data []byte) (map[string]int, error) {
+	durations := make(map[string]int)
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			// The first row can be a header, e.g. "path,duration".
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid duration %q", line, record[1])
+		}
+
+		if err := setDuration(durations, record[0], d); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return durations, nil
+}
+
+func setDuration(durations map[string]int, path string, d float64) error {
+	if d < 0 || math.IsNaN(d) || math.IsInf(d, 0) {
+		return fmt.Errorf("invalid duration %v for %q", d, path)
+	}
+	durations[path] = int(math.Round(d))
+	return nil
+}
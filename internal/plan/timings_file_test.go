@@ -0,0 +1,95 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadTimingsFile(t *testing.T) {
+	scenarios := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]int
+	}{
+		{
+			name:    "json rows",
+			file:    "timings.json",
+			content: `[{"path": "a_spec.rb", "duration": 1200}, {"path": "b_spec.rb", "duration": 300.4}, {"path": "a_spec.rb", "duration": 1500}]`,
+			want:    map[string]int{"a_spec.rb": 1500, "b_spec.rb": 300},
+		},
+		{
+			name:    "json object",
+			file:    "timings.json",
+			content: `{"a_spec.rb": 1200, "b_spec.rb": 300}`,
+			want:    map[string]int{"a_spec.rb": 1200, "b_spec.rb": 300},
+		},
+		{
+			name:    "csv with header",
+			file:    "timings.csv",
+			content: "path,duration\na_spec.rb,1200\n\"b spec,rb\", 300\n",
+			want:    map[string]int{"a_spec.rb": 1200, "b spec,rb": 300},
+		},
+		{
+			name:    "csv without header",
+			file:    "timings.CSV",
+			content: "a_spec.rb,1200\nb_spec.rb,300.5\n",
+			want:    map[string]int{"a_spec.rb": 1200, "b_spec.rb": 301},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), s.file)
+			if err := os.WriteFile(path, []byte(s.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadTimingsFile(path)
+			if err != nil {
+				t.Fatalf("LoadTimingsFile(%q) error = %v", path, err)
+			}
+
+			if diff := cmp.Diff(got, s.want); diff != "" {
+				t.Errorf("LoadTimingsFile(%q) diff (-got +want):\n%s", path, diff)
+			}
+		})
+	}
+}
+
+func TestLoadTimingsFile_Invalid(t *testing.T) {
+	scenarios := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "invalid json", file: "timings.json", content: `not json`},
+		{name: "json row without path", file: "timings.json", content: `[{"duration": 100}]`},
+		{name: "negative duration", file: "timings.json", content: `{"a_spec.rb": -1}`},
+		{name: "invalid csv duration", file: "timings.csv", content: "a_spec.rb,100\nb_spec.rb,slow\n"},
+		{name: "csv with extra column", file: "timings.csv", content: "a_spec.rb,100,extra\n"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), s.file)
+			if err := os.WriteFile(path, []byte(s.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := LoadTimingsFile(path); err == nil {
+				t.Errorf("LoadTimingsFile(%q) error = nil, want error", path)
+			}
+		})
+	}
+}
+
+func TestLoadTimingsFile_FileNotExist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timings.json")
+	if _, err := LoadTimingsFile(path); err == nil {
+		t.Errorf("LoadTimingsFile(%q) error = nil, want error", path)
+	}
+}
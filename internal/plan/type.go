@@ -1,5 +1,7 @@
 package plan
 
+import "time"
+
 type TestCaseFormat string
 
 const (
@@ -63,6 +65,15 @@ type Task struct {
 	Tests []TestCase `json:"tests"`
 }
 
+// EstimatedDuration returns the total estimated duration of the tests in the task.
+func (t Task) EstimatedDuration() time.Duration {
+	var ms int
+	for _, tc := range t.Tests {
+		ms += tc.EstimatedDuration
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
 // TestPlan represents the entire test plan.
 type TestPlan struct {
 	Identifier   string           `json:"identifier"`
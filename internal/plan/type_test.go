@@ -3,6 +3,7 @@ package plan
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -193,3 +194,15 @@ func TestTestPlan_RoundTrip(t *testing.T) {
 		t.Errorf("round-trip diff (-want +got):\n%s", diff)
 	}
 }
+
+func TestTask_EstimatedDuration(t *testing.T) {
+	task := Task{Tests: []TestCase{
+		{Path: "a_spec.rb", EstimatedDuration: 1500},
+		{Path: "b_spec.rb", EstimatedDuration: 250},
+		{Path: "c_spec.rb"},
+	}}
+
+	if got, want := task.EstimatedDuration(), 1750*time.Millisecond; got != want {
+		t.Errorf("Task.EstimatedDuration() = %s, want %s", got, want)
+	}
+}
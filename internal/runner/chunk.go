@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+)
+
+// RunChunked runs the tests with the runner, in as many sequential commands as needed to keep each
+// command under the argument limit of the OS. The results of every command are recorded in the same
+// result. Commands taking the tests in a "{{testListFile}}" are never split.
+//
+// When a command fails, the next ones are still run, and the error of the first failed command is
+// returned, unless the command was interrupted by a signal, or couldn't be started.
+//
+// afterRun is called after each command, while its result files are still in place: the result files of
+// a command are replaced by the next one.
+func RunChunked(r TestRunner, result *RunResult, testCases []plan.TestCase, retry bool, afterRun func()) error {
+	chunks := CommandChunks(r, testCases, retry)
+	if len(chunks) == 1 {
+		err := r.Run(result, testCases, retry)
+		afterRun()
+		return err
+	}
+
+	fmt.Printf("Buildkite Test Engine Client: The %s command for %d tests exceeds the argument limit of the OS, the tests will be run in %d commands\n", r.Name(), len(testCases), len(chunks))
+
+	var runErr error
+	for i, chunk := range chunks {
+		fmt.Printf("Buildkite Test Engine Client: Running command %d of %d with %d tests\n", i+1, len(chunks), len(chunk))
+		err := r.Run(result, chunk, retry)
+		afterRun()
+		if err == nil {
+			continue
+		}
+
+		// A command that exited by itself, usually because tests failed, doesn't stop the other
+		// commands. A command killed by a signal (e.g. the job being cancelled) does.
+		if exitError := new(exec.ExitError); !errors.As(err, &exitError) || exitError.ExitCode() < 0 {
+			return err
+		}
+
+		if runErr == nil {
+			runErr = err
+		}
+	}
+
+	return runErr
+}
+
+// CommandChunks splits the tests into the groups that can be run by a single command of the runner
+// without exceeding the argument limit of the OS. The tests are split in halves until each command
+// fits, keeping their order. A command that can't be built isn't split, so the error is reported
+// when the tests are run.
+func CommandChunks(r TestRunner, testCases []plan.TestCase, retry bool) [][]plan.TestCase {
+	// Most commands are far below the limit, so they aren't built twice to measure them.
+	if estimateCommandLineLength(testCases) < maxArgumentLength/2 {
+		return [][]plan.TestCase{testCases}
+	}
+
+	if len(testCases) <= 1 || commandFits(r, testCases, retry) {
+		return [][]plan.TestCase{testCases}
+	}
+
+	mid := len(testCases) / 2
+	return append(CommandChunks(r, testCases[:mid], retry), CommandChunks(r, testCases[mid:], retry)...)
+}
+
+// estimateCommandLineLength returns an upper estimate of the length the tests add to a command,
+// when each test is given by its path or by a pattern of its scope and name.
+func estimateCommandLineLength(testCases []plan.TestCase) int {
+	length := 0
+	for _, tc := range testCases {
+		// Patterns escape the scope and name, which can double their length.
+		length += len(tc.Path) + len(tc.Value) + 2*(len(tc.Scope)+len(tc.Name)) + 16
+	}
+	return length
+}
+
+func commandFits(r TestRunner, testCases []plan.TestCase, retry bool) bool {
+	name, args, err := r.CommandNameAndArgs(testCases, retry)
+	if err != nil {
+		return true
+	}
+
+	words := append([]string{name}, args...)
+	for _, word := range words {
+		if len(word) > maxArgumentLength {
+			return false
+		}
+	}
+
+	return commandLineLength(words, os.Environ()) <= maxCommandLineLength
+}
@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// longTestCases returns enough test cases for their paths to exceed the argument limit of the OS.
+func longTestCases() []plan.TestCase {
+	var testCases []plan.TestCase
+	for i := range 2 * maxCommandLineLength / 1000 {
+		testCases = append(testCases, plan.TestCase{Path: fmt.Sprintf("tests/%04d_%s_test.sh", i, strings.Repeat("a", 1000))})
+	}
+	return testCases
+}
+
+func TestCommandChunks(t *testing.T) {
+	custom, err := NewCustom(RunnerConfig{
+		TestCommand:     "bin/test {{testExamples}}",
+		TestFilePattern: "tests/**/*_test.sh",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Custom runner: %v", err)
+	}
+
+	testCases := longTestCases()
+	chunks := CommandChunks(custom, testCases, false)
+
+	if len(chunks) < 2 {
+		t.Fatalf("CommandChunks() returned %d chunks, want at least 2", len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		if !commandFits(custom, chunk, false) {
+			t.Errorf("CommandChunks() chunk %d of %d tests exceeds the argument limit", i, len(chunk))
+		}
+	}
+
+	if diff := cmp.Diff(slices.Concat(chunks...), testCases); diff != "" {
+		t.Errorf("CommandChunks() tests diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestCommandChunks_UnderLimit(t *testing.T) {
+	custom, err := NewCustom(RunnerConfig{
+		TestCommand:     "bin/test {{testExamples}}",
+		TestFilePattern: "tests/**/*_test.sh",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Custom runner: %v", err)
+	}
+
+	testCases := []plan.TestCase{{Path: "tests/a_test.sh"}, {Path: "tests/b_test.sh"}}
+	got := CommandChunks(custom, testCases, false)
+	want := [][]plan.TestCase{testCases}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("CommandChunks() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestCommandChunks_TestListFile(t *testing.T) {
+	custom, err := NewCustom(RunnerConfig{
+		TestCommand:     "bin/test --file-list {{testListFile}}",
+		TestFilePattern: "tests/**/*_test.sh",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Custom runner: %v", err)
+	}
+
+	testCases := longTestCases()
+	if got := CommandChunks(custom, testCases, false); len(got) != 1 {
+		t.Errorf("CommandChunks() returned %d chunks, want 1", len(got))
+	}
+}
+
+func TestRunChunked(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "result.json")
+	testCases := longTestCases()
+	first, last := testCases[0].Path, testCases[len(testCases)-1].Path
+
+	// Each command writes a Test Engine JSON report of the tests it was given, where the first and
+	// last tests of the node fail, so they are in different commands.
+	script := `out=$0; sep=; printf "[" > "$out"; ` +
+		`for f in "$@"; do case "$f" in ` + first + `|` + last + `) status=failed;; *) status=passed;; esac; ` +
+		`printf "%s{\"scope\": \"chunk\", \"name\": \"%s\", \"file_name\": \"%s\", \"result\": \"%s\"}" "$sep" "$f" "$f" "$status" >> "$out"; sep=,; done; ` +
+		`printf "]" >> "$out"; exit 1`
+	custom, err := NewCustom(RunnerConfig{
+		TestCommand:     fmt.Sprintf(`sh -c '%s' %s {{testExamples}}`, script, resultPath),
+		TestFilePattern: "tests/**/*_test.sh",
+		ResultPath:      resultPath,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Custom runner: %v", err)
+	}
+
+	chunks := CommandChunks(custom, testCases, false)
+	if len(chunks) < 2 {
+		t.Fatalf("CommandChunks() returned %d chunks, want at least 2", len(chunks))
+	}
+
+	result := NewRunResult([]plan.TestCase{})
+	runs := 0
+	err = RunChunked(custom, result, testCases, false, func() { runs++ })
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if runs != len(chunks) {
+		t.Errorf("RunChunked() called afterRun %d times, want %d", runs, len(chunks))
+	}
+
+	if got := result.Statistics().Total; got != len(testCases) {
+		t.Errorf("RunChunked() RunResult.Statistics().Total = %d, want %d", got, len(testCases))
+	}
+
+	var failed []string
+	for _, tc := range result.FailedTests() {
+		failed = append(failed, tc.Name)
+	}
+	slices.Sort(failed)
+
+	if diff := cmp.Diff(failed, []string{first, last}); diff != "" {
+		t.Errorf("RunChunked() RunResult.FailedTests() names diff (-got +want):\n%s", diff)
+	}
+}
@@ -11,10 +11,24 @@ import (
 	"github.com/buildkite/test-engine-client/v3/internal/plan"
 )
 
-func buildCommand(runner TestRunner, testCases []plan.TestCase, retry bool) (*exec.Cmd, error) {
+// buildCommand returns the command of the runner for the test cases. When the command takes the tests in
+// a "{{testListFile}}", the file is written, and the cleanup func removes it. The cleanup func must be called
+// once the command has run. Runners that aren't a testLister can't take the tests in a "{{testListFile}}".
+func buildCommand(runner TestRunner, testCases []plan.TestCase, retry bool) (*exec.Cmd, func(), error) {
 	commandName, commandArgs, err := runner.CommandNameAndArgs(testCases, retry)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build command: %w", err)
+		return nil, nil, fmt.Errorf("failed to build command: %w", err)
+	}
+
+	cleanup := func() {}
+	if lister, ok := runner.(testLister); ok {
+		words, listCleanup, err := writeTestListFile(append([]string{commandName}, commandArgs...), lister.testListPaths(testCases, retry))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build command: %w", err)
+		}
+		commandName, commandArgs, cleanup = words[0], words[1:], listCleanup
+	} else if hasTestListFile(append([]string{commandName}, commandArgs...)) {
+		return nil, nil, fmt.Errorf("failed to build command: the %s runner doesn't support %s", runner.Name(), testListFilePlaceholder)
 	}
 
 	cmd := exec.Command(commandName, commandArgs...)
@@ -24,7 +38,7 @@ func buildCommand(runner TestRunner, testCases []plan.TestCase, retry bool) (*ex
 	env = append(env, analyticsTokenEnv)
 	cmd.Env = env
 
-	return cmd, nil
+	return cmd, cleanup, nil
 }
 
 // runAndForwardSignal runs the command and forwards any signals received to the command.
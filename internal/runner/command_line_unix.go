@@ -0,0 +1,21 @@
+//go:build !windows
+
+package runner
+
+// Starting a command fails with E2BIG when its arguments and environment exceed ARG_MAX,
+// which is 1 MiB on macOS and usually 2 MiB on Linux, or when a single argument exceeds
+// 128 KiB on Linux.
+const (
+	maxCommandLineLength = 1 << 20
+	maxArgumentLength    = 128<<10 - 1
+)
+
+// commandLineLength returns the size of the arguments and environment of a command,
+// as counted against ARG_MAX: each string is null-terminated, and referenced by a pointer.
+func commandLineLength(args []string, env []string) int {
+	length := 0
+	for _, s := range append(args, env...) {
+		length += len(s) + 1 + 8
+	}
+	return length
+}
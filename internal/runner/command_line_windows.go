@@ -0,0 +1,19 @@
+//go:build windows
+
+package runner
+
+// A command line is limited to 32767 characters on Windows. The environment has a separate limit.
+const (
+	maxCommandLineLength = 32767
+	maxArgumentLength    = maxCommandLineLength
+)
+
+// commandLineLength returns the length of the command line of a command, where the arguments are
+// separated by spaces and may be quoted.
+func commandLineLength(args []string, env []string) int {
+	length := 0
+	for _, arg := range args {
+		length += len(arg) + 3
+	}
+	return length
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CTRFTest represents a test of a CTRF (Common Test Report Format) report.
+// Only the attributes needed by bktec are included.
+// Ref: https://ctrf.io/docs/specification/test
+type CTRFTest struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Duration is the duration of the test in milliseconds.
+	Duration float64   `json:"duration"`
+	Suite    ctrfSuite `json:"suite"`
+	Message  string    `json:"message"`
+	Trace    string    `json:"trace"`
+	FilePath string    `json:"filePath"`
+	Line     int       `json:"line"`
+}
+
+// ctrfSuite is the suite of a CTRF test. Older reporters write it as a string,
+// and newer ones as the list of nested suites, which are joined with " > ".
+type ctrfSuite string
+
+func (s *ctrfSuite) UnmarshalJSON(data []byte) error {
+	var suites []string
+	if err := json.Unmarshal(data, &suites); err == nil {
+		*s = ctrfSuite(strings.Join(suites, " > "))
+		return nil
+	}
+
+	var suite string
+	if err := json.Unmarshal(data, &suite); err != nil {
+		return fmt.Errorf("suite must be a string or a list of strings: %w", err)
+	}
+	*s = ctrfSuite(suite)
+	return nil
+}
+
+type ctrfReport struct {
+	Results struct {
+		Tests []CTRFTest `json:"tests"`
+	} `json:"results"`
+}
+
+// Attempt returns the result of the test, including its duration and failure.
+func (t CTRFTest) Attempt() TestAttempt {
+	attempt := TestAttempt{
+		Status:   ctrfTestStatus(t.Status),
+		Duration: millisecondsToDuration(t.Duration),
+	}
+
+	if attempt.Status == TestStatusFailed && (t.Message != "" || t.Trace != "") {
+		failure := &TestFailure{Message: strings.TrimSpace(t.Message)}
+		for _, line := range strings.Split(strings.TrimSpace(t.Trace), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				failure.Backtrace = append(failure.Backtrace, line)
+			}
+		}
+		attempt.Failure = failure
+	}
+	return attempt
+}
+
+// ctrfTestStatus maps the status of a CTRF test to a test status.
+// Pending tests haven't run, like skipped tests.
+func ctrfTestStatus(status string) TestStatus {
+	switch status {
+	case "passed":
+		return TestStatusPassed
+	case "failed":
+		return TestStatusFailed
+	case "skipped", "pending":
+		return TestStatusSkipped
+	default:
+		return TestStatusUnknown
+	}
+}
+
+func loadAndParseCTRF(path string) ([]CTRFTest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CTRF file %s: %w", path, err)
+	}
+
+	var report ctrfReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse CTRF file %s: %w", path, err)
+	}
+
+	return report.Results.Tests, nil
+}
@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadAndParseCTRF(t *testing.T) {
+	results, err := loadAndParseCTRF("./testdata/definitions/results/report.ctrf.json")
+	if err != nil {
+		t.Fatalf("loadAndParseCTRF() error = %v", err)
+	}
+
+	want := []CTRFTest{
+		{Name: "returns the user", Status: "passed", Duration: 12, Suite: "api/users", FilePath: "api/users.hurl", Line: 3},
+		{
+			Name:     "creates the user",
+			Status:   "failed",
+			Duration: 1500.5,
+			Suite:    "api > users > create",
+			FilePath: "api/users.hurl",
+			Line:     10,
+			Message:  "Assert status code\n  expected: 201\n  actual:   500",
+			Trace:    "api/users.hurl:10:6\n  api/users.hurl:12:1\n",
+		},
+		{Name: "deletes the user", Status: "skipped", FilePath: "api/users.hurl"},
+		{Name: "lists the users", Status: "pending"},
+	}
+
+	if diff := cmp.Diff(results, want); diff != "" {
+		t.Errorf("loadAndParseCTRF() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestCTRFTest_Attempt(t *testing.T) {
+	cases := []struct {
+		test CTRFTest
+		want TestAttempt
+	}{
+		{
+			test: CTRFTest{Status: "passed", Duration: 12},
+			want: TestAttempt{Status: TestStatusPassed, Duration: 12 * time.Millisecond},
+		},
+		{
+			test: CTRFTest{
+				Status:   "failed",
+				Duration: 1500.5,
+				Message:  "Assert status code\n  expected: 201\n  actual:   500",
+				Trace:    "api/users.hurl:10:6\n  api/users.hurl:12:1\n",
+			},
+			want: TestAttempt{
+				Status:   TestStatusFailed,
+				Duration: 1500500 * time.Microsecond,
+				Failure: &TestFailure{
+					Message:   "Assert status code\n  expected: 201\n  actual:   500",
+					Backtrace: []string{"api/users.hurl:10:6", "api/users.hurl:12:1"},
+				},
+			},
+		},
+		{
+			test: CTRFTest{Status: "failed"},
+			want: TestAttempt{Status: TestStatusFailed},
+		},
+		{
+			test: CTRFTest{Status: "pending"},
+			want: TestAttempt{Status: TestStatusSkipped},
+		},
+		{
+			test: CTRFTest{Status: "other"},
+			want: TestAttempt{Status: TestStatusUnknown},
+		},
+	}
+
+	for _, tc := range cases {
+		if diff := cmp.Diff(tc.test.Attempt(), tc.want); diff != "" {
+			t.Errorf("CTRFTest{Status: %q}.Attempt() diff (-got +want):\n%s", tc.test.Status, diff)
+		}
+	}
+}
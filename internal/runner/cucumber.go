@@ -84,10 +84,11 @@ func (c Cucumber) DiscoverTestTargets() ([]string, error) {
 
 // Run executes the Cucumber command and records results.
 func (c Cucumber) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(c, testCases, retry)
+	cmd, cleanup, err := buildCommand(c, testCases, retry)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 
 	cmdErr := runAndForwardSignal(cmd)
 
@@ -128,7 +129,7 @@ func (c Cucumber) Run(result *RunResult, testCases []plan.TestCase, retry bool)
 				Path:       fileLinePath,
 			}
 
-			result.RecordTestResult(testCaseForResult, testStatus)
+			result.RecordTestAttempt(testCaseForResult, scenario.Attempt(testStatus))
 		}
 	}
 
@@ -220,9 +221,13 @@ func (c Cucumber) GetExamples(files []string) ([]plan.TestCase, error) {
 	return testCases, nil
 }
 
+func (c Cucumber) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	return pathsFromTestCases(testCases)
+}
+
 // CommandNameAndArgs replaces placeholders and returns command + args.
 func (c Cucumber) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
-	testPaths := pathsFromTestCases(testCases)
+	testPaths := c.testListPaths(testCases, retry)
 
 	cmd := c.TestCommand
 	if retry {
@@ -235,10 +240,10 @@ func (c Cucumber) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (str
 	}
 
 	idx := slices.Index(words, "{{testExamples}}")
-	if idx < 0 {
-		words = append(words, testPaths...)
-	} else {
+	if idx >= 0 {
 		words = slices.Replace(words, idx, idx+1, testPaths...)
+	} else if !hasTestListFile(words) {
+		words = append(words, testPaths...)
 	}
 
 	idx = slices.Index(words, "{{resultPath}}")
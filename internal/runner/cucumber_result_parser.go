@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 )
 
 // CucumberFeature represents a single feature in Cucumber's JSON output.
@@ -66,6 +67,22 @@ func (e CucumberElement) AggregatedStatus() string {
 	return status
 }
 
+// Attempt returns the result of the scenario with the given status, including its duration
+// and the error of the first failed step.
+func (e CucumberElement) Attempt(status TestStatus) TestAttempt {
+	attempt := TestAttempt{Status: status}
+	for _, step := range e.Steps {
+		if step.Result == nil {
+			continue
+		}
+		attempt.Duration += time.Duration(step.Result.Duration)
+		if attempt.Failure == nil && step.Result.ErrorMessage != "" {
+			attempt.Failure = parseTestFailure("", step.Result.ErrorMessage)
+		}
+	}
+	return attempt
+}
+
 // CucumberStep represents a single step in a scenario.
 type CucumberStep struct {
 	Keyword       string                 `json:"keyword"`
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/buildkite/test-engine-client/v3/internal/debug"
@@ -39,7 +41,8 @@ func (c Custom) SupportedFeatures() SupportedFeatures {
 		SplitByExample:  false,
 		FilterTestFiles: true,
 		FilterTestByTag: false,
-		AutoRetry:       false,
+		// The failed tests are only known from the result file.
+		AutoRetry:       c.ResultPath != "",
 		Mute:            true,
 		Skip:            false,
 		SplitBySelector: true,
@@ -78,61 +81,107 @@ func (r Custom) DiscoverTestTargets() ([]string, error) {
 
 	return files, nil
 }
+
+// Run executes the test command and records the results from ResultPath, which can be a glob matching
+// several report files. Files ending in ".xml" are read as JUnit XML, anything else is read as
+// Test Engine JSON.
 func (r Custom) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(r, testCases, retry)
+	cmd, cleanup, err := buildCommand(r, testCases, retry)
 	if err != nil {
 		return err
 	}
-
-	cmdErr := runAndForwardSignal(cmd)
+	defer cleanup()
 
 	// If the result path is not set, bubble up the error directly.
 	if r.ResultPath == "" {
+		return runAndForwardSignal(cmd)
+	}
+
+	if err := removeResultFiles(r.ResultPath); err != nil {
+		return err
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	if parseErr := r.parseResults(result); parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read %s output, tests will not be retried: %v\n", r.Name(), parseErr)
 		return cmdErr
 	}
 
-	if strings.HasSuffix(r.ResultPath, ".xml") {
-		tests, parseErr := loadAndParseJUnitXML(r.ResultPath)
-		if parseErr != nil {
-			fmt.Printf("Buildkite Test Engine Client: Failed to read JUnit XML output: %v\n", parseErr)
-			return cmdErr
+	// Return any command error after processing the report
+	return cmdErr
+}
+
+func (r Custom) parseResults(result *RunResult) error {
+	files, err := ResultFiles(r.ResultPath)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no result files found matching %q", r.ResultPath)
+	}
+
+	for _, file := range files {
+		if err := r.parseResultFile(result, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r Custom) parseResultFile(result *RunResult, file string) error {
+	if strings.HasSuffix(file, ".xml") {
+		tests, err := loadAndParseJUnitXML(file)
+		if err != nil {
+			return err
 		}
 		for _, test := range tests {
-			result.RecordTestResult(plan.TestCase{
+			result.RecordTestAttempt(plan.TestCase{
 				Format: plan.TestCaseFormatExample,
 				Scope:  test.Classname,
 				Name:   test.Name,
 				Path:   test.Classname,
-			}, test.Result)
-		}
-	} else {
-		tests, parseErr := parseTestEngineTestResult(r.ResultPath)
-		if parseErr != nil {
-			fmt.Printf("Buildkite Test Engine Client: Failed to read test engine JSON output: %v\n", parseErr)
-			return cmdErr
-		}
-		for _, test := range tests {
-			result.RecordTestResult(plan.TestCase{
-				Identifier: test.ID,
-				Format:     plan.TestCaseFormatExample,
-				Scope:      test.Scope,
-				Name:       test.Name,
-				Path:       fmt.Sprintf("%s:%s", test.FileName, test.Location),
-			}, test.Result)
+			}, test.Attempt())
 		}
+		return nil
 	}
 
-	// Return any command error after processing the report
-	return cmdErr
+	tests, err := parseTestEngineTestResult(file)
+	if err != nil {
+		return err
+	}
+	for _, test := range tests {
+		result.RecordTestAttempt(plan.TestCase{
+			Identifier: test.ID,
+			Format:     plan.TestCaseFormatExample,
+			Scope:      test.Scope,
+			Name:       test.Name,
+			Path:       fmt.Sprintf("%s:%s", test.FileName, test.Location),
+		}, test.Attempt())
+	}
+	return nil
 }
 
+func (r Custom) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	return pathsFromTestCases(testCases)
+}
+
+// CommandNameAndArgs replaces the "{{testExamples}}" placeholder in the test command with the paths
+// of the tests.
+//
+// On retry, the failed tests can also be selected individually with the placeholders of the retry command:
+// "{{testNamePattern}}" is replaced with a regular expression matching the scope and name of the failed
+// tests exactly, and a word with "{{testNames}}" or "{{testIds}}" is repeated for the name or the
+// Test Engine id of each failed test, e.g. "--filter={{testNames}}" becomes "--filter=a --filter=b".
 func (r Custom) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
 	cmd := r.TestCommand
 	if retry {
 		cmd = r.RetryTestCommand
 	}
 
-	testPaths := pathsFromTestCases(testCases)
+	testPaths := r.testListPaths(testCases, retry)
 
 	cmd = strings.Replace(cmd, "{{testExamples}}", strings.Join(testPaths, " "), 1)
 
@@ -141,5 +190,58 @@ func (r Custom) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (strin
 		return "", []string{}, err
 	}
 
+	if retry {
+		words, err = customRetryFilters(words, testCases)
+		if err != nil {
+			return "", []string{}, err
+		}
+	}
+
 	return words[0], words[1:], nil
 }
+
+// customRetryFilters replaces the placeholders selecting the failed tests individually in the words
+// of the retry command.
+func customRetryFilters(words []string, testCases []plan.TestCase) ([]string, error) {
+	var names, ids, patterns []string
+	for _, tc := range testCases {
+		names = append(names, tc.Name)
+		ids = append(ids, tc.Identifier)
+
+		fullName := tc.Name
+		if tc.Scope != "" {
+			fullName = fmt.Sprintf("%s %s", tc.Scope, tc.Name)
+		}
+		patterns = append(patterns, regexp.QuoteMeta(fullName))
+	}
+
+	for _, values := range [][]string{names, ids, patterns} {
+		slices.Sort(values)
+	}
+	names = slices.Compact(names)
+	ids = slices.Compact(ids)
+	patterns = slices.Compact(patterns)
+
+	pattern := fmt.Sprintf("^(%s)$", strings.Join(patterns, "|"))
+
+	var replaced []string
+	for _, word := range words {
+		switch {
+		case strings.Contains(word, "{{testNames}}"):
+			for _, name := range names {
+				replaced = append(replaced, strings.ReplaceAll(word, "{{testNames}}", name))
+			}
+		case strings.Contains(word, "{{testIds}}"):
+			if slices.Contains(ids, "") {
+				return nil, errors.New("failed tests can't be retried with {{testIds}}, the test results don't include their ids")
+			}
+			for _, id := range ids {
+				replaced = append(replaced, strings.ReplaceAll(word, "{{testIds}}", id))
+			}
+		default:
+			replaced = append(replaced, strings.ReplaceAll(word, "{{testNamePattern}}", pattern))
+		}
+	}
+
+	return replaced, nil
+}
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
@@ -153,6 +154,79 @@ func TestCustom_CommandNameAndArgs(t *testing.T) {
 	}
 }
 
+func TestCustom_CommandNameAndArgs_Retry(t *testing.T) {
+	testCases := []plan.TestCase{
+		{Identifier: "b2", Scope: "math", Name: "divides (by zero)", Path: "tests/math.bats"},
+		{Identifier: "a1", Scope: "math", Name: "adds", Path: "tests/math.bats"},
+		{Identifier: "c3", Name: "concatenates", Path: "tests/strings.bats"},
+	}
+
+	commands := []struct {
+		command  string
+		wantArgs []string
+	}{
+		{
+			command:  "bin/test --filter '{{testNamePattern}}'",
+			wantArgs: []string{"--filter", `^(concatenates|math adds|math divides \(by zero\))$`},
+		},
+		{
+			command:  "bin/test --filter={{testNames}} --verbose",
+			wantArgs: []string{"--filter=adds", "--filter=concatenates", "--filter=divides (by zero)", "--verbose"},
+		},
+		{
+			command:  "bin/test {{testIds}}",
+			wantArgs: []string{"a1", "b2", "c3"},
+		},
+		{
+			command:  "bin/test {{testExamples}}",
+			wantArgs: []string{"tests/math.bats", "tests/math.bats", "tests/strings.bats"},
+		},
+	}
+
+	for _, tc := range commands {
+		custom, err := NewCustom(RunnerConfig{
+			TestCommand:      "bin/test {{testExamples}}",
+			RetryTestCommand: tc.command,
+			TestFilePattern:  "tests/**/*.bats",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create Custom runner: %v", err)
+		}
+
+		gotName, gotArgs, err := custom.CommandNameAndArgs(testCases, true)
+		if err != nil {
+			t.Errorf("Custom.CommandNameAndArgs(%q, testCases, true) error = %v", tc.command, err)
+		}
+
+		if gotName != "bin/test" {
+			t.Errorf("Custom.CommandNameAndArgs(%q, testCases, true) name = %v, want %v", tc.command, gotName, "bin/test")
+		}
+
+		if diff := cmp.Diff(gotArgs, tc.wantArgs); diff != "" {
+			t.Errorf("Custom.CommandNameAndArgs(%q, testCases, true) args diff (-got +want):\n%s", tc.command, diff)
+		}
+	}
+}
+
+func TestCustom_CommandNameAndArgs_RetryTestIdsWithoutIds(t *testing.T) {
+	custom, err := NewCustom(RunnerConfig{
+		TestCommand:      "bin/test {{testExamples}}",
+		RetryTestCommand: "bin/test --id {{testIds}}",
+		TestFilePattern:  "tests/**/*.bats",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Custom runner: %v", err)
+	}
+
+	testCases := []plan.TestCase{{Scope: "math.bats", Name: "adds", Path: "math.bats"}}
+	_, _, err = custom.CommandNameAndArgs(testCases, true)
+
+	want := "failed tests can't be retried with {{testIds}}, the test results don't include their ids"
+	if err == nil || err.Error() != want {
+		t.Errorf("Custom.CommandNameAndArgs() error = %v, want %q", err, want)
+	}
+}
+
 func TestCustom_Run(t *testing.T) {
 	changeCwd(t, "./testdata/custom")
 	custom, err := NewCustom(RunnerConfig{
@@ -238,10 +312,13 @@ func TestCustom_Run_TestFailedWithXMLResult(t *testing.T) {
 
 func TestCustom_Run_TestFailedWithJSONResult(t *testing.T) {
 	changeCwd(t, "./testdata/custom")
+	resultPath := filepath.Join(t.TempDir(), "test-result.json")
+
+	// Result files from a previous run are removed, so the command writes the report of the tests itself.
 	custom, err := NewCustom(RunnerConfig{
-		TestCommand:     "bats {{testExamples}}",
+		TestCommand:     fmt.Sprintf(`sh -c 'cp test-result.json "$0" && bats "$@"' %s {{testExamples}}`, resultPath),
 		TestFilePattern: "tests/**/*.bats",
-		ResultPath:      "./test-result.json",
+		ResultPath:      resultPath,
 	})
 
 	if err != nil {
@@ -267,6 +344,48 @@ func TestCustom_Run_TestFailedWithJSONResult(t *testing.T) {
 	}
 }
 
+func TestCustom_Run_ResultGlob(t *testing.T) {
+	changeCwd(t, "./testdata/custom")
+	resultDir := t.TempDir()
+
+	// A report from a previous attempt, which must not be read again.
+	stale := filepath.Join(resultDir, "report-stale.xml")
+	if err := os.WriteFile(stale, []byte(`<testsuite><testcase classname="stale.bats" name="stale"/></testsuite>`), 0644); err != nil {
+		t.Fatalf("os.WriteFile(%q) error = %v", stale, err)
+	}
+
+	custom, err := NewCustom(RunnerConfig{
+		TestCommand:     fmt.Sprintf(`sh -c 'cp results/*.xml "$0"; exit 1' %s {{testExamples}}`, resultDir),
+		TestFilePattern: "tests/**/*.bats",
+		ResultPath:      filepath.Join(resultDir, "report-*.xml"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Custom runner: %v", err)
+	}
+
+	testCases := []plan.TestCase{{Path: "tests/math.bats"}, {Path: "tests/strings.bats"}}
+	result := NewRunResult([]plan.TestCase{})
+	err = custom.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(%q) error = %v, want the stale report to be removed", stale, err)
+	}
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "math.bats", Name: "divides", Path: "math.bats"},
+	}
+	if diff := cmp.Diff(result.FailedTests(), want); diff != "" {
+		t.Errorf("Custom.Run() RunResult.FailedTests() diff (-got +want):\n%s", diff)
+	}
+
+	if stats := result.Statistics(); stats.Total != 3 {
+		t.Errorf("Custom.Run() RunResult.Statistics().Total = %d, want %d", stats.Total, 3)
+	}
+}
+
 func TestCustom_ResultFormat(t *testing.T) {
 	cases := []struct {
 		resultPath string
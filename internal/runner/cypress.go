@@ -1,7 +1,10 @@
 package runner
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"os"
 	"slices"
 	"strings"
 
@@ -42,22 +45,252 @@ func (c Cypress) SupportedFeatures() SupportedFeatures {
 		SplitByExample:  false,
 		FilterTestFiles: true,
 		FilterTestByTag: false,
-		AutoRetry:       false,
-		Mute:            false,
+		AutoRetry:       true,
+		Mute:            true,
 		Skip:            false,
 		SplitBySelector: true,
 	}
 }
 
+// Run executes the Cypress command for the given specs and records the results
+// from the reporter output at ResultPath.
+//
+// Cypress runs every spec in isolation, so its reporters write one file per spec.
+// ResultPath can therefore contain the mocha-junit-reporter "[hash]" token, or be a
+// glob, and every matching file is read after the run. Files ending in ".xml" are
+// read as JUnit XML, anything else is read as mochawesome JSON.
 func (c Cypress) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(c, testCases, retry)
+	cmd, cleanup, err := buildCommand(c, testCases, retry)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 
-	err = runAndForwardSignal(cmd)
+	// Reports from a previous attempt would otherwise be read again, and tests
+	// that are not part of this attempt would be counted twice.
+	if c.ResultPath != "" {
+		if err := removeResultFiles(c.resultFilesPattern()); err != nil {
+			return err
+		}
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// If the result path is not set, there is nothing to read.
+	if c.ResultPath == "" {
+		return cmdErr
+	}
+
+	// Cypress exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the report even if the command returns an error.
+	if parseErr := c.parseResults(result); parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read Cypress output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the report,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
 
-	return err
+	// Return any command error after processing the report
+	return cmdErr
+}
+
+// IsTestFailureExitCode reports whether the exit code can be caused by test failures.
+// Cypress exits with the number of failed tests, rather than 1.
+// Ref: https://docs.cypress.io/app/references/command-line#Exit-code
+func (c Cypress) IsTestFailureExitCode(code int) bool {
+	return code >= 1
+}
+
+// resultFilesPattern returns the glob pattern matching the reporter output files.
+// mocha-junit-reporter replaces "[hash]" in its mochaFile option with a hash of the file content.
+func (c Cypress) resultFilesPattern() string {
+	return strings.ReplaceAll(c.ResultPath, "[hash]", "*")
+}
+
+func (c Cypress) parseResults(result *RunResult) error {
+	files, err := ResultFiles(c.resultFilesPattern())
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no result files found matching %q", c.ResultPath)
+	}
+
+	for _, file := range files {
+		var testResults []TestResult
+		if strings.HasSuffix(file, ".xml") {
+			testResults, err = parseCypressJUnitResult(file)
+		} else {
+			testResults, err = parseCypressMochawesomeResult(file)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, testResult := range testResults {
+			result.RecordTestAttempt(testResult.TestCase, testResult.LastAttempt())
+		}
+	}
+
+	return nil
+}
+
+// parseCypressJUnitResult reads a mocha-junit-reporter file written by Cypress.
+// The reporter sets the testcase classname to the test title and the name to the
+// full title, and only the root suite, which has no testcases, carries the spec file.
+func parseCypressJUnitResult(path string) ([]TestResult, error) {
+	tests, err := loadAndParseJUnitXML(path)
+	if err != nil {
+		return nil, err
+	}
+
+	specFile, err := cypressJUnitSpecFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	testResults := make([]TestResult, 0, len(tests))
+	for _, test := range tests {
+		file := test.File
+		if file == "" {
+			file = specFile
+		}
+
+		testResults = append(testResults, TestResult{
+			TestCase: mapCypressTestToTestCase(file, test.Classname, test.Name),
+			Status:   test.Result,
+			Attempts: []TestAttempt{test.Attempt()},
+		})
+	}
+
+	return testResults, nil
+}
+
+// cypressJUnitSpecFile returns the first file attribute of the <testsuite> elements in the report.
+func cypressJUnitSpecFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read JUnit XML file %s: %w", path, err)
+	}
+
+	var testSuites junitXMLTestSuites
+	if err := xml.Unmarshal(data, &testSuites); err != nil {
+		return "", fmt.Errorf("failed to unmarshal JUnit XML file %s: %w", path, err)
+	}
+
+	for _, suite := range testSuites.TestSuites {
+		if suite.File != "" {
+			return suite.File, nil
+		}
+	}
+	return "", nil
+}
+
+type cypressMochawesomeTest struct {
+	Title     string `json:"title"`
+	FullTitle string `json:"fullTitle"`
+	State     string `json:"state"`
+	Pending   bool   `json:"pending"`
+	Skipped   bool   `json:"skipped"`
+	// Duration is the duration of the test in milliseconds.
+	Duration float64 `json:"duration"`
+	Err      struct {
+		Message string `json:"message"`
+		// EStack is the error message followed by the stack trace.
+		EStack string `json:"estack"`
+	} `json:"err"`
+}
+
+// attempt returns the result of the test with the given status, including its duration and failure.
+func (t cypressMochawesomeTest) attempt(status TestStatus) TestAttempt {
+	attempt := TestAttempt{
+		Status:   status,
+		Duration: millisecondsToDuration(t.Duration),
+	}
+
+	output := t.Err.EStack
+	if output == "" {
+		output = t.Err.Message
+	}
+	attempt.Failure = parseTestFailure("", output)
+	return attempt
+}
+
+type cypressMochawesomeSuite struct {
+	Title  string                    `json:"title"`
+	File   string                    `json:"file"`
+	Tests  []cypressMochawesomeTest  `json:"tests"`
+	Suites []cypressMochawesomeSuite `json:"suites"`
+}
+
+type cypressMochawesomeReport struct {
+	Results []cypressMochawesomeSuite `json:"results"`
+}
+
+// parseCypressMochawesomeResult reads a mochawesome JSON report.
+// Each entry in "results" is the root suite of a spec file.
+func parseCypressMochawesomeResult(path string) ([]TestResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mochawesome output: %v", err)
+	}
+
+	var report cypressMochawesomeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse mochawesome output: %s", err)
+	}
+
+	var testResults []TestResult
+	for _, suite := range report.Results {
+		testResults = append(testResults, getCypressTestResultsFromSuite(suite, suite.File)...)
+	}
+
+	return testResults, nil
+}
+
+func getCypressTestResultsFromSuite(suite cypressMochawesomeSuite, file string) []TestResult {
+	var testResults []TestResult
+
+	for _, test := range suite.Tests {
+		var status TestStatus
+		switch {
+		case test.State == "failed":
+			status = TestStatusFailed
+		case test.State == "passed":
+			status = TestStatusPassed
+		case test.Pending || test.Skipped:
+			status = TestStatusSkipped
+		default:
+			status = TestStatusUnknown
+		}
+
+		testResults = append(testResults, TestResult{
+			TestCase: mapCypressTestToTestCase(file, test.Title, test.FullTitle),
+			Status:   status,
+			Attempts: []TestAttempt{test.attempt(status)},
+		})
+	}
+
+	for _, subSuite := range suite.Suites {
+		testResults = append(testResults, getCypressTestResultsFromSuite(subSuite, file)...)
+	}
+
+	return testResults
+}
+
+// mapCypressTestToTestCase converts a Cypress test to a plan.TestCase.
+// The scope and name has to match with the scope generated by Buildkite test collector,
+// where the scope is the titles of the enclosing describe blocks.
+// Reporters only give the full title, so the test title is removed from it to get the scope.
+// The path is the spec file, as that is the smallest unit Cypress can run.
+func mapCypressTestToTestCase(file, title, fullTitle string) plan.TestCase {
+	return plan.TestCase{
+		Format: plan.TestCaseFormatExample,
+		Scope:  strings.TrimSuffix(strings.TrimSuffix(fullTitle, title), " "),
+		Name:   title,
+		Path:   file,
+	}
 }
 
 func (c Cypress) DiscoverTestTargets() ([]string, error) {
@@ -76,6 +309,19 @@ func (c Cypress) DiscoverTestTargets() ([]string, error) {
 	return files, nil
 }
 
+func (c Cypress) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	// Failed tests from the same spec share a path, and the spec only needs to run once.
+	testPaths := []string{}
+	pathsSeen := map[string]bool{}
+	for _, path := range pathsFromTestCases(testCases) {
+		if !pathsSeen[path] {
+			testPaths = append(testPaths, path)
+			pathsSeen[path] = true
+		}
+	}
+	return testPaths
+}
+
 func (c Cypress) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
 	cmd := c.TestCommand
 	if retry {
@@ -87,13 +333,18 @@ func (c Cypress) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (stri
 	}
 	idx := slices.Index(words, "{{testExamples}}")
 
-	testPaths := pathsFromTestCases(testCases)
+	testPaths := c.testListPaths(testCases, retry)
 
 	specs := strings.Join(testPaths, ",")
-	if idx < 0 {
-		words = append(words, "--spec", specs)
-	} else {
+	if idx >= 0 {
 		words[idx] = specs
+	} else if !hasTestListFile(words) {
+		words = append(words, "--spec", specs)
+	}
+
+	// The result path is usually part of a reporter option, e.g. "mochaFile={{resultPath}}".
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, "{{resultPath}}", c.ResultPath)
 	}
 
 	return words[0], words[1:], nil
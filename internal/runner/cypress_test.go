@@ -2,7 +2,10 @@ package runner
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"syscall"
 	"testing"
 
@@ -73,6 +76,107 @@ func TestCypressRun_CommandFailed(t *testing.T) {
 	assert.ErrorAs(t, err, &exitError)
 }
 
+func TestCypressRun_WithJUnitResult(t *testing.T) {
+	tmpDir := t.TempDir()
+	resultPath := filepath.Join(tmpDir, "results-[hash].xml")
+
+	// A result file left over from a previous attempt must not be read again.
+	stalePath := filepath.Join(tmpDir, "results-stale.xml")
+	if err := os.WriteFile(stalePath, []byte("<testsuites></testsuites>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cypress := NewCypress(RunnerConfig{
+		TestCommand: fmt.Sprintf("sh -c 'cp testdata/cypress/results/junit.xml %s; exit 1' {{testExamples}}", filepath.Join(tmpDir, "results-abc123.xml")),
+		ResultPath:  resultPath,
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "cypress/e2e/passing_spec.cy.js"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := cypress.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if _, err := os.Stat(stalePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Cypress.Run(%q) stale result file still exists, stat error = %v", testCases, err)
+	}
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Cypress.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "Passing spec", Name: "says hello", Path: "cypress/e2e/passing_spec.cy.js"},
+	}
+	if diff := cmp.Diff(result.FailedTests(), want); diff != "" {
+		t.Errorf("Cypress.Run(%q) RunResult.FailedTests() diff (-got +want):\n%s", testCases, diff)
+	}
+
+	wantStatistics := RunStatistics{Total: 3, PassedOnFirstRun: 1, Failed: 1, Skipped: 1}
+	if diff := cmp.Diff(result.Statistics(), wantStatistics); diff != "" {
+		t.Errorf("Cypress.Run(%q) RunResult.Statistics() diff (-got +want):\n%s", testCases, diff)
+	}
+}
+
+func TestCypressRun_WithMochawesomeResult(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cypress := NewCypress(RunnerConfig{
+		TestCommand: fmt.Sprintf("sh -c 'cp testdata/cypress/results/mochawesome.json %s' {{testExamples}}", filepath.Join(tmpDir, "mochawesome.json")),
+		ResultPath:  filepath.Join(tmpDir, "*.json"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "cypress/e2e/failing_spec.cy.js"},
+	}
+	result := NewRunResult([]plan.TestCase{{Scope: "Failing spec", Name: "fails"}})
+	err := cypress.Run(result, testCases, false)
+
+	if err != nil {
+		t.Errorf("Cypress.Run(%q) error = %v", testCases, err)
+	}
+
+	if result.Status() != RunStatusPassed {
+		t.Errorf("Cypress.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusPassed)
+	}
+
+	wantStatistics := RunStatistics{Total: 3, PassedOnFirstRun: 1, MutedFailed: 1, Skipped: 1}
+	if diff := cmp.Diff(result.Statistics(), wantStatistics); diff != "" {
+		t.Errorf("Cypress.Run(%q) RunResult.Statistics() diff (-got +want):\n%s", testCases, diff)
+	}
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "Failing spec", Name: "fails", Path: "cypress/e2e/failing_spec.cy.js"},
+	}
+	if diff := cmp.Diff(result.FailedMutedTests(), want); diff != "" {
+		t.Errorf("Cypress.Run(%q) RunResult.FailedMutedTests() diff (-got +want):\n%s", testCases, diff)
+	}
+}
+
+func TestCypressRun_ResultNotFound(t *testing.T) {
+	cypress := NewCypress(RunnerConfig{
+		TestCommand: "true",
+		ResultPath:  filepath.Join(t.TempDir(), "results-[hash].xml"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "cypress/e2e/passing_spec.cy.js"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := cypress.Run(result, testCases, false)
+
+	if err != nil {
+		t.Errorf("Cypress.Run(%q) error = %v", testCases, err)
+	}
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("Cypress.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
 func TestCypressRun_SignaledError(t *testing.T) {
 	cypress := NewCypress(RunnerConfig{
 		TestCommand: "./testdata/segv.sh",
@@ -188,3 +292,55 @@ func TestCypressCommandNameAndArgs_InvalidTestCommand(t *testing.T) {
 		t.Errorf("commandNameAndArgs() error = %v, want %v", err, shellquote.UnterminatedSingleQuoteError)
 	}
 }
+
+func TestCypressCommandNameAndArgs_Retry(t *testing.T) {
+	testCases := []plan.TestCase{
+		{Scope: "Passing spec", Name: "has a title", Path: "cypress/e2e/passing_spec.cy.js"},
+		{Scope: "Flaky spec", Name: "is 50% flaky", Path: "cypress/e2e/flaky_spec.cy.js"},
+		{Scope: "Passing spec", Name: "says hello", Path: "cypress/e2e/passing_spec.cy.js"},
+	}
+	testCommand := "cypress run --spec {{testExamples}} --reporter junit --reporter-options mochaFile={{resultPath}}"
+
+	cy := NewCypress(RunnerConfig{
+		TestCommand: testCommand,
+		ResultPath:  "results/cypress-[hash].xml",
+	})
+
+	gotName, gotArgs, err := cy.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Errorf("commandNameAndArgs(%q, %q) error = %v", testCases, testCommand, err)
+	}
+
+	wantName := "cypress"
+	wantArgs := []string{
+		"run",
+		"--spec", "cypress/e2e/passing_spec.cy.js,cypress/e2e/flaky_spec.cy.js",
+		"--reporter", "junit",
+		"--reporter-options", "mochaFile=results/cypress-[hash].xml",
+	}
+
+	if diff := cmp.Diff(gotName, wantName); diff != "" {
+		t.Errorf("commandNameAndArgs(%q, %q) diff (-got +want):\n%s", testCases, testCommand, diff)
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("commandNameAndArgs(%q, %q) diff (-got +want):\n%s", testCases, testCommand, diff)
+	}
+}
+
+func TestCypressIsTestFailureExitCode(t *testing.T) {
+	cypress := NewCypress(RunnerConfig{})
+
+	cases := []struct {
+		code int
+		want bool
+	}{
+		{0, false},
+		{1, true},
+		{3, true},
+	}
+	for _, tc := range cases {
+		if got := cypress.IsTestFailureExitCode(tc.code); got != tc.want {
+			t.Errorf("Cypress.IsTestFailureExitCode(%d) = %v, want %v", tc.code, got, tc.want)
+		}
+	}
+}
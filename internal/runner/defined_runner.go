@@ -0,0 +1,370 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// DefinedRunner is a test runner described by a RunnerDefinition of a runner definitions file.
+type DefinedRunner struct {
+	RunnerConfig
+	definition RunnerDefinition
+}
+
+// NewDefinedRunner returns the runner of the definition. The test command, retry command,
+// result path and test file patterns of the config take precedence over the definition.
+func NewDefinedRunner(c RunnerConfig, definition RunnerDefinition) DefinedRunner {
+	if c.TestCommand == "" {
+		c.TestCommand = definition.TestCommand
+	}
+
+	if c.RetryTestCommand == "" {
+		c.RetryTestCommand = definition.RetryCommand
+	}
+
+	if c.RetryTestCommand == "" {
+		c.RetryTestCommand = c.TestCommand
+	}
+
+	if c.ResultPath == "" {
+		c.ResultPath = definition.ResultPath
+	}
+
+	if c.TestFilePattern == "" {
+		c.TestFilePattern = definition.Discover.Pattern
+	}
+
+	if c.TestFileExcludePattern == "" {
+		c.TestFileExcludePattern = definition.Discover.ExcludePattern
+	}
+
+	return DefinedRunner{
+		RunnerConfig: c,
+		definition:   definition,
+	}
+}
+
+func (r DefinedRunner) Name() string {
+	if r.definition.DisplayName != "" {
+		return r.definition.DisplayName
+	}
+	return r.definition.Name
+}
+
+func (r DefinedRunner) SupportedFeatures() SupportedFeatures {
+	features := r.definition.Features
+	return SupportedFeatures{
+		SplitByFile:     featureEnabled(features.SplitByFile, true),
+		SplitByExample:  false,
+		FilterTestFiles: featureEnabled(features.FilterTestFiles, r.definition.Discover.Command == ""),
+		FilterTestByTag: false,
+		AutoRetry:       featureEnabled(features.AutoRetry, true),
+		Mute:            featureEnabled(features.Mute, true),
+		Skip:            false,
+		SplitBySelector: featureEnabled(features.SplitBySelector, true),
+	}
+}
+
+func featureEnabled(enabled *bool, defaultValue bool) bool {
+	if enabled == nil {
+		return defaultValue
+	}
+	return *enabled
+}
+
+// ResultFormat returns the format of the report for raw result uploads.
+// TAP and CTRF reports can't be uploaded to Test Engine.
+func (r DefinedRunner) ResultFormat() string {
+	switch r.definition.ResultFormat {
+	case ResultFormatJUnit:
+		return "junit"
+	case ResultFormatTestEngineJSON:
+		return "json"
+	default:
+		return ""
+	}
+}
+
+// DiscoverTestTargets returns the test files matching the test file pattern, or the lines printed by
+// the discovery command of the definition when there is no pattern.
+func (r DefinedRunner) DiscoverTestTargets() ([]string, error) {
+	if r.TestFilePattern == "" && r.definition.Discover.Command != "" {
+		return r.discoverWithCommand()
+	}
+
+	if r.TestFilePattern == "" {
+		return nil, fmt.Errorf("test file pattern must be provided for runner %q", r.definition.Name)
+	}
+
+	debug.Println("Discovering test files with include pattern:", r.TestFilePattern, "exclude pattern:", r.TestFileExcludePattern)
+	files, err := discoverTestFiles(r.TestFilePattern, r.TestFileExcludePattern)
+	debug.Println("Discovered", len(files), "files")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found with pattern %q and exclude pattern %q", r.TestFilePattern, r.TestFileExcludePattern)
+	}
+
+	return files, nil
+}
+
+func (r DefinedRunner) discoverWithCommand() ([]string, error) {
+	words, err := shellquote.Split(r.definition.Discover.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(words) == 0 {
+		return nil, errors.New("discover command is empty")
+	}
+
+	debug.Println("Discovering test targets with command:", r.definition.Discover.Command)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(words[0], words[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run discover command %q: %w\n%s", r.definition.Discover.Command, err, stderr.String())
+	}
+
+	var targets []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			targets = append(targets, line)
+		}
+	}
+	debug.Println("Discovered", len(targets), "targets")
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no test targets printed by discover command %q", r.definition.Discover.Command)
+	}
+
+	return targets, nil
+}
+
+func (r DefinedRunner) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(r, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Without a result path, the results can't be read, so bubble up the error directly.
+	if r.ResultPath == "" {
+		return runAndForwardSignal(cmd)
+	}
+
+	if err := removeResultFiles(r.ResultPath); err != nil {
+		return err
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// Test runners usually exit with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the report even if the command returns an error.
+	if parseErr := r.parseResults(result); parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read %s output, tests will not be retried: %v\n", r.Name(), parseErr)
+		// We don't want to fail the build if we fail to parse the report,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	// Return any command error after processing the report
+	return cmdErr
+}
+
+func (r DefinedRunner) parseResults(result *RunResult) error {
+	files, err := ResultFiles(r.ResultPath)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no result files found matching %q", r.ResultPath)
+	}
+
+	for _, file := range files {
+		if err := r.parseResultFile(result, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r DefinedRunner) parseResultFile(result *RunResult, file string) error {
+	switch r.definition.ResultFormat {
+	case ResultFormatJUnit:
+		tests, err := loadAndParseJUnitXML(file)
+		if err != nil {
+			return err
+		}
+		for _, test := range tests {
+			result.RecordTestAttempt(plan.TestCase{
+				Format: plan.TestCaseFormatExample,
+				Scope:  test.Classname,
+				Name:   test.Name,
+				Path:   pathOrScope(test.File, test.Classname),
+			}, test.Attempt())
+		}
+	case ResultFormatTestEngineJSON:
+		tests, err := parseTestEngineTestResult(file)
+		if err != nil {
+			return err
+		}
+		for _, test := range tests {
+			path := test.FileName
+			if path == "" {
+				path = test.Location
+			}
+			path = pathOrScope(path, test.Scope)
+			result.RecordTestAttempt(plan.TestCase{
+				Identifier: test.ID,
+				Format:     plan.TestCaseFormatExample,
+				Scope:      test.Scope,
+				Name:       test.Name,
+				Path:       path,
+			}, test.Attempt())
+		}
+	case ResultFormatTAP:
+		tests, err := loadAndParseTAP(file)
+		if err != nil {
+			return err
+		}
+		for _, test := range tests {
+			result.RecordTestAttempt(plan.TestCase{
+				Format: plan.TestCaseFormatExample,
+				Name:   test.Description,
+			}, test.Attempt())
+		}
+	case ResultFormatCTRF:
+		tests, err := loadAndParseCTRF(file)
+		if err != nil {
+			return err
+		}
+		for _, test := range tests {
+			result.RecordTestAttempt(plan.TestCase{
+				Format: plan.TestCaseFormatExample,
+				Scope:  string(test.Suite),
+				Name:   test.Name,
+				Path:   pathOrScope(test.FilePath, string(test.Suite)),
+			}, test.Attempt())
+		}
+	default:
+		return fmt.Errorf("unsupported result format %q", r.definition.ResultFormat)
+	}
+
+	return nil
+}
+
+// pathOrScope returns the path of a test, or its scope when the report doesn't include the path,
+// as the Custom runner does with the JUnit classname, so that the failed test can still be retried.
+func pathOrScope(path, scope string) string {
+	if path == "" {
+		return scope
+	}
+	return path
+}
+
+// CommandNameAndArgs replaces the placeholders of the test command, or of the retry command on retry.
+// "{{testExamples}}" is replaced with the test files, which are the files of the failed tests on retry,
+// "{{testFilter}}" with the retry filter of the failed tests, and "{{resultPath}}" with the result path.
+func (r DefinedRunner) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := r.TestCommand
+	if retry {
+		cmd = r.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	targets := r.testListPaths(testCases, retry)
+	var filter string
+	if retry {
+		if r.definition.RetryFilter == nil && slices.ContainsFunc(testCases, func(tc plan.TestCase) bool { return tc.Path == "" }) {
+			return "", []string{}, fmt.Errorf("failed tests can't be retried without their paths, the report of runner %q doesn't include their paths nor scopes and no retry_filter is defined", r.definition.Name)
+		}
+		if r.definition.RetryFilter != nil {
+			filter = r.definition.RetryFilter.build(testCases)
+		}
+	}
+
+	var args []string
+	for _, word := range words {
+		switch word {
+		case "{{testExamples}}":
+			args = append(args, targets...)
+		case "{{testFilter}}":
+			args = append(args, filter)
+		default:
+			args = append(args, strings.ReplaceAll(word, "{{resultPath}}", r.ResultPath))
+		}
+	}
+
+	if len(args) == 0 {
+		return "", []string{}, errors.New("test command is empty")
+	}
+
+	return args[0], args[1:], nil
+}
+
+// testListPaths returns the test targets, which are the files of the failed tests on retry.
+func (r DefinedRunner) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	if retry {
+		return definedRunnerRetryPaths(testCases)
+	}
+	return pathsFromTestCases(testCases)
+}
+
+// definedRunnerRetryPaths returns the unique paths of the failed tests, in the order of the tests.
+func definedRunnerRetryPaths(testCases []plan.TestCase) []string {
+	var paths []string
+	for _, tc := range testCases {
+		if tc.Path != "" && !slices.Contains(paths, tc.Path) {
+			paths = append(paths, tc.Path)
+		}
+	}
+	return paths
+}
+
+// build returns the filter matching the failed tests.
+func (f RetryFilterDefinition) build(testCases []plan.TestCase) string {
+	escape := func(s string) string { return s }
+	if f.Escape == "regexp" {
+		escape = regexp.QuoteMeta
+	}
+
+	separator := f.Separator
+	if separator == "" {
+		separator = " "
+	}
+
+	var tests []string
+	for _, tc := range testCases {
+		test := strings.NewReplacer(
+			"{{scope}}", escape(tc.Scope),
+			"{{name}}", escape(tc.Name),
+			"{{path}}", escape(tc.Path),
+		).Replace(f.Test)
+		if !slices.Contains(tests, test) {
+			tests = append(tests, test)
+		}
+	}
+
+	return f.Prefix + strings.Join(tests, separator) + f.Suffix
+}
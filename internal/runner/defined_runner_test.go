@@ -0,0 +1,268 @@
+package runner
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+)
+
+func loadRunnerDefinition(t *testing.T, name string) RunnerDefinition {
+	t.Helper()
+	definitions, err := LoadRunnerDefinitions("./testdata/definitions/runners.yml")
+	if err != nil {
+		t.Fatalf("LoadRunnerDefinitions() error = %v", err)
+	}
+
+	definition, ok := definitions.Find(name)
+	if !ok {
+		t.Fatalf("Find(%q) ok = false, want true", name)
+	}
+	return definition
+}
+
+func TestNewDefinedRunner(t *testing.T) {
+	r := NewDefinedRunner(RunnerConfig{}, loadRunnerDefinition(t, "bats"))
+
+	if r.Name() != "Bats" {
+		t.Errorf("Name() = %q, want %q", r.Name(), "Bats")
+	}
+	if r.TestCommand != "bats --report-formatter junit --output tmp {{testExamples}}" {
+		t.Errorf("TestCommand = %q", r.TestCommand)
+	}
+	if r.RetryTestCommand != "bats --report-formatter junit --output tmp --filter {{testFilter}} {{testExamples}}" {
+		t.Errorf("RetryTestCommand = %q", r.RetryTestCommand)
+	}
+	if r.ResultPath != "tmp/report.xml" {
+		t.Errorf("ResultPath = %q, want %q", r.ResultPath, "tmp/report.xml")
+	}
+	if r.TestFilePattern != "tests/*.bats" {
+		t.Errorf("TestFilePattern = %q, want %q", r.TestFilePattern, "tests/*.bats")
+	}
+	if r.ResultFormat() != "junit" {
+		t.Errorf("ResultFormat() = %q, want %q", r.ResultFormat(), "junit")
+	}
+}
+
+func TestNewDefinedRunner_ConfigOverridesDefinition(t *testing.T) {
+	r := NewDefinedRunner(RunnerConfig{
+		TestCommand:     "bin/bats {{testExamples}}",
+		ResultPath:      "reports/bats.xml",
+		TestFilePattern: "test/**/*.bats",
+	}, loadRunnerDefinition(t, "prove"))
+
+	if r.Name() != "prove" {
+		t.Errorf("Name() = %q, want %q", r.Name(), "prove")
+	}
+	if r.TestCommand != "bin/bats {{testExamples}}" {
+		t.Errorf("TestCommand = %q, want %q", r.TestCommand, "bin/bats {{testExamples}}")
+	}
+	// The retry command defaults to the test command when neither the config nor the definition set it.
+	if r.RetryTestCommand != "bin/bats {{testExamples}}" {
+		t.Errorf("RetryTestCommand = %q, want %q", r.RetryTestCommand, "bin/bats {{testExamples}}")
+	}
+	if r.ResultPath != "reports/bats.xml" {
+		t.Errorf("ResultPath = %q, want %q", r.ResultPath, "reports/bats.xml")
+	}
+	if r.ResultFormat() != "" {
+		t.Errorf("ResultFormat() = %q, want empty", r.ResultFormat())
+	}
+}
+
+func TestDefinedRunner_SupportedFeatures(t *testing.T) {
+	bats := NewDefinedRunner(RunnerConfig{}, loadRunnerDefinition(t, "bats")).SupportedFeatures()
+	want := SupportedFeatures{
+		SplitByFile:     true,
+		FilterTestFiles: true,
+		AutoRetry:       true,
+		Mute:            true,
+		SplitBySelector: true,
+	}
+	if diff := cmp.Diff(bats, want); diff != "" {
+		t.Errorf("SupportedFeatures() diff (-got +want):\n%s", diff)
+	}
+
+	prove := NewDefinedRunner(RunnerConfig{}, loadRunnerDefinition(t, "prove")).SupportedFeatures()
+	want = SupportedFeatures{
+		SplitByFile:     true,
+		Mute:            true,
+		SplitBySelector: true,
+	}
+	if diff := cmp.Diff(prove, want); diff != "" {
+		t.Errorf("SupportedFeatures() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestDefinedRunner_DiscoverTestTargets(t *testing.T) {
+	bats := NewDefinedRunner(RunnerConfig{}, loadRunnerDefinition(t, "bats"))
+	prove := NewDefinedRunner(RunnerConfig{}, loadRunnerDefinition(t, "prove"))
+	changeCwd(t, "./testdata/definitions")
+
+	want := []string{"tests/math.bats", "tests/strings.bats"}
+	for _, r := range []DefinedRunner{bats, prove} {
+		got, err := r.DiscoverTestTargets()
+		if err != nil {
+			t.Errorf("%s.DiscoverTestTargets() error = %v", r.Name(), err)
+		}
+
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("%s.DiscoverTestTargets() diff (-got +want):\n%s", r.Name(), diff)
+		}
+	}
+}
+
+func TestDefinedRunner_DiscoverTestTargets_CommandFails(t *testing.T) {
+	r := NewDefinedRunner(RunnerConfig{}, RunnerDefinition{
+		Name:     "failing",
+		Discover: RunnerDefinitionDiscover{Command: "sh -c 'echo no tests >&2; exit 1'"},
+	})
+
+	_, err := r.DiscoverTestTargets()
+	if err == nil || !strings.Contains(err.Error(), "no tests") {
+		t.Errorf("DiscoverTestTargets() error = %v, want error containing the output of the command", err)
+	}
+}
+
+func TestDefinedRunner_CommandNameAndArgs(t *testing.T) {
+	r := NewDefinedRunner(RunnerConfig{}, loadRunnerDefinition(t, "bats"))
+	testCases := []plan.TestCase{{Path: "tests/math.bats"}, {Path: "tests/strings.bats"}}
+
+	gotName, gotArgs, err := r.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs() error = %v", err)
+	}
+
+	wantArgs := []string{"--report-formatter", "junit", "--output", "tmp", "tests/math.bats", "tests/strings.bats"}
+	if gotName != "bats" {
+		t.Errorf("CommandNameAndArgs() name = %q, want %q", gotName, "bats")
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs() args diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestDefinedRunner_CommandNameAndArgs_Retry(t *testing.T) {
+	r := NewDefinedRunner(RunnerConfig{}, loadRunnerDefinition(t, "bats"))
+	testCases := []plan.TestCase{
+		{Scope: "math", Name: "adds (1 + 1)", Path: "tests/math.bats"},
+		{Scope: "math", Name: "divides", Path: "tests/math.bats"},
+		{Scope: "strings", Name: "joins", Path: "tests/strings.bats"},
+	}
+
+	gotName, gotArgs, err := r.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs() error = %v", err)
+	}
+
+	wantArgs := []string{
+		"--report-formatter", "junit", "--output", "tmp",
+		"--filter", `^(adds \(1 \+ 1\)|divides|joins)$`,
+		"tests/math.bats", "tests/strings.bats",
+	}
+	if gotName != "bats" {
+		t.Errorf("CommandNameAndArgs() name = %q, want %q", gotName, "bats")
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs() args diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestDefinedRunner_CommandNameAndArgs_RetryWithoutPaths(t *testing.T) {
+	r := NewDefinedRunner(RunnerConfig{}, loadRunnerDefinition(t, "prove"))
+	testCases := []plan.TestCase{{Name: "joins strings"}}
+
+	_, _, err := r.CommandNameAndArgs(testCases, true)
+	if err == nil {
+		t.Errorf("CommandNameAndArgs() error = nil, want an error")
+	}
+}
+
+func TestDefinedRunner_Run(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "report.tap")
+	r := NewDefinedRunner(RunnerConfig{
+		TestCommand: "cp results/report.tap {{resultPath}}",
+		ResultPath:  resultPath,
+	}, loadRunnerDefinition(t, "prove"))
+	changeCwd(t, "./testdata/definitions")
+
+	result := NewRunResult([]plan.TestCase{})
+	if err := r.Run(result, []plan.TestCase{{Path: "tests/math.bats"}}, false); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Run() RunResult.Status = %v, want %v", result.Status(), RunStatusFailed)
+	}
+
+	var failed []string
+	for _, test := range result.FailedTests() {
+		failed = append(failed, test.Name)
+	}
+	slices.Sort(failed)
+	if diff := cmp.Diff(failed, []string{"divides by zero", "joins strings"}); diff != "" {
+		t.Errorf("Run() failed tests diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestDefinedRunner_Run_CTRF(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "report.json")
+	r := NewDefinedRunner(RunnerConfig{ResultPath: resultPath}, RunnerDefinition{
+		Name:         "hurl",
+		TestCommand:  "cp results/report.ctrf.json {{resultPath}}",
+		ResultFormat: ResultFormatCTRF,
+	})
+	changeCwd(t, "./testdata/definitions")
+
+	result := NewRunResult([]plan.TestCase{})
+	if err := r.Run(result, []plan.TestCase{{Path: "api/users.hurl"}}, false); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+
+	want := []plan.TestCase{{
+		Format: plan.TestCaseFormatExample,
+		Scope:  "api > users > create",
+		Name:   "creates the user",
+		Path:   "api/users.hurl",
+	}}
+	if diff := cmp.Diff(result.FailedTests(), want); diff != "" {
+		t.Errorf("Run() failed tests diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestDefinedRunner_Run_JUnitWithoutFile(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "report.xml")
+	r := NewDefinedRunner(RunnerConfig{ResultPath: resultPath}, RunnerDefinition{
+		Name:         "bats",
+		TestCommand:  "cp results/report.xml {{resultPath}}",
+		RetryCommand: "bats {{testExamples}}",
+		ResultFormat: ResultFormatJUnit,
+	})
+	changeCwd(t, "./testdata/definitions")
+
+	result := NewRunResult([]plan.TestCase{})
+	if err := r.Run(result, []plan.TestCase{{Path: "tests/math.bats"}}, false); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+
+	// The classname is used as the path of the failed test, so that it can be retried.
+	want := []plan.TestCase{{
+		Format: plan.TestCaseFormatExample,
+		Scope:  "tests/math.bats",
+		Name:   "divides by zero",
+		Path:   "tests/math.bats",
+	}}
+	if diff := cmp.Diff(result.FailedTests(), want); diff != "" {
+		t.Errorf("Run() failed tests diff (-got +want):\n%s", diff)
+	}
+
+	_, gotArgs, err := r.CommandNameAndArgs(result.FailedTests(), true)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs() error = %v", err)
+	}
+	if diff := cmp.Diff(gotArgs, []string{"tests/math.bats"}); diff != "" {
+		t.Errorf("CommandNameAndArgs() args diff (-got +want):\n%s", diff)
+	}
+}
@@ -2,10 +2,15 @@ package runner
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/buildkite/test-engine-client/v3/internal/config"
 )
 
+// builtInRunners are the names of the built-in test runners.
+// Update this list when adding a new runner.
+var builtInRunners = []string{"rspec", "jest", "vitest", "cypress", "playwright", "pytest", "gotest", "cucumber", "minitest", "mocha", "phpunit", "nextest", "gradle", "maven", "dotnet", "exunit", "plugin", "custom"}
+
 func DetectRunner(cfg *config.Config) (TestRunnerWithTargetDiscovery, error) {
 	runnerConfig := RunnerConfig{
 		TestRunner: cfg.TestRunner,
@@ -19,6 +24,8 @@ func DetectRunner(cfg *config.Config) (TestRunnerWithTargetDiscovery, error) {
 		TestFilePattern:        cfg.TestFilePattern,
 		uploadToken:            cfg.UploadToken,
 		SelectorListPath:       cfg.SelectorListPath,
+		GoTestBinaryDir:        cfg.GoTestBinaryDir,
+		PluginCommand:          cfg.RunnerPlugin,
 	}
 
 	switch testRunner := cfg.TestRunner; testRunner {
@@ -38,10 +45,49 @@ func DetectRunner(cfg *config.Config) (TestRunnerWithTargetDiscovery, error) {
 		return NewGoTest(runnerConfig), nil
 	case "cucumber":
 		return NewCucumber(runnerConfig), nil
+	case "minitest":
+		return NewMinitest(runnerConfig), nil
+	case "mocha":
+		return NewMocha(runnerConfig), nil
+	case "phpunit":
+		return NewPHPUnit(runnerConfig), nil
+	case "nextest":
+		return NewNextest(runnerConfig), nil
+	case "gradle":
+		return NewGradle(runnerConfig), nil
+	case "maven":
+		return NewMaven(runnerConfig), nil
+	case "dotnet":
+		return NewDotnet(runnerConfig), nil
+	case "exunit":
+		return NewExUnit(runnerConfig), nil
+	case "plugin":
+		return NewPlugin(runnerConfig)
 	case "custom":
 		return NewCustom(runnerConfig)
 	default:
-		// Update the error message to include the new runner
-		return nil, fmt.Errorf("runner value %q is invalid, possible values are 'rspec', 'jest', 'vitest', 'cypress', 'playwright', 'pytest', 'gotest', 'cucumber', or 'custom'", testRunner)
+		if cfg.RunnerDefinitionsFile != "" {
+			definitions, err := LoadRunnerDefinitions(cfg.RunnerDefinitionsFile)
+			if err != nil {
+				return nil, err
+			}
+			if definition, ok := definitions.Find(testRunner); ok {
+				return NewDefinedRunner(runnerConfig, definition), nil
+			}
+		}
+		return nil, fmt.Errorf("runner value %q is invalid, possible values are %s", testRunner, runnerNames(cfg.RunnerDefinitionsFile))
+	}
+}
+
+// runnerNames returns the names of the built-in runners quoted and joined for an error message,
+// including the runners of the runner definitions file when it's set.
+func runnerNames(definitionsFile string) string {
+	names := make([]string, len(builtInRunners))
+	for i, name := range builtInRunners {
+		names[i] = fmt.Sprintf("'%s'", name)
+	}
+	if definitionsFile != "" {
+		names = append(names, fmt.Sprintf("a runner defined in %s", definitionsFile))
 	}
+	return strings.Join(names[:len(names)-1], ", ") + ", or " + names[len(names)-1]
 }
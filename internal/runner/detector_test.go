@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/config"
+)
+
+func TestDetectRunner_RunnerDefinition(t *testing.T) {
+	cfg := config.Config{
+		TestRunner:            "bats",
+		RunnerDefinitionsFile: "./testdata/definitions/runners.yml",
+	}
+
+	testRunner, err := DetectRunner(&cfg)
+	if err != nil {
+		t.Fatalf("DetectRunner() error = %v", err)
+	}
+
+	if testRunner.Name() != "Bats" {
+		t.Errorf("DetectRunner() Name() = %q, want %q", testRunner.Name(), "Bats")
+	}
+}
+
+func TestDetectRunner_InvalidRunner(t *testing.T) {
+	cfg := config.Config{TestRunner: "bats"}
+
+	_, err := DetectRunner(&cfg)
+
+	want := `runner value "bats" is invalid, possible values are 'rspec', 'jest', 'vitest', 'cypress', 'playwright', 'pytest', 'gotest', 'cucumber', 'minitest', 'mocha', 'phpunit', 'nextest', 'gradle', 'maven', 'dotnet', 'exunit', 'plugin', or 'custom'`
+	if err == nil || err.Error() != want {
+		t.Errorf("DetectRunner() error = %v, want %q", err, want)
+	}
+}
+
+func TestDetectRunner_InvalidRunnerWithRunnerDefinitions(t *testing.T) {
+	cfg := config.Config{
+		TestRunner:            "hurl",
+		RunnerDefinitionsFile: "./testdata/definitions/runners.yml",
+	}
+
+	_, err := DetectRunner(&cfg)
+
+	want := `runner value "hurl" is invalid, possible values are 'rspec', 'jest', 'vitest', 'cypress', 'playwright', 'pytest', 'gotest', 'cucumber', 'minitest', 'mocha', 'phpunit', 'nextest', 'gradle', 'maven', 'dotnet', 'exunit', 'plugin', 'custom', or a runner defined in ./testdata/definitions/runners.yml`
+	if err == nil || err.Error() != want {
+		t.Errorf("DetectRunner() error = %v, want %q", err, want)
+	}
+}
@@ -87,6 +87,9 @@ func TestDiscoverTestFiles_ExcludeNodeModules(t *testing.T) {
 		"testdata/jest/skipped.spec.js",
 		"testdata/jest/slow.spec.js",
 		"testdata/jest/spells/expelliarmus.spec.js",
+		"testdata/mocha/test/hook.spec.js",
+		"testdata/mocha/test/models/spell.spec.js",
+		"testdata/mocha/test/models/user.spec.js",
 		"testdata/playwright/playwright.config.js",
 		"testdata/playwright/tests/error.spec.js",
 		"testdata/playwright/tests/example.spec.js",
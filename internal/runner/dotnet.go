@@ -0,0 +1,241 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// Dotnet is a runner for .NET tests run with `dotnet test`, such as xUnit, NUnit and MSTest tests.
+//
+// Tests are split by test file, and each file is run as the test class named after it with a
+// --filter expression. Results are read from the TRX report written by the trx logger.
+type Dotnet struct {
+	RunnerConfig
+}
+
+func NewDotnet(d RunnerConfig) Dotnet {
+	if d.TestCommand == "" {
+		d.TestCommand = `dotnet test --filter {{testFilter}} --logger "trx;LogFileName={{resultPath}}"`
+	}
+
+	if d.TestFilePattern == "" {
+		d.TestFilePattern = "**/*Tests.cs"
+	}
+
+	if d.RetryTestCommand == "" {
+		d.RetryTestCommand = d.TestCommand
+	}
+
+	return Dotnet{
+		RunnerConfig: d,
+	}
+}
+
+func (d Dotnet) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     true,
+		SplitByExample:  false,
+		FilterTestFiles: true,
+		FilterTestByTag: false,
+		AutoRetry:       true,
+		Mute:            true,
+		Skip:            false,
+		SplitBySelector: true,
+	}
+}
+
+func (d Dotnet) Name() string {
+	return ".NET"
+}
+
+// DiscoverTestTargets returns file names using the discovery pattern.
+func (d Dotnet) DiscoverTestTargets() ([]string, error) {
+	debug.Println("Discovering test files with include pattern:", d.TestFilePattern, "exclude pattern:", d.TestFileExcludePattern)
+	files, err := discoverTestFiles(d.TestFilePattern, d.TestFileExcludePattern)
+	debug.Println("Discovered", len(files), "files")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found with pattern %q and exclude pattern %q", d.TestFilePattern, d.TestFileExcludePattern)
+	}
+
+	return files, nil
+}
+
+func (d Dotnet) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(d, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := removeResultFiles(d.ResultPath); err != nil {
+		return err
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// dotnet test exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the report even if the command returns an error.
+	if parseErr := d.parseResults(result, testCases); parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read .NET output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the report,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	// Return any command error after processing the report
+	return cmdErr
+}
+
+// parseResults records the results of the TRX reports matching ResultPath. The scope of a test is
+// its class and the name is its display name without the class. Reports don't include the test file,
+// so it is looked up from the test cases of the run by the class name.
+func (d Dotnet) parseResults(result *RunResult, testCases []plan.TestCase) error {
+	files, err := ResultFiles(d.ResultPath)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no result files found matching %q", d.ResultPath)
+	}
+
+	paths := dotnetTestClassPaths(testCases)
+	for _, file := range files {
+		tests, err := loadAndParseTRX(file)
+		if err != nil {
+			return err
+		}
+
+		for _, test := range tests {
+			result.RecordTestAttempt(plan.TestCase{
+				Scope: test.ClassName,
+				Name:  strings.TrimPrefix(test.TestName, test.ClassName+"."),
+				Path:  paths[dotnetClassName(test.ClassName)],
+			}, test.Attempt())
+		}
+	}
+
+	return nil
+}
+
+// CommandNameAndArgs replaces the "{{testFilter}}" and "{{resultPath}}" placeholders in the test command.
+//
+// "{{testFilter}}" is replaced with a --filter expression selecting the test class of each test file,
+// which is named after the file, e.g. "FullyQualifiedName~.UserTests.|FullyQualifiedName~.UserTests+"
+// for UserTests.cs and its nested classes. On retry, the expression selects the failed test methods,
+// e.g. "FullyQualifiedName=Acme.Tests.UserTests.SavesTheUser". When the command has no "{{testFilter}}"
+// placeholder, the expression is appended with --filter.
+//
+// "{{resultPath}}" is replaced with the absolute result path, as the trx logger writes relative paths
+// to the results directory of each test project.
+func (d Dotnet) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := d.TestCommand
+	if retry {
+		cmd = d.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	filter := dotnetTestFilter(testCases)
+	idx := slices.Index(words, "{{testFilter}}")
+	if idx < 0 {
+		words = append(words, "--filter", filter)
+	} else {
+		words[idx] = filter
+	}
+
+	resultPath, err := filepath.Abs(d.ResultPath)
+	if err != nil {
+		return "", []string{}, err
+	}
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, "{{resultPath}}", resultPath)
+	}
+
+	return words[0], words[1:], nil
+}
+
+// dotnetTestFilter returns a --filter expression selecting the test cases.
+// Test cases of a test file select its test class, and test cases from a previous attempt
+// select their test method.
+func dotnetTestFilter(testCases []plan.TestCase) string {
+	var conditions []string
+	for i, path := range pathsFromTestCases(testCases) {
+		var testConditions []string
+		if testCase := testCases[i]; testCase.Name != "" {
+			testConditions = []string{"FullyQualifiedName=" + dotnetFilterEscape(testCase.Scope+"."+dotnetTestMethod(testCase.Name))}
+		} else {
+			// The namespace of the class can't be derived from the path, so the class is matched
+			// as a segment of the fully qualified name of its tests.
+			class := dotnetFilterEscape(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+			testConditions = []string{"FullyQualifiedName~." + class + ".", "FullyQualifiedName~." + class + "+"}
+		}
+
+		for _, condition := range testConditions {
+			if !slices.Contains(conditions, condition) {
+				conditions = append(conditions, condition)
+			}
+		}
+	}
+	return strings.Join(conditions, "|")
+}
+
+// dotnetFilterEscape escapes the characters with a special meaning in a --filter expression.
+func dotnetFilterEscape(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(`\()&|=!~`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// dotnetTestMethod returns the test method of a test name, without the arguments of a parameterized test,
+// e.g. "Rounds" for "Rounds(amount: 1.005)".
+func dotnetTestMethod(name string) string {
+	if i := strings.Index(name, "("); i > 0 {
+		name = name[:i]
+	}
+	return strings.TrimSpace(name)
+}
+
+// dotnetClassName returns the name of the top-level class of a fully qualified class name,
+// without its namespace and nested classes, e.g. "UserTests" for "Acme.Tests.UserTests+Validation".
+func dotnetClassName(class string) string {
+	class, _, _ = strings.Cut(class, "+")
+	return class[strings.LastIndex(class, ".")+1:]
+}
+
+// dotnetTestClassPaths returns the test file of each test class of the test cases.
+// Test cases of a test file are mapped by the class named after the file, and test cases
+// from a previous attempt by their scope.
+func dotnetTestClassPaths(testCases []plan.TestCase) map[string]string {
+	paths := map[string]string{}
+	for i, path := range pathsFromTestCases(testCases) {
+		class := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if testCases[i].Scope != "" {
+			class = dotnetClassName(testCases[i].Scope)
+		}
+
+		if _, ok := paths[class]; !ok {
+			paths[class] = path
+		}
+	}
+	return paths
+}
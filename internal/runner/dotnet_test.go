@@ -0,0 +1,233 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDotnetRun(t *testing.T) {
+	changeCwd(t, "./testdata/dotnet")
+
+	resultPath := filepath.Join(t.TempDir(), "results.trx")
+	// A stale report from a previous attempt is removed before the run.
+	if err := os.WriteFile(resultPath, []byte("not xml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dotnet := NewDotnet(RunnerConfig{
+		TestCommand: `sh -c 'cp results.trx "$0"; exit 1' {{resultPath}}`,
+		ResultPath:  resultPath,
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "Acme.Tests/UserTests.cs"},
+		{Path: "Acme.Tests/Billing/InvoiceTests.cs"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := dotnet.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Dotnet.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	want := []plan.TestCase{
+		{
+			Scope: "Acme.Tests.Billing.InvoiceTests",
+			Name:  `Rounds(amount: "1.005")`,
+			Path:  "Acme.Tests/Billing/InvoiceTests.cs",
+		},
+		{
+			Scope: "Acme.Tests.UserTests",
+			Name:  "ValidatesTheEmail",
+			Path:  "Acme.Tests/UserTests.cs",
+		},
+	}
+	// Sort the failed tests by scope and name when comparing
+	sorter := cmp.Transformer("Sort", func(in []plan.TestCase) []plan.TestCase {
+		out := append([]plan.TestCase(nil), in...) // Copy input to avoid mutating it
+		slices.SortFunc(out, func(a, b plan.TestCase) int {
+			return strings.Compare(a.Scope+"/"+a.Name, b.Scope+"/"+b.Name)
+		})
+		return out
+	})
+
+	if diff := cmp.Diff(want, result.FailedTests(), sorter); diff != "" {
+		t.Errorf("Dotnet.Run(%q) RunResult.FailedTests() diff (-want +got):\n%s", testCases, diff)
+	}
+
+	stats := result.Statistics()
+	if stats.Total != 6 || stats.PassedOnFirstRun != 3 || stats.Skipped != 1 {
+		t.Errorf("Dotnet.Run(%q) RunResult.Statistics() = %+v, want 6 tests with 3 passed and 1 skipped", testCases, stats)
+	}
+
+	// Tests of nested classes are recorded against the file of their top-level class.
+	if nested := result.tests["Acme.Tests.UserTests+Validation/RejectsBlankNames/Acme.Tests/UserTests.cs"]; nested == nil {
+		t.Errorf("Dotnet.Run(%q) didn't record RejectsBlankNames of UserTests.cs", testCases)
+	}
+
+	failed := result.tests["Acme.Tests.UserTests/ValidatesTheEmail/Acme.Tests/UserTests.cs"]
+	if failed == nil {
+		t.Fatalf("Dotnet.Run(%q) didn't record ValidatesTheEmail", testCases)
+	}
+	if failure := failed.Failure(); failure == nil || failure.Message != "Assert.True() Failure\nExpected: True\nActual:   False" {
+		t.Errorf("ValidatesTheEmail Failure() = %+v, want the Assert.True() failure", failure)
+	}
+}
+
+func TestDotnetRun_CommandFailed(t *testing.T) {
+	changeCwd(t, "./testdata/dotnet")
+
+	dotnet := NewDotnet(RunnerConfig{
+		TestCommand: "sh -c 'exit 1'",
+		ResultPath:  filepath.Join(t.TempDir(), "results.trx"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "Acme.Tests/UserTests.cs"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := dotnet.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("Dotnet.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
+func TestDotnetCommandNameAndArgs(t *testing.T) {
+	dotnet := NewDotnet(RunnerConfig{
+		ResultPath: "/tmp/bktec/results.trx",
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "Acme.Tests/UserTests.cs"},
+		{Path: "Acme.Tests/Billing/InvoiceTests.cs"},
+	}
+
+	gotName, gotArgs, err := dotnet.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("Dotnet.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "dotnet" {
+		t.Errorf("Dotnet.CommandNameAndArgs() name = %q, want %q", gotName, "dotnet")
+	}
+
+	wantArgs := []string{
+		"test",
+		"--filter", "FullyQualifiedName~.UserTests.|FullyQualifiedName~.UserTests+|FullyQualifiedName~.InvoiceTests.|FullyQualifiedName~.InvoiceTests+",
+		"--logger", "trx;LogFileName=/tmp/bktec/results.trx",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Dotnet.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDotnetCommandNameAndArgs_Retry(t *testing.T) {
+	dotnet := NewDotnet(RunnerConfig{
+		ResultPath: "/tmp/bktec/results.trx",
+	})
+
+	testCases := []plan.TestCase{
+		{
+			Scope: "Acme.Tests.UserTests",
+			Name:  "ValidatesTheEmail",
+			Path:  "Acme.Tests/UserTests.cs",
+		},
+		{
+			Scope: "Acme.Tests.Billing.InvoiceTests",
+			Name:  `Rounds(amount: "1.005")`,
+			Path:  "Acme.Tests/Billing/InvoiceTests.cs",
+		},
+		{
+			Scope: "Acme.Tests.Billing.InvoiceTests",
+			Name:  `Rounds(amount: "2.5")`,
+			Path:  "Acme.Tests/Billing/InvoiceTests.cs",
+		},
+	}
+
+	_, gotArgs, err := dotnet.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Fatalf("Dotnet.CommandNameAndArgs() error = %v", err)
+	}
+
+	wantArgs := []string{
+		"test",
+		"--filter", "FullyQualifiedName=Acme.Tests.UserTests.ValidatesTheEmail|FullyQualifiedName=Acme.Tests.Billing.InvoiceTests.Rounds",
+		"--logger", "trx;LogFileName=/tmp/bktec/results.trx",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Dotnet.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDotnetCommandNameAndArgs_RelativeResultPath(t *testing.T) {
+	dotnet := NewDotnet(RunnerConfig{
+		TestCommand: "dotnet test Acme.sln --logger trx;LogFileName={{resultPath}}",
+		ResultPath:  "tmp/results.trx",
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "Acme.Tests/UserTests.cs"},
+	}
+
+	_, gotArgs, err := dotnet.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("Dotnet.CommandNameAndArgs() error = %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The filter is appended when the command has no "{{testFilter}}" placeholder.
+	wantArgs := []string{
+		"test", "Acme.sln",
+		"--logger", "trx;LogFileName=" + filepath.Join(wd, "tmp/results.trx"),
+		"--filter", "FullyQualifiedName~.UserTests.|FullyQualifiedName~.UserTests+",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Dotnet.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDotnetFilterEscape(t *testing.T) {
+	got := dotnetFilterEscape(`Acme.Tests.Parser.Parses(input: "a|b")`)
+	want := `Acme.Tests.Parser.Parses\(input: "a\|b"\)`
+	if got != want {
+		t.Errorf("dotnetFilterEscape() = %q, want %q", got, want)
+	}
+}
+
+func TestDotnetDiscoverTestTargets(t *testing.T) {
+	changeCwd(t, "./testdata/dotnet")
+
+	dotnet := NewDotnet(RunnerConfig{})
+
+	got, err := dotnet.DiscoverTestTargets()
+	if err != nil {
+		t.Fatalf("Dotnet.DiscoverTestTargets() error = %v", err)
+	}
+
+	want := []string{
+		"Acme.Tests/Billing/InvoiceTests.cs",
+		"Acme.Tests/UserTests.cs",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Dotnet.DiscoverTestTargets() diff (-want +got):\n%s", diff)
+	}
+}
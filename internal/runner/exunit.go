@@ -0,0 +1,318 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// ExUnit is a runner for Elixir tests run with `mix test`.
+//
+// Results are read from the JUnit XML report written by the junit_formatter package, which has to
+// include the file and line of each test, so failed tests can be retried with `mix test file:line`.
+type ExUnit struct {
+	RunnerConfig
+}
+
+func NewExUnit(e RunnerConfig) ExUnit {
+	if e.TestCommand == "" {
+		e.TestCommand = "mix test {{testExamples}} --formatter JUnitFormatter --formatter ExUnit.CLIFormatter"
+	}
+
+	if e.TestFilePattern == "" {
+		e.TestFilePattern = "test/**/*_test.exs"
+	}
+
+	if e.RetryTestCommand == "" {
+		e.RetryTestCommand = e.TestCommand
+	}
+
+	return ExUnit{
+		RunnerConfig: e,
+	}
+}
+
+func (e ExUnit) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     true,
+		SplitByExample:  true,
+		FilterTestFiles: true,
+		FilterTestByTag: false,
+		AutoRetry:       true,
+		Mute:            true,
+		Skip:            false,
+		SplitBySelector: true,
+	}
+}
+
+func (e ExUnit) Name() string {
+	return "ExUnit"
+}
+
+// DiscoverTestTargets returns file names using the discovery pattern.
+func (e ExUnit) DiscoverTestTargets() ([]string, error) {
+	debug.Println("Discovering test files with include pattern:", e.TestFilePattern, "exclude pattern:", e.TestFileExcludePattern)
+	files, err := discoverTestFiles(e.TestFilePattern, e.TestFileExcludePattern)
+	debug.Println("Discovered", len(files), "files")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found with pattern %q and exclude pattern %q", e.TestFilePattern, e.TestFileExcludePattern)
+	}
+
+	return files, nil
+}
+
+func (e ExUnit) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(e, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resultPath, err := filepath.Abs(e.ResultPath)
+	if err != nil {
+		return err
+	}
+
+	// junit_formatter can only be configured in the application environment, so the result path
+	// is given to config/test.exs with an environment variable.
+	cmd.Env = append(cmd.Env, fmt.Sprintf("BUILDKITE_TEST_ENGINE_RESULT_PATH=%s", resultPath))
+
+	if err := os.Remove(resultPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove previous result file: %w", err)
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// mix test exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the report even if the command returns an error.
+	tests, parseErr := loadAndParseJUnitXML(resultPath)
+	if parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read ExUnit output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the report,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %v", err)
+	}
+
+	// Failed tests are retried by their file and line, so they can't be retried without them.
+	if slices.ContainsFunc(tests, func(test JUnitXMLTestCase) bool { return test.File == "" }) {
+		fmt.Println("Buildkite Test Engine Client: Failed to read ExUnit output, tests will not be retried: the JUnit report doesn't include the test files, set include_filename? and include_file_line? in the junit_formatter configuration")
+		return cmdErr
+	}
+
+	for _, test := range tests {
+		result.RecordTestAttempt(mapExUnitJUnitToTestCase(test, workDir), test.Attempt())
+	}
+
+	// Return any command error after processing the report
+	return cmdErr
+}
+
+// IsTestFailureExitCode reports whether the exit code can be caused by test failures.
+// mix test exits with 2 when tests fail, the default of its --exit-status option, and with 1
+// for other errors such as compilation failures.
+func (e ExUnit) IsTestFailureExitCode(code int) bool {
+	return code == 2
+}
+
+// exunitFileLinePattern matches a file attribute of junit_formatter that includes the line of the test,
+// e.g. "test/my_app/user_test.exs:12".
+var exunitFileLinePattern = regexp.MustCompile(`^(.+):(\d+)$`)
+
+// mapExUnitJUnitToTestCase maps a testcase of a junit_formatter report to a test case.
+// The scope is the test module and the name is the test name, e.g. "test saves the user".
+// The path is the file and line of the test, which `mix test` can run on its own.
+func mapExUnitJUnitToTestCase(test JUnitXMLTestCase, workDir string) plan.TestCase {
+	file, line := test.File, test.Line
+	if matches := exunitFileLinePattern.FindStringSubmatch(file); matches != nil {
+		file, line = matches[1], matches[2]
+	}
+
+	if rel, err := filepath.Rel(workDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+
+	path := file
+	if line != "" {
+		path = fmt.Sprintf("%s:%s", file, line)
+	}
+
+	return plan.TestCase{
+		Identifier: path,
+		Format:     plan.TestCaseFormatExample,
+		Scope:      strings.TrimPrefix(test.Classname, "Elixir."),
+		Name:       test.Name,
+		Path:       path,
+	}
+}
+
+// CommandNameAndArgs replaces the "{{testExamples}}" placeholder in the test command with the test cases,
+// which are test files or the file and line of single tests, and "{{resultPath}}" with the absolute result path.
+// On retry, the test cases are the file and line of the failed tests, e.g. "test/my_app/user_test.exs:12".
+func (e ExUnit) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := e.TestCommand
+	if retry {
+		cmd = e.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	testPaths := pathsFromTestCases(testCases)
+
+	idx := slices.Index(words, "{{testExamples}}")
+	if idx < 0 {
+		words = append(words, testPaths...)
+	} else {
+		words = slices.Replace(words, idx, idx+1, testPaths...)
+	}
+
+	resultPath, err := filepath.Abs(e.ResultPath)
+	if err != nil {
+		return "", []string{}, err
+	}
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, "{{resultPath}}", resultPath)
+	}
+
+	return words[0], words[1:], nil
+}
+
+// GetExamples returns an array of test examples within the given files.
+// The tests are listed with `mix run`, which loads the test files without running them.
+func (e ExUnit) GetExamples(files []string) ([]plan.TestCase, error) {
+	if len(files) == 0 {
+		return []plan.TestCase{}, nil
+	}
+
+	script, err := os.CreateTemp("", "exunit-list-*.exs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file for listing ExUnit tests: %w", err)
+	}
+	defer os.Remove(script.Name())
+
+	if _, err := script.WriteString(exunitListScript); err != nil {
+		script.Close()
+		return nil, fmt.Errorf("failed to write ExUnit list script: %w", err)
+	}
+	script.Close()
+
+	// Create a temporary file to store the listed tests.
+	// We cannot simply read them from stdout because test files may print when they are loaded.
+	f, err := os.CreateTemp("", "exunit-list-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file for listing ExUnit tests: %w", err)
+	}
+
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	args := append([]string{"run", "--no-start", script.Name(), f.Name()}, files...)
+	debug.Printf("Running `mix run` to list the tests in %d files", len(files))
+
+	cmd := exec.Command("mix", args...)
+	// Test support files are only compiled in the test environment.
+	cmd.Env = append(os.Environ(), "MIX_ENV=test")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ExUnit tests: %s", output)
+	}
+
+	return parseExUnitListOutput(f.Name())
+}
+
+// parseExUnitListOutput reads the tests written by exunitListScript.
+func parseExUnitListOutput(path string) ([]plan.TestCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ExUnit test list: %w", err)
+	}
+
+	var tests []exunitListedTest
+	if err := json.Unmarshal(data, &tests); err != nil {
+		return nil, fmt.Errorf("failed to parse ExUnit test list: %w", err)
+	}
+
+	testCases := make([]plan.TestCase, 0, len(tests))
+	for _, test := range tests {
+		path := fmt.Sprintf("%s:%d", test.File, test.Line)
+		testCases = append(testCases, plan.TestCase{
+			Identifier: path,
+			Format:     plan.TestCaseFormatExample,
+			Scope:      test.Scope,
+			Name:       test.Name,
+			Path:       path,
+		})
+	}
+
+	return testCases, nil
+}
+
+// exunitListScript loads the test files given after the output file, and writes the tests of every
+// test module to the output file, as JSON. ExUnit is started without autorun, so the tests are
+// registered when their module is loaded but never run. The application isn't started, and
+// test_helper.exs isn't loaded, as it usually starts ExUnit with autorun.
+// The JSON is written by hand, as Elixir didn't include a JSON encoder before 1.18.
+const exunitListScript = `
+[output | files] = System.argv()
+
+ExUnit.start(autorun: false)
+
+encode = fn value ->
+  escaped = value |> String.replace("\\", "\\\\") |> String.replace("\"", "\\\"")
+
+  escaped =
+    Regex.replace(~r/[\x00-\x1f]/, escaped, fn <<char>> ->
+      "\\u" <> String.pad_leading(Integer.to_string(char, 16), 4, "0")
+    end)
+
+  "\"" <> escaped <> "\""
+end
+
+tests =
+  for file <- files,
+      {module, _binary} <- Code.require_file(file),
+      function_exported?(module, :__ex_unit__, 0),
+      test <- module.__ex_unit__().tests do
+    fields = [
+      {"scope", encode.(inspect(module))},
+      {"name", encode.(Atom.to_string(test.name))},
+      {"file", encode.(Path.relative_to_cwd(test.tags.file))},
+      {"line", Integer.to_string(test.tags.line)}
+    ]
+
+    "{" <> Enum.map_join(fields, ",", fn {key, value} -> encode.(key) <> ":" <> value end) <> "}"
+  end
+
+File.write!(output, "[" <> Enum.join(tests, ",") <> "]")
+`
+
+// exunitListedTest is a test listed by exunitListScript.
+type exunitListedTest struct {
+	Scope string `json:"scope"`
+	Name  string `json:"name"`
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+}
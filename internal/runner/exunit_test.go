@@ -0,0 +1,304 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExUnitRun(t *testing.T) {
+	changeCwd(t, "./testdata/exunit")
+
+	resultPath := filepath.Join(t.TempDir(), "junit.xml")
+	// A stale report from a previous attempt is removed before the run.
+	if err := os.WriteFile(resultPath, []byte("not xml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The result path is given to the command with BUILDKITE_TEST_ENGINE_RESULT_PATH,
+	// which config/config.exs reads to configure junit_formatter.
+	exunit := NewExUnit(RunnerConfig{
+		TestCommand: `sh -c 'cp junit.xml "$BUILDKITE_TEST_ENGINE_RESULT_PATH"; exit 1'`,
+		ResultPath:  resultPath,
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/my_app/user_test.exs"},
+		{Path: "test/my_app/post_test.exs"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := exunit.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("ExUnit.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	want := []plan.TestCase{
+		{
+			Identifier: "test/my_app/user_test.exs:9",
+			Format:     plan.TestCaseFormatExample,
+			Scope:      "MyApp.UserTest",
+			Name:       "test validate/1 rejects invalid emails",
+			Path:       "test/my_app/user_test.exs:9",
+		},
+	}
+	if diff := cmp.Diff(want, result.FailedTests()); diff != "" {
+		t.Errorf("ExUnit.Run(%q) RunResult.FailedTests() diff (-want +got):\n%s", testCases, diff)
+	}
+
+	stats := result.Statistics()
+	if stats.Total != 4 || stats.Skipped != 1 {
+		t.Errorf("ExUnit.Run(%q) RunResult.Statistics() = %+v, want 4 tests with 1 skipped", testCases, stats)
+	}
+
+	failed := result.tests["MyApp.UserTest/test validate/1 rejects invalid emails/test/my_app/user_test.exs:9"]
+	if failed == nil {
+		t.Fatalf("ExUnit.Run(%q) didn't record test validate/1 rejects invalid emails", testCases)
+	}
+	if failed.Duration() != 3901*time.Microsecond {
+		t.Errorf("test validate/1 rejects invalid emails Duration() = %v, want %v", failed.Duration(), 3901*time.Microsecond)
+	}
+}
+
+func TestExUnitRun_MutedFailures(t *testing.T) {
+	changeCwd(t, "./testdata/exunit")
+
+	// mix test exits with 2 when tests fail.
+	exunit := NewExUnit(RunnerConfig{
+		TestCommand: `sh -c 'cp junit.xml "$BUILDKITE_TEST_ENGINE_RESULT_PATH"; exit 2'`,
+		ResultPath:  filepath.Join(t.TempDir(), "junit.xml"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/my_app/user_test.exs"},
+		{Path: "test/my_app/post_test.exs"},
+	}
+	result := NewRunResult([]plan.TestCase{
+		{Scope: "MyApp.UserTest", Name: "test validate/1 rejects invalid emails", Path: "test/my_app/user_test.exs:9"},
+	})
+	err := exunit.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	if !errors.As(err, &exitError) {
+		t.Fatalf("ExUnit.Run(%q) error = %v, want an exit error", testCases, err)
+	}
+
+	if !exunit.IsTestFailureExitCode(exitError.ExitCode()) {
+		t.Errorf("ExUnit.IsTestFailureExitCode(%d) = false, want true", exitError.ExitCode())
+	}
+	if exunit.IsTestFailureExitCode(1) {
+		t.Errorf("ExUnit.IsTestFailureExitCode(1) = true, want false")
+	}
+	if !result.OnlyMutedFailures() {
+		t.Errorf("ExUnit.Run(%q) RunResult.OnlyMutedFailures() = false, want true", testCases)
+	}
+}
+
+func TestExUnitRun_ReportWithoutFiles(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "junit.xml")
+	report := `<testsuites>
+  <testsuite name="Elixir.MyApp.UserTest" tests="1" failures="1">
+    <testcase classname="Elixir.MyApp.UserTest" name="test saves the user" time="0.1">
+      <failure message="error: Assertion with == failed"/>
+    </testcase>
+  </testsuite>
+</testsuites>`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(resultPath), "report.xml"), []byte(report), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exunit := NewExUnit(RunnerConfig{
+		TestCommand: `sh -c 'cp "$(dirname "$BUILDKITE_TEST_ENGINE_RESULT_PATH")/report.xml" "$BUILDKITE_TEST_ENGINE_RESULT_PATH"; exit 1'`,
+		ResultPath:  resultPath,
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/my_app/user_test.exs"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := exunit.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	// Without the files, failed tests can't be retried by their location.
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("ExUnit.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
+func TestExUnitRun_CommandFailed(t *testing.T) {
+	changeCwd(t, "./testdata/exunit")
+
+	exunit := NewExUnit(RunnerConfig{
+		TestCommand: "sh -c 'exit 1'",
+		ResultPath:  filepath.Join(t.TempDir(), "junit.xml"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/my_app/user_test.exs"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := exunit.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("ExUnit.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
+func TestExUnitCommandNameAndArgs(t *testing.T) {
+	exunit := NewExUnit(RunnerConfig{
+		ResultPath: "/tmp/bktec/junit.xml",
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/my_app/user_test.exs"},
+		{Path: "test/my_app/post_test.exs:4"},
+	}
+
+	gotName, gotArgs, err := exunit.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("ExUnit.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "mix" {
+		t.Errorf("ExUnit.CommandNameAndArgs() name = %q, want %q", gotName, "mix")
+	}
+
+	wantArgs := []string{
+		"test", "test/my_app/user_test.exs", "test/my_app/post_test.exs:4",
+		"--formatter", "JUnitFormatter", "--formatter", "ExUnit.CLIFormatter",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("ExUnit.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestExUnitCommandNameAndArgs_Retry(t *testing.T) {
+	exunit := NewExUnit(RunnerConfig{
+		RetryTestCommand: "mix test --failed-output {{resultPath}} {{testExamples}}",
+		ResultPath:       "/tmp/bktec/junit.xml",
+	})
+
+	testCases := []plan.TestCase{
+		{
+			Scope: "MyApp.UserTest",
+			Name:  "test validate/1 rejects invalid emails",
+			Path:  "test/my_app/user_test.exs:9",
+		},
+		{
+			Scope: "MyApp.PostTest",
+			Name:  "test publishes the post",
+			Path:  "test/my_app/post_test.exs:4",
+		},
+	}
+
+	_, gotArgs, err := exunit.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Fatalf("ExUnit.CommandNameAndArgs() error = %v", err)
+	}
+
+	wantArgs := []string{
+		"test", "--failed-output", "/tmp/bktec/junit.xml",
+		"test/my_app/user_test.exs:9", "test/my_app/post_test.exs:4",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("ExUnit.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMapExUnitJUnitToTestCase(t *testing.T) {
+	cases := []struct {
+		name string
+		test JUnitXMLTestCase
+		want string
+	}{
+		{
+			name: "file with line",
+			test: JUnitXMLTestCase{Classname: "Elixir.MyApp.UserTest", Name: "test saves the user", File: "test/my_app/user_test.exs:4"},
+			want: "test/my_app/user_test.exs:4",
+		},
+		{
+			name: "absolute file",
+			test: JUnitXMLTestCase{Classname: "Elixir.MyApp.UserTest", Name: "test saves the user", File: "/app/test/my_app/user_test.exs:4"},
+			want: "test/my_app/user_test.exs:4",
+		},
+		{
+			name: "file without line",
+			test: JUnitXMLTestCase{Classname: "MyApp.UserTest", Name: "test saves the user", File: "test/my_app/user_test.exs"},
+			want: "test/my_app/user_test.exs",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := mapExUnitJUnitToTestCase(tc.test, "/app")
+			want := plan.TestCase{
+				Identifier: tc.want,
+				Format:     plan.TestCaseFormatExample,
+				Scope:      "MyApp.UserTest",
+				Name:       "test saves the user",
+				Path:       tc.want,
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("mapExUnitJUnitToTestCase() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExUnitDiscoverTestTargets(t *testing.T) {
+	changeCwd(t, "./testdata/exunit")
+
+	exunit := NewExUnit(RunnerConfig{})
+
+	got, err := exunit.DiscoverTestTargets()
+	if err != nil {
+		t.Fatalf("ExUnit.DiscoverTestTargets() error = %v", err)
+	}
+
+	want := []string{
+		"test/my_app/post_test.exs",
+		"test/my_app/user_test.exs",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ExUnit.DiscoverTestTargets() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseExUnitListOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.json")
+	list := `[
+		{"scope": "MyApp.UserTest", "name": "test saves the user", "file": "test/my_app/user_test.exs", "line": 4},
+		{"scope": "MyApp.UserTest", "name": "test validate/1 rejects \"invalid\" emails", "file": "test/my_app/user_test.exs", "line": 9}
+	]`
+	if err := os.WriteFile(path, []byte(list), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseExUnitListOutput(path)
+	if err != nil {
+		t.Fatalf("parseExUnitListOutput() error = %v", err)
+	}
+
+	want := []plan.TestCase{
+		{Identifier: "test/my_app/user_test.exs:4", Format: plan.TestCaseFormatExample, Scope: "MyApp.UserTest", Name: "test saves the user", Path: "test/my_app/user_test.exs:4"},
+		{Identifier: "test/my_app/user_test.exs:9", Format: plan.TestCaseFormatExample, Scope: "MyApp.UserTest", Name: `test validate/1 rejects "invalid" emails`, Path: "test/my_app/user_test.exs:9"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseExUnitListOutput() diff (-want +got):\n%s", diff)
+	}
+}
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,7 +10,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/buildkite/test-engine-client/v3/internal/debug"
 	"github.com/buildkite/test-engine-client/v3/internal/plan"
@@ -19,6 +23,8 @@ import (
 type GoTest struct {
 	RunnerConfig
 	resultFormat string
+	// binaries are the test binaries compiled so far, by package, when GoTestBinaryDir is set.
+	binaries map[string]goTestBinary
 }
 
 const (
@@ -39,20 +45,22 @@ func NewGoTest(c RunnerConfig) GoTest {
 	}
 
 	resultFormat := goTestResultFormatJUnit
-	if commandProducesGoJSONL(c.TestCommand) {
+	// test2json reports the results of test binaries as Go JSONL, whatever the test command is.
+	if commandProducesGoJSONL(c.TestCommand) || c.GoTestBinaryDir != "" {
 		resultFormat = goTestResultFormatGoJSONL
 	}
 
 	return GoTest{
 		RunnerConfig: c,
 		resultFormat: resultFormat,
+		binaries:     map[string]goTestBinary{},
 	}
 }
 
 func (g GoTest) SupportedFeatures() SupportedFeatures {
 	return SupportedFeatures{
 		SplitByFile:     false,
-		SplitByExample:  false,
+		SplitByExample:  true,
 		SplitBySelector: true,
 		FilterTestFiles: false,
 		FilterTestByTag: false,
@@ -79,11 +87,67 @@ func commandProducesGoJSONL(command string) bool {
 }
 
 // Run executes the configured command for the specified packages.
+//
+// Packages split by test function, and failed tests on retry, only run the given
+// test functions. Each invocation of the command passes a single -run pattern to
+// go test, so the packages are grouped by their test functions, and each group is
+// run with its own invocation.
+//
+// When GoTestBinaryDir is set, the tests run with compiled test binaries instead of the test command.
 func (g GoTest) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(g, testCases, retry)
+	if g.GoTestBinaryDir != "" {
+		return g.runBinaries(result, testCases, retry)
+	}
+
+	groups := goTestRunGroups(testCases)
+	if len(groups) == 1 {
+		return g.run(result, groups[0], retry)
+	}
+
+	// Each invocation overwrites the result file, so the results of every group are
+	// kept and written back once all groups have run, to upload the whole attempt.
+	// Go JSONL is concatenated, and the JUnit XML reports are merged into a single report.
+	var reports [][]byte
+	defer func() {
+		if len(reports) == 0 {
+			return
+		}
+		results := bytes.Join(reports, nil)
+		if g.resultFormat == goTestResultFormatJUnit {
+			var err error
+			if results, err = mergeJUnitXMLReports(reports); err != nil {
+				fmt.Printf("Buildkite Test Engine Client: Failed to merge Go test results: %v\n", err)
+				return
+			}
+		}
+		if err := os.WriteFile(g.ResultPath, results, 0644); err != nil {
+			fmt.Printf("Buildkite Test Engine Client: Failed to write Go test results: %v\n", err)
+		}
+	}()
+
+	var testErr error
+	for _, group := range groups {
+		err := g.run(result, group, retry)
+		if data, readErr := os.ReadFile(g.ResultPath); readErr == nil {
+			reports = append(reports, data)
+		}
+		// A test failure exits with 1, in which case the remaining groups still run.
+		if exitError := new(exec.ExitError); err != nil && !(errors.As(err, &exitError) && exitError.ExitCode() == 1) {
+			return err
+		}
+		if err != nil {
+			testErr = err
+		}
+	}
+	return testErr
+}
+
+func (g GoTest) run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(g, testCases, retry)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 
 	cmdErr := g.runCommand(cmd)
 
@@ -154,13 +218,13 @@ func (g GoTest) parseJUnitResults(result *RunResult) error {
 			continue
 		}
 
-		result.RecordTestResult(plan.TestCase{
+		result.RecordTestAttempt(plan.TestCase{
 			Format: plan.TestCaseFormatExample,
 			Scope:  test.Classname,
 			Name:   test.Name,
 			// This is the special thing about go test support.
 			Path: test.Classname,
-		}, test.Result)
+		}, test.Attempt())
 	}
 
 	return nil
@@ -172,6 +236,8 @@ type goJSONLTestEvent struct {
 	Test        string `json:"Test"`
 	Output      string `json:"Output"`
 	FailedBuild string `json:"FailedBuild"`
+	// Elapsed is the duration of the test or package in seconds, set on pass, fail and skip events.
+	Elapsed float64 `json:"Elapsed"`
 }
 
 func (g GoTest) parseGoJSONLResults(result *RunResult) error {
@@ -186,6 +252,8 @@ func (g GoTest) parseGoJSONLResults(result *RunResult) error {
 	packageHasFailedTest := map[string]bool{}
 	testStatuses := map[string]TestStatus{}
 	testCases := map[string]plan.TestCase{}
+	testOutputs := map[string]string{}
+	testDurations := map[string]time.Duration{}
 
 	for _, event := range events {
 		if event.Package == "" {
@@ -204,12 +272,17 @@ func (g GoTest) parseGoJSONLResults(result *RunResult) error {
 			continue
 		}
 
+		key := event.Package + "/" + event.Test
+		if event.Action == "output" {
+			testOutputs[key] += event.Output
+		}
+
 		status, ok := goJSONLActionStatus(event.Action)
 		if !ok {
 			continue
 		}
 
-		key := event.Package + "/" + event.Test
+		testDurations[key] = secondsToDuration(event.Elapsed)
 		if testStatuses[key] != TestStatusFailed {
 			testStatuses[key] = status
 		}
@@ -225,7 +298,11 @@ func (g GoTest) parseGoJSONLResults(result *RunResult) error {
 	}
 
 	for key, testCase := range testCases {
-		result.RecordTestResult(testCase, testStatuses[key])
+		attempt := TestAttempt{Status: testStatuses[key], Duration: testDurations[key]}
+		if attempt.Status == TestStatusFailed {
+			attempt.Failure = goTestFailure(testOutputs[key])
+		}
+		result.RecordTestAttempt(testCase, attempt)
 	}
 
 	for pkg := range packageFailed {
@@ -292,6 +369,24 @@ func goJSONLActionStatus(action string) (TestStatus, bool) {
 	}
 }
 
+// goTestFailure builds a TestFailure from the output of a failed test,
+// leaving out the lines go test prints to mark the progress of the test.
+func goTestFailure(output string) *TestFailure {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") {
+			continue
+		}
+		lines = append(lines, trimmed)
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+	return &TestFailure{Message: strings.Join(lines, "\n")}
+}
+
 // isBuildFailure reports whether a JUnit testcase is gotestsum's synthetic
 // representation of a Go package that failed to build. The "TestMain" name and
 // empty classname signature is also produced by package-level failures such as
@@ -340,6 +435,57 @@ func (g GoTest) DiscoverTestTargets() ([]string, error) {
 	}
 	return validPackages, nil
 }
+
+// GetExamples returns the test functions of the given packages, as listed by
+// `go test -list` for each package. Subtests can't be listed without running
+// them, so each test function is an example. Benchmarks are left out, since
+// they don't run without -bench.
+func (g GoTest) GetExamples(packages []string) ([]plan.TestCase, error) {
+	var testCases []plan.TestCase
+	for _, pkg := range packages {
+		debug.Printf("Listing tests in %s using `go test -list`", pkg)
+		cmd := exec.Command("go", "test", "-list", ".*", pkg)
+		output, err := cmd.Output()
+		if err != nil {
+			if ee, ok := err.(*exec.ExitError); ok {
+				return nil, fmt.Errorf("go test -list failed for %s: %w\nstderr:\n%s", pkg, err, string(ee.Stderr))
+			}
+			return nil, fmt.Errorf("failed to run go test -list: %w", err)
+		}
+		testCases = append(testCases, parseGoTestListOutput(pkg, string(output))...)
+	}
+	return testCases, nil
+}
+
+// parseGoTestListOutput parses the output of `go test -list` for a package.
+//
+// Example output:
+//
+//	TestHelloWorld
+//	TestHelloWorld_Empty
+//	ExampleHello
+//	BenchmarkHello
+//	ok  	example.com/hello	0.002s
+func parseGoTestListOutput(pkg string, output string) []plan.TestCase {
+	var testCases []plan.TestCase
+	for _, line := range strings.Split(output, "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		if !strings.HasPrefix(name, "Test") && !strings.HasPrefix(name, "Example") && !strings.HasPrefix(name, "Fuzz") {
+			continue
+		}
+		testCases = append(testCases, plan.TestCase{
+			Format: plan.TestCaseFormatExample,
+			Scope:  pkg,
+			Name:   name,
+			Path:   pkg,
+		})
+	}
+	return testCases
+}
+
 func (g GoTest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
 	packages, err := g.getPackages(testCases)
 	if err != nil {
@@ -352,6 +498,9 @@ func (g GoTest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (strin
 	}
 
 	concatenatedPackages := strings.Join(packages, " ")
+	if pattern := goTestRunPattern(testCases); pattern != "" {
+		concatenatedPackages += " -run " + shellquote.Join(pattern)
+	}
 
 	if strings.Contains(cmd, "{{packages}}") {
 		cmd = strings.Replace(cmd, "{{packages}}", concatenatedPackages, 1)
@@ -419,6 +568,61 @@ func (g GoTest) getPackages(testCases []plan.TestCase) ([]string, error) {
 	return packages, nil
 }
 
+// goTestRunGroups groups the test cases by package, then puts the packages with the
+// same test functions together, so they can be run with a single -run pattern.
+// The groups are in the order their packages first appear.
+func goTestRunGroups(testCases []plan.TestCase) [][]plan.TestCase {
+	var packages []string
+	packageTestCases := map[string][]plan.TestCase{}
+	for _, tc := range testCases {
+		pkg := packageFromTestCase(tc)
+		if _, ok := packageTestCases[pkg]; !ok {
+			packages = append(packages, pkg)
+		}
+		packageTestCases[pkg] = append(packageTestCases[pkg], tc)
+	}
+
+	var groups [][]plan.TestCase
+	groupIndex := map[string]int{}
+	for _, pkg := range packages {
+		pattern := goTestRunPattern(packageTestCases[pkg])
+		i, ok := groupIndex[pattern]
+		if !ok {
+			i = len(groups)
+			groupIndex[pattern] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], packageTestCases[pkg]...)
+	}
+	return groups
+}
+
+// goTestRunPattern returns an anchored -run pattern matching the top-level test
+// functions of the test cases. A subtest is retried by running its parent function,
+// since subtests can depend on the setup done by their parent.
+//
+// It returns an empty pattern when the whole package has to run, which is the case
+// for packages that aren't split by test function, and for a package-level failure,
+// reported as a synthetic "TestMain" test.
+func goTestRunPattern(testCases []plan.TestCase) string {
+	var functions []string
+	for _, tc := range testCases {
+		function, _, _ := strings.Cut(tc.Name, "/")
+		if function == "" || function == "TestMain" {
+			return ""
+		}
+		functions = append(functions, regexp.QuoteMeta(function))
+	}
+
+	slices.Sort(functions)
+	functions = slices.Compact(functions)
+
+	if len(functions) == 1 {
+		return "^" + functions[0] + "$"
+	}
+	return "^(" + strings.Join(functions, "|") + ")$"
+}
+
 func packageFromTestCase(tc plan.TestCase) string {
 	if tc.Format == plan.TestCaseFormatSelector && tc.Value != "" {
 		return tc.Value
@@ -0,0 +1,234 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// goTestBinary is a test binary compiled by `go test -c`, with the directory of its package.
+// A package without test files has no binary, so its path is empty.
+type goTestBinary struct {
+	path string
+	dir  string
+}
+
+// goTestBinaryFlags are the go test flags of a test command, split between the flags
+// compiling the test binaries and the flags running them.
+type goTestBinaryFlags struct {
+	build []string
+	test  []string
+}
+
+// goTestBuildFlags are the go test flags that change how a test binary is compiled,
+// by whether they take a value.
+var goTestBuildFlags = map[string]bool{
+	"a": false, "asan": false, "buildvcs": false, "cover": false, "msan": false, "race": false, "trimpath": false,
+	"asmflags": true, "covermode": true, "coverpkg": true, "gcflags": true, "ldflags": true, "mod": true, "overlay": true, "pgo": true, "tags": true,
+}
+
+// goTestTestFlags are the go test flags that a test binary accepts as -test.* flags,
+// by whether they take a value.
+var goTestTestFlags = map[string]bool{
+	"failfast": false, "fullpath": false, "short": false,
+	"count": true, "cpu": true, "parallel": true, "shuffle": true, "skip": true, "timeout": true,
+}
+
+// parseGoTestBinaryFlags reads the go test flags of a `go test` or `gotestsum -- ...` command.
+// -json and -v are left out, because test binaries always run verbosely through test2json.
+// Any other flag can't be applied to test binaries, so it's an error rather than being ignored.
+func parseGoTestBinaryFlags(command string) (goTestBinaryFlags, error) {
+	var flags goTestBinaryFlags
+
+	words, err := shellquote.Split(command)
+	if err != nil {
+		return flags, err
+	}
+
+	var args []string
+	if len(words) >= 2 && filepath.Base(words[0]) == "go" && words[1] == "test" {
+		args = words[2:]
+	} else if i := slices.Index(words, "--"); i >= 0 && filepath.Base(words[0]) == "gotestsum" {
+		args = words[i+1:]
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// Packages and placeholders are replaced by the packages of the test plan.
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "json" || name == "v" {
+			continue
+		}
+
+		takesValue, isBuildFlag := goTestBuildFlags[name]
+		if !isBuildFlag {
+			var isTestFlag bool
+			if takesValue, isTestFlag = goTestTestFlags[name]; !isTestFlag {
+				return flags, fmt.Errorf("the %s flag of the test command isn't supported with Go test binaries", arg)
+			}
+		}
+
+		if takesValue && !hasValue {
+			if i+1 >= len(args) {
+				return flags, fmt.Errorf("the %s flag of the test command is missing its value", arg)
+			}
+			i++
+			value, hasValue = args[i], true
+		}
+
+		flag := "-" + name
+		if !isBuildFlag {
+			flag = "-test." + name
+		}
+		if hasValue {
+			flag += "=" + value
+		}
+
+		if isBuildFlag {
+			flags.build = append(flags.build, flag)
+		} else {
+			flags.test = append(flags.test, flag)
+		}
+	}
+
+	return flags, nil
+}
+
+// runBinaries runs the tests with test binaries compiled by `go test -c` instead of the test command.
+// Each package is compiled the first time it runs, and its binary is reused by the retries,
+// so they don't pay for compiling and linking again.
+// The binaries run through `go tool test2json`, so their results are read as Go JSONL.
+//
+// The build flags of the test command are used to compile the binaries, and its test flags
+// are passed to them. Retries pass the test flags of the retry command instead, but can't
+// change the build flags, since the binaries are already compiled.
+func (g GoTest) runBinaries(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	flags, err := parseGoTestBinaryFlags(g.TestCommand)
+	if err != nil {
+		return err
+	}
+	if retry {
+		retryFlags, err := parseGoTestBinaryFlags(g.RetryTestCommand)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(retryFlags.build, flags.build) {
+			return fmt.Errorf("the retry command has the build flags %q, but the test binaries are compiled with the build flags %q of the test command", retryFlags.build, flags.build)
+		}
+		flags.test = retryFlags.test
+	}
+
+	file, err := os.Create(g.ResultPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ran := false
+	var testErr error
+	for _, group := range goTestRunGroups(testCases) {
+		packages, err := g.getPackages(group)
+		if err != nil {
+			return fmt.Errorf("failed to generate test package list: %w", err)
+		}
+		pattern := goTestRunPattern(group)
+
+		for _, pkg := range packages {
+			binary, err := g.testBinary(pkg, flags.build)
+			if exitError := new(exec.ExitError); errors.As(err, &exitError) {
+				// A build failure can't be fixed by retrying, so it's an error outside of the tests.
+				// It's attributed to the package that failed to build, and the other packages still run.
+				result.error = fmt.Errorf("go test failed to build %s", pkg)
+				testErr = err
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if binary.path == "" {
+				continue
+			}
+
+			ran = true
+			err = runAndForwardSignalWithOutput(g.testBinaryCommand(binary, pkg, pattern, flags.test), io.MultiWriter(os.Stdout, file), os.Stderr)
+			// A test failure exits with 1, in which case the remaining packages still run.
+			if exitError := new(exec.ExitError); err != nil && !(errors.As(err, &exitError) && exitError.ExitCode() == 1) {
+				return err
+			}
+			if err != nil && testErr == nil {
+				testErr = err
+			}
+		}
+	}
+
+	if !ran {
+		return testErr
+	}
+
+	if parseErr := g.parseGoJSONLResults(result); parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read Go test output, tests will not be retried: %v\n", parseErr)
+	}
+	return testErr
+}
+
+// testBinary returns the test binary of the package, compiling it with the build flags the first time it's needed.
+func (g GoTest) testBinary(pkg string, buildFlags []string) (goTestBinary, error) {
+	if binary, ok := g.binaries[pkg]; ok {
+		return binary, nil
+	}
+
+	path, err := filepath.Abs(filepath.Join(g.GoTestBinaryDir, filepath.FromSlash(pkg)+".test"))
+	if err != nil {
+		return goTestBinary{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return goTestBinary{}, fmt.Errorf("failed to create the Go test binary directory: %w", err)
+	}
+
+	args := append([]string{"test", "-c", "-o", path}, buildFlags...)
+	if err := runAndForwardSignal(exec.Command("go", append(args, pkg)...)); err != nil {
+		return goTestBinary{}, err
+	}
+
+	// go test runs a test binary in the directory of its package, so the tests can read their testdata.
+	output, err := exec.Command("go", append(append([]string{"list", "-f", "{{.Dir}}"}, buildFlags...), pkg)...).Output()
+	if err != nil {
+		return goTestBinary{}, fmt.Errorf("failed to find the directory of %s: %w", pkg, err)
+	}
+
+	binary := goTestBinary{dir: strings.TrimSpace(string(output))}
+	// go test -c doesn't write a binary for a package without test files.
+	if _, err := os.Stat(path); err == nil {
+		binary.path = path
+	}
+
+	g.binaries[pkg] = binary
+	return binary, nil
+}
+
+// testBinaryCommand runs the test binary through test2json, with the flags go test passes to it by default.
+// The test flags come after the defaults, so they override them, e.g. -test.timeout.
+func (g GoTest) testBinaryCommand(binary goTestBinary, pkg string, pattern string, testFlags []string) *exec.Cmd {
+	args := []string{"tool", "test2json", "-t", "-p", pkg, binary.path, "-test.v=test2json", "-test.paniconexit0", "-test.timeout=10m0s"}
+	args = append(args, testFlags...)
+	if pattern != "" {
+		args = append(args, "-test.run="+pattern)
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = binary.dir
+	cmd.Env = append(os.Environ(), fmt.Sprintf("BUILDKITE_ANALYTICS_TOKEN=%s", g.UploadToken()))
+	return cmd
+}
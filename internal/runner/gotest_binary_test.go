@@ -0,0 +1,237 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGotestRun_Binaries(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	binaryDir := t.TempDir()
+	gotest := NewGoTest(RunnerConfig{
+		ResultPath:      filepath.Join(t.TempDir(), "test-results.jsonl"),
+		GoTestBinaryDir: binaryDir,
+	})
+	testCases := []plan.TestCase{
+		{Path: "example.com/hello"},
+		{Path: "example.com/hello/notest"},
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/split", Name: "TestSlow", Path: "example.com/hello/split"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gotest.Run(result, testCases, false)
+
+	assert.NoError(t, err)
+	if result.Status() != RunStatusPassed {
+		t.Errorf("Gotest.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusPassed)
+	}
+
+	var got []string
+	for _, test := range result.Tests() {
+		got = append(got, test.Scope+"/"+test.Name)
+	}
+	want := []string{"example.com/hello/TestHelloWorld", "example.com/hello/split/TestSlow"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Gotest.Run(%q) tests diff (-want +got):\n%s", testCases, diff)
+	}
+
+	if _, err := os.Stat(filepath.Join(binaryDir, "example.com", "hello.test")); err != nil {
+		t.Errorf("os.Stat(hello.test) error = %v, want the test binary to be kept", err)
+	}
+	if gotest.ResultFormat() != goTestResultFormatGoJSONL {
+		t.Errorf("Gotest.ResultFormat() = %q, want %q", gotest.ResultFormat(), goTestResultFormatGoJSONL)
+	}
+}
+
+func TestGotestRun_BinariesReusedOnRetry(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	gotest := NewGoTest(RunnerConfig{
+		ResultPath:      filepath.Join(t.TempDir(), "test-results.jsonl"),
+		GoTestBinaryDir: t.TempDir(),
+	})
+	testCases := []plan.TestCase{
+		{Path: "example.com/hello/bad"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gotest.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	binary := gotest.binaries["example.com/hello/bad"]
+	before, err := os.Stat(binary.path)
+	if err != nil {
+		t.Fatalf("os.Stat(%q) error = %v", binary.path, err)
+	}
+
+	err = gotest.Run(result, result.FailedTests(), true)
+	assert.ErrorAs(t, err, &exitError)
+
+	after, err := os.Stat(binary.path)
+	if err != nil {
+		t.Fatalf("os.Stat(%q) error = %v", binary.path, err)
+	}
+	if !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("test binary was modified on retry, want it to be reused")
+	}
+
+	test := result.tests["example.com/hello/bad/TestBad/example.com/hello/bad"]
+	if test.ExecutionCount != 2 {
+		t.Errorf("TestBad ExecutionCount = %d, want 2", test.ExecutionCount)
+	}
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Gotest.Run() RunResult.Status = %v, want %v", result.Status(), RunStatusFailed)
+	}
+}
+
+func TestGotestRun_BinariesBuildFailed(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	gotest := NewGoTest(RunnerConfig{
+		ResultPath:      filepath.Join(t.TempDir(), "test-results.jsonl"),
+		GoTestBinaryDir: t.TempDir(),
+	})
+	testCases := []plan.TestCase{
+		{Path: "example.com/hello/broken"},
+		{Path: "example.com/hello"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gotest.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusError {
+		t.Errorf("Gotest.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusError)
+	}
+	if result.Error().Error() != "go test failed to build example.com/hello/broken" {
+		t.Errorf("Gotest.Run(%q) RunResult.Error = %v, want the build failure of example.com/hello/broken", testCases, result.Error())
+	}
+
+	// The packages that build still run.
+	if _, ok := result.tests["example.com/hello/TestHelloWorld/example.com/hello"]; !ok {
+		t.Errorf("Gotest.Run(%q) didn't run example.com/hello", testCases)
+	}
+	if failed := result.FailedTests(); len(failed) != 0 {
+		t.Errorf("Gotest.Run(%q) RunResult.FailedTests() = %v, want none", testCases, failed)
+	}
+}
+
+func TestGotestRun_BinariesPackageLevelFailure(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	gotest := NewGoTest(RunnerConfig{
+		ResultPath:      filepath.Join(t.TempDir(), "test-results.jsonl"),
+		GoTestBinaryDir: t.TempDir(),
+	})
+	testCases := []plan.TestCase{
+		{Path: "example.com/hello/testmain"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gotest.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/testmain", Name: "TestMain", Path: "example.com/hello/testmain"},
+	}
+	if diff := cmp.Diff(want, result.FailedTests()); diff != "" {
+		t.Errorf("Gotest.Run(%q) RunResult.FailedTests() diff (-want +got):\n%s", testCases, diff)
+	}
+}
+
+func TestParseGoTestBinaryFlags(t *testing.T) {
+	cases := []struct {
+		command string
+		want    goTestBinaryFlags
+	}{
+		{
+			command: "gotestsum --jsonfile={{resultPath}} {{packages}}",
+			want:    goTestBinaryFlags{},
+		},
+		{
+			command: "go test -json -race -tags integration -timeout=20m -count 1 -short {{packages}}",
+			want: goTestBinaryFlags{
+				build: []string{"-race", "-tags=integration"},
+				test:  []string{"-test.timeout=20m", "-test.count=1", "-test.short"},
+			},
+		},
+		{
+			command: "gotestsum --jsonfile={{resultPath}} -- -v --tags=integration -failfast {{packages}}",
+			want: goTestBinaryFlags{
+				build: []string{"-tags=integration"},
+				test:  []string{"-test.failfast"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.command, func(t *testing.T) {
+			got, err := parseGoTestBinaryFlags(tc.command)
+			if err != nil {
+				t.Fatalf("parseGoTestBinaryFlags(%q) error = %v", tc.command, err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(goTestBinaryFlags{})); diff != "" {
+				t.Errorf("parseGoTestBinaryFlags(%q) diff (-want +got):\n%s", tc.command, diff)
+			}
+		})
+	}
+}
+
+func TestParseGoTestBinaryFlags_Unsupported(t *testing.T) {
+	cases := []string{
+		"go test -exec xprog {{packages}}",
+		"gotestsum -- -coverprofile=cover.out {{packages}}",
+		"go test {{packages}} -tags",
+	}
+
+	for _, command := range cases {
+		if _, err := parseGoTestBinaryFlags(command); err == nil {
+			t.Errorf("parseGoTestBinaryFlags(%q) error = nil, want an error", command)
+		}
+	}
+}
+
+func TestGotestRun_BinariesWithFlags(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	binaryDir := t.TempDir()
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand:      "go test -json -tags integration -count=1 {{packages}}",
+		RetryTestCommand: "go test -json -tags integration -count=1 -failfast {{packages}}",
+		ResultPath:       filepath.Join(t.TempDir(), "test-results.jsonl"),
+		GoTestBinaryDir:  binaryDir,
+	})
+	testCases := []plan.TestCase{{Path: "example.com/hello"}}
+
+	result := NewRunResult([]plan.TestCase{})
+	assert.NoError(t, gotest.Run(result, testCases, false))
+	assert.NoError(t, gotest.Run(result, testCases, true))
+
+	if result.Status() != RunStatusPassed {
+		t.Errorf("Gotest.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusPassed)
+	}
+}
+
+func TestGotestRun_BinariesRetryChangesBuildFlags(t *testing.T) {
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand:      "go test -json {{packages}}",
+		RetryTestCommand: "go test -json -race {{packages}}",
+		ResultPath:       filepath.Join(t.TempDir(), "test-results.jsonl"),
+		GoTestBinaryDir:  t.TempDir(),
+	})
+	testCases := []plan.TestCase{{Path: "example.com/hello"}}
+
+	err := gotest.Run(NewRunResult([]plan.TestCase{}), testCases, true)
+	if err == nil {
+		t.Errorf("Gotest.Run(%q) error = nil, want an error", testCases)
+	}
+}
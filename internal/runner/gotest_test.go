@@ -5,8 +5,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/buildkite/test-engine-client/v3/internal/plan"
 	"github.com/google/go-cmp/cmp"
@@ -255,6 +257,41 @@ func TestGotestRun_GoJSONLFailureIsNotOverwrittenByLaterPass(t *testing.T) {
 	}
 }
 
+func TestGotestParseGoJSONLResults_FailureDetails(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "test-results.jsonl")
+	err := os.WriteFile(resultPath, []byte(`{"Action":"start","Package":"example.com/hello"}
+{"Action":"run","Package":"example.com/hello","Test":"TestHello"}
+{"Action":"output","Package":"example.com/hello","Test":"TestHello","Output":"=== RUN   TestHello\n"}
+{"Action":"output","Package":"example.com/hello","Test":"TestHello","Output":"    hello_test.go:10: got \"hi\", want \"hello\"\n"}
+{"Action":"output","Package":"example.com/hello","Test":"TestHello","Output":"--- FAIL: TestHello (0.25s)\n"}
+{"Action":"fail","Package":"example.com/hello","Test":"TestHello","Elapsed":0.25}
+{"Action":"fail","Package":"example.com/hello","Elapsed":0.3}
+`), 0o600)
+	assert.NoError(t, err)
+
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand: "go test -json {{packages}}",
+		ResultPath:  resultPath,
+	})
+	result := NewRunResult([]plan.TestCase{})
+	err = gotest.parseGoJSONLResults(result)
+	assert.NoError(t, err)
+
+	tests := result.Tests()
+	if len(tests) != 1 {
+		t.Fatalf("GoTest.parseGoJSONLResults() RunResult.Tests() = %v, want 1 test", tests)
+	}
+
+	want := TestAttempt{
+		Status:   TestStatusFailed,
+		Duration: 250 * time.Millisecond,
+		Failure:  &TestFailure{Message: `hello_test.go:10: got "hi", want "hello"`},
+	}
+	if diff := cmp.Diff(want, tests[0].LastAttempt()); diff != "" {
+		t.Errorf("GoTest.parseGoJSONLResults() attempt diff (-want +got):\n%s", diff)
+	}
+}
+
 func TestGotestRun_CommandFailed(t *testing.T) {
 	changeCwd(t, "./testdata/go")
 
@@ -383,6 +420,7 @@ func TestGotestDiscoverTestTargets(t *testing.T) {
 		"example.com/hello",
 		"example.com/hello/bad",
 		"example.com/hello/broken",
+		"example.com/hello/split",
 		"example.com/hello/testmain",
 	}
 
@@ -391,6 +429,166 @@ func TestGotestDiscoverTestTargets(t *testing.T) {
 	}
 }
 
+func TestGotestGetExamples(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	gotest := NewGoTest(RunnerConfig{})
+
+	got, err := gotest.GetExamples([]string{"example.com/hello", "example.com/hello/split"})
+	if err != nil {
+		t.Fatalf("Gotest.GetExamples() error = %v", err)
+	}
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello", Name: "TestHelloWorld", Path: "example.com/hello"},
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/split", Name: "TestFast", Path: "example.com/hello/split"},
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/split", Name: "TestSlow", Path: "example.com/hello/split"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Gotest.GetExamples() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGotestGetExamples_BuildFailed(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	gotest := NewGoTest(RunnerConfig{})
+
+	_, err := gotest.GetExamples([]string{"example.com/hello/broken"})
+	if err == nil {
+		t.Errorf("Gotest.GetExamples() error = nil, want an error")
+	}
+}
+
+func TestParseGoTestListOutput(t *testing.T) {
+	output := "TestHello\nExampleHello\nFuzzHello\nBenchmarkHello\nok  \texample.com/hello\t0.002s\n"
+
+	got := parseGoTestListOutput("example.com/hello", output)
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello", Name: "TestHello", Path: "example.com/hello"},
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello", Name: "ExampleHello", Path: "example.com/hello"},
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello", Name: "FuzzHello", Path: "example.com/hello"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("parseGoTestListOutput() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGotestRun_SplitByExample(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand: "go test -json {{packages}}",
+		ResultPath:  filepath.Join(t.TempDir(), "test-results.jsonl"),
+	})
+	testCases := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/split", Name: "TestSlow", Path: "example.com/hello/split"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gotest.Run(result, testCases, false)
+
+	assert.NoError(t, err)
+
+	var got []string
+	for _, test := range result.Tests() {
+		got = append(got, test.Name)
+	}
+	if diff := cmp.Diff(got, []string{"TestSlow"}); diff != "" {
+		t.Errorf("Gotest.Run(%q) tests diff (-got +want):\n%s", testCases, diff)
+	}
+}
+
+func TestGotestRun_SplitByExampleKeepsResultsOfEveryGroup(t *testing.T) {
+	changeCwd(t, "./testdata/go")
+
+	resultPath := filepath.Join(t.TempDir(), "test-results.jsonl")
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand: "go test -json {{packages}}",
+		ResultPath:  resultPath,
+	})
+	testCases := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/split", Name: "TestSlow", Path: "example.com/hello/split"},
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello", Name: "TestHelloWorld", Path: "example.com/hello"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gotest.Run(result, testCases, false)
+
+	assert.NoError(t, err)
+
+	// The result file has the results of both invocations.
+	results := NewRunResult([]plan.TestCase{})
+	if err := gotest.parseGoJSONLResults(results); err != nil {
+		t.Fatalf("parseGoJSONLResults() error = %v", err)
+	}
+	var got []string
+	for _, test := range results.Tests() {
+		got = append(got, test.Name)
+	}
+	slices.Sort(got)
+	if diff := cmp.Diff(got, []string{"TestHelloWorld", "TestSlow"}); diff != "" {
+		t.Errorf("result file tests diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGotestRun_SplitByExampleMergesJUnitReportsOfEveryGroup(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "test-results.xml")
+	// Writes a JUnit XML report with a test of the package, like gotestsum --junitfile.
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand: `sh -c 'echo "<testsuites><testsuite name=\"$1\"><testcase classname=\"$1\" name=\"$3\"></testcase></testsuite></testsuites>" > "$0"' {{resultPath}} {{packages}}`,
+		ResultPath:  resultPath,
+	})
+	testCases := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/split", Name: "TestSlow", Path: "example.com/hello/split"},
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello", Name: "TestHelloWorld", Path: "example.com/hello"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gotest.Run(result, testCases, false)
+
+	assert.NoError(t, err)
+
+	// The result file has the test suites of both invocations.
+	tests, err := loadAndParseJUnitXML(resultPath)
+	if err != nil {
+		t.Fatalf("loadAndParseJUnitXML(%q) error = %v", resultPath, err)
+	}
+	var got []string
+	for _, test := range tests {
+		got = append(got, test.Classname)
+	}
+	slices.Sort(got)
+	if diff := cmp.Diff(got, []string{"example.com/hello", "example.com/hello/split"}); diff != "" {
+		t.Errorf("result file tests diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGotestCommandNameAndArgs_ExampleTasks(t *testing.T) {
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand: "gotestsum --jsonfile={{resultPath}} {{packages}}",
+		ResultPath:  "test-results.jsonl",
+	})
+
+	testCases := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/split", Name: "TestSlow", Path: "example.com/hello/split"},
+		{Format: plan.TestCaseFormatExample, Scope: "example.com/hello/split", Name: "TestFast", Path: "example.com/hello/split"},
+	}
+
+	_, gotArgs, err := gotest.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("GoTest.CommandNameAndArgs() error = %v", err)
+	}
+
+	wantArgs := []string{
+		"--jsonfile=test-results.jsonl",
+		"example.com/hello/split",
+		"-run",
+		"^(TestFast|TestSlow)$",
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("GoTest.CommandNameAndArgs() args diff (-got +want):\n%s", diff)
+	}
+}
+
 func TestGotestCommandNameAndArgs_SelectorTasks(t *testing.T) {
 	gotest := NewGoTest(RunnerConfig{
 		TestCommand: "gotestsum --junitfile={{resultPath}} {{packages}}",
@@ -461,6 +659,133 @@ func TestGotestCommandNameAndArgs_PathTasksStillWork(t *testing.T) {
 	}
 }
 
+func TestGotestCommandNameAndArgs_RetryFailedTests(t *testing.T) {
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand: "gotestsum --jsonfile={{resultPath}} {{packages}}",
+		ResultPath:  "test-results.jsonl",
+	})
+
+	testCases := []plan.TestCase{
+		{Scope: "example.com/hello", Name: "TestHello/subtest", Path: "example.com/hello"},
+		{Scope: "example.com/hello", Name: "TestHello", Path: "example.com/hello"},
+		{Scope: "example.com/hello", Name: "TestBye", Path: "example.com/hello"},
+	}
+
+	gotName, gotArgs, err := gotest.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Fatalf("GoTest.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "gotestsum" {
+		t.Errorf("GoTest.CommandNameAndArgs() name = %q, want %q", gotName, "gotestsum")
+	}
+
+	wantArgs := []string{
+		"--jsonfile=test-results.jsonl",
+		"example.com/hello",
+		"-run",
+		"^(TestBye|TestHello)$",
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("GoTest.CommandNameAndArgs() args diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGotestCommandNameAndArgs_RetryPackageLevelFailure(t *testing.T) {
+	gotest := NewGoTest(RunnerConfig{
+		TestCommand: "go test -json {{packages}}",
+		ResultPath:  "test-results.jsonl",
+	})
+
+	testCases := []plan.TestCase{
+		{Scope: "example.com/hello", Name: "TestMain", Path: "example.com/hello"},
+	}
+
+	_, gotArgs, err := gotest.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Fatalf("GoTest.CommandNameAndArgs() error = %v", err)
+	}
+
+	wantArgs := []string{"test", "-json", "example.com/hello"}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("GoTest.CommandNameAndArgs() args diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGoTestRunPattern(t *testing.T) {
+	tests := []struct {
+		name      string
+		testCases []plan.TestCase
+		want      string
+	}{
+		{
+			name:      "single test",
+			testCases: []plan.TestCase{{Name: "TestHello"}},
+			want:      "^TestHello$",
+		},
+		{
+			name:      "subtests run their parent function",
+			testCases: []plan.TestCase{{Name: "TestHello/with_name"}, {Name: "TestHello/without_name"}},
+			want:      "^TestHello$",
+		},
+		{
+			name:      "multiple tests",
+			testCases: []plan.TestCase{{Name: "TestWorld"}, {Name: "TestHello"}},
+			want:      "^(TestHello|TestWorld)$",
+		},
+		{
+			name:      "package-level failure",
+			testCases: []plan.TestCase{{Name: "TestHello"}, {Name: "TestMain"}},
+			want:      "",
+		},
+		{
+			name:      "selector",
+			testCases: []plan.TestCase{{Format: plan.TestCaseFormatSelector, Value: "example.com/hello"}},
+			want:      "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := goTestRunPattern(tc.testCases)
+			if got != tc.want {
+				t.Errorf("goTestRunPattern() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGoTestRunGroups(t *testing.T) {
+	testCases := []plan.TestCase{
+		{Name: "TestA", Path: "example.com/one"},
+		{Name: "TestB", Path: "example.com/two"},
+		{Name: "TestA", Path: "example.com/three"},
+		{Name: "TestB", Path: "example.com/one"},
+		{Name: "TestA", Path: "example.com/two"},
+		{Name: "TestC", Path: "example.com/four"},
+	}
+
+	got := goTestRunGroups(testCases)
+
+	want := [][]plan.TestCase{
+		{
+			{Name: "TestA", Path: "example.com/one"},
+			{Name: "TestB", Path: "example.com/one"},
+			{Name: "TestB", Path: "example.com/two"},
+			{Name: "TestA", Path: "example.com/two"},
+		},
+		{
+			{Name: "TestA", Path: "example.com/three"},
+		},
+		{
+			{Name: "TestC", Path: "example.com/four"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("goTestRunGroups() diff (-want +got):\n%s", diff)
+	}
+}
+
 func getRandomXMLTempFilename() string {
 	tempDir, err := os.MkdirTemp("", "bktec-*")
 	if err != nil {
@@ -0,0 +1,166 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// Gradle is a runner for JVM tests run with Gradle, such as JUnit tests written in Java or Kotlin.
+//
+// Tests are split by test file, and each file is run as its test class with a --tests filter
+// of the test task of its project. Gradle writes a JUnit report per test class, so ResultPath is a glob.
+type Gradle struct {
+	RunnerConfig
+}
+
+func NewGradle(g RunnerConfig) Gradle {
+	if g.TestCommand == "" {
+		g.TestCommand = "./gradlew --continue {{testExamples}}"
+	}
+
+	if g.TestFilePattern == "" {
+		g.TestFilePattern = "**/src/test/**/*Test.{kt,java}"
+	}
+
+	if g.RetryTestCommand == "" {
+		g.RetryTestCommand = g.TestCommand
+	}
+
+	if g.ResultPath == "" {
+		g.ResultPath = "**/build/test-results/**/TEST-*.xml"
+	}
+
+	return Gradle{
+		RunnerConfig: g,
+	}
+}
+
+func (g Gradle) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     true,
+		SplitByExample:  false,
+		FilterTestFiles: true,
+		FilterTestByTag: false,
+		AutoRetry:       true,
+		Mute:            true,
+		Skip:            false,
+		SplitBySelector: true,
+	}
+}
+
+func (g Gradle) Name() string {
+	return "Gradle"
+}
+
+// DiscoverTestTargets returns file names using the discovery pattern.
+func (g Gradle) DiscoverTestTargets() ([]string, error) {
+	debug.Println("Discovering test files with include pattern:", g.TestFilePattern, "exclude pattern:", g.TestFileExcludePattern)
+	files, err := discoverTestFiles(g.TestFilePattern, g.TestFileExcludePattern)
+	debug.Println("Discovered", len(files), "files")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found with pattern %q and exclude pattern %q", g.TestFilePattern, g.TestFileExcludePattern)
+	}
+
+	return files, nil
+}
+
+func (g Gradle) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(g, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := removeResultFiles(g.ResultPath); err != nil {
+		return err
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// Gradle exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the reports even if the command returns an error.
+	if parseErr := parseJVMResults(result, g.ResultPath, testCases); parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read Gradle output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the reports,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	// Return any command error after processing the reports
+	return cmdErr
+}
+
+// CommandNameAndArgs replaces the "{{testExamples}}" placeholder in the test command with the test task
+// of each project, followed by a --tests filter for each of its test classes, e.g.
+// ":services:api:test --tests com.example.UserTest". On retry, the filters select the failed test
+// methods, e.g. "--tests com.example.UserTest.savesTheUser".
+//
+// The project of a test file is the directory before src/test, so the Gradle project paths must
+// follow the directory layout, which is the default.
+func (g Gradle) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := g.TestCommand
+	if retry {
+		cmd = g.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	tasks := gradleTestTasks(testCases)
+	idx := slices.Index(words, "{{testExamples}}")
+	if idx < 0 {
+		words = append(words, tasks...)
+	} else {
+		words = slices.Replace(words, idx, idx+1, tasks...)
+	}
+
+	return words[0], words[1:], nil
+}
+
+// gradleTestTasks returns the test task of each project of the test cases with its --tests filters,
+// in the order the projects first appear.
+func gradleTestTasks(testCases []plan.TestCase) []string {
+	var modules []string
+	moduleFilters := map[string][]string{}
+	for i, path := range pathsFromTestCases(testCases) {
+		module, class := jvmTestClassFromPath(path)
+		filter := class
+		if testCase := testCases[i]; testCase.Name != "" {
+			filter = fmt.Sprintf("%s.%s", testCase.Scope, jvmTestMethod(testCase.Name))
+		}
+
+		if _, ok := moduleFilters[module]; !ok {
+			modules = append(modules, module)
+		}
+		if !slices.Contains(moduleFilters[module], filter) {
+			moduleFilters[module] = append(moduleFilters[module], filter)
+		}
+	}
+
+	var words []string
+	for _, module := range modules {
+		// A task name without a project path would run in every project that has the task.
+		task := ":test"
+		if module != "" {
+			task = fmt.Sprintf(":%s:test", strings.ReplaceAll(module, "/", ":"))
+		}
+
+		words = append(words, task)
+		for _, filter := range moduleFilters[module] {
+			words = append(words, "--tests", filter)
+		}
+	}
+	return words
+}
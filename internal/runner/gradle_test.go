@@ -0,0 +1,256 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// gradleTestReports is a test command that writes the reports of the fixture to the build
+// directory of each project, like Gradle does, and fails.
+const gradleTestReports = `sh -c '` +
+	`mkdir -p app/build/test-results/test lib/core/build/test-results/test && ` +
+	`cp reports/TEST-com.acme.app.*.xml app/build/test-results/test/ && ` +
+	`cp reports/TEST-com.acme.core.*.xml lib/core/build/test-results/test/; ` +
+	`exit 1'`
+
+func removeGradleBuildDirs(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		os.RemoveAll("app/build")
+		os.RemoveAll("lib/core/build")
+	})
+}
+
+func TestGradleRun(t *testing.T) {
+	changeCwd(t, "./testdata/gradle")
+	removeGradleBuildDirs(t)
+
+	// Stale reports from a previous attempt are removed before the run.
+	if err := os.MkdirAll("app/build/test-results/test", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("app/build/test-results/test/TEST-com.acme.app.StaleTest.xml", []byte("not xml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gradle := NewGradle(RunnerConfig{
+		TestCommand: gradleTestReports,
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "app/src/test/kotlin/com/acme/app/UserTest.kt"},
+		{Path: "lib/core/src/test/java/com/acme/core/MoneyTest.java"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gradle.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Gradle.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	want := []plan.TestCase{
+		{
+			Scope: "com.acme.app.UserTest",
+			Name:  "validatesTheEmail()",
+			Path:  "app/src/test/kotlin/com/acme/app/UserTest.kt",
+		},
+	}
+	if diff := cmp.Diff(want, result.FailedTests()); diff != "" {
+		t.Errorf("Gradle.Run(%q) RunResult.FailedTests() diff (-want +got):\n%s", testCases, diff)
+	}
+
+	stats := result.Statistics()
+	if stats.Total != 6 || stats.PassedOnFirstRun != 4 || stats.Skipped != 1 {
+		t.Errorf("Gradle.Run(%q) RunResult.Statistics() = %+v, want 6 tests with 4 passed and 1 skipped", testCases, stats)
+	}
+
+	// Tests of nested classes are recorded against the file of their top-level class.
+	nested := result.tests["com.acme.app.UserTest$Validation/rejectsBlankNames()/app/src/test/kotlin/com/acme/app/UserTest.kt"]
+	if nested == nil {
+		t.Errorf("Gradle.Run(%q) didn't record rejectsBlankNames() of UserTest.kt", testCases)
+	}
+
+	failed := result.tests["com.acme.app.UserTest/validatesTheEmail()/app/src/test/kotlin/com/acme/app/UserTest.kt"]
+	if failed == nil {
+		t.Fatalf("Gradle.Run(%q) didn't record validatesTheEmail()", testCases)
+	}
+	if failed.Duration() != 29*time.Millisecond {
+		t.Errorf("validatesTheEmail() Duration() = %v, want %v", failed.Duration(), 29*time.Millisecond)
+	}
+	if failure := failed.Failure(); failure == nil || failure.Exception != "org.opentest4j.AssertionFailedError" {
+		t.Errorf("validatesTheEmail() Failure() = %+v, want the org.opentest4j.AssertionFailedError", failure)
+	}
+}
+
+func TestGradleRun_Retry(t *testing.T) {
+	changeCwd(t, "./testdata/gradle")
+	removeGradleBuildDirs(t)
+
+	gradle := NewGradle(RunnerConfig{
+		RetryTestCommand: gradleTestReports,
+	})
+
+	// Failed tests of a previous attempt are looked up by their class.
+	testCases := []plan.TestCase{
+		{
+			Scope: "com.acme.app.UserTest",
+			Name:  "validatesTheEmail()",
+			Path:  "app/src/test/kotlin/com/acme/app/UserTest.kt",
+		},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gradle.Run(result, testCases, true)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if diff := cmp.Diff(testCases, result.FailedTests()); diff != "" {
+		t.Errorf("Gradle.Run(%q) RunResult.FailedTests() diff (-want +got):\n%s", testCases, diff)
+	}
+}
+
+func TestGradleRun_CommandFailed(t *testing.T) {
+	changeCwd(t, "./testdata/gradle")
+
+	gradle := NewGradle(RunnerConfig{
+		TestCommand: "sh -c 'exit 1'",
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "app/src/test/kotlin/com/acme/app/UserTest.kt"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := gradle.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("Gradle.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
+func TestGradleCommandNameAndArgs(t *testing.T) {
+	gradle := NewGradle(RunnerConfig{})
+
+	testCases := []plan.TestCase{
+		{Path: "app/src/test/kotlin/com/acme/app/UserTest.kt"},
+		{Path: "lib/core/src/test/java/com/acme/core/MoneyTest.java"},
+		{Path: "src/test/java/com/acme/ApplicationTest.java"},
+		{Path: "app/src/test/kotlin/com/acme/app/PostTest.kt"},
+	}
+
+	gotName, gotArgs, err := gradle.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("Gradle.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "./gradlew" {
+		t.Errorf("Gradle.CommandNameAndArgs() name = %q, want %q", gotName, "./gradlew")
+	}
+
+	wantArgs := []string{
+		"--continue",
+		":app:test", "--tests", "com.acme.app.UserTest", "--tests", "com.acme.app.PostTest",
+		":lib:core:test", "--tests", "com.acme.core.MoneyTest",
+		":test", "--tests", "com.acme.ApplicationTest",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Gradle.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGradleCommandNameAndArgs_Retry(t *testing.T) {
+	gradle := NewGradle(RunnerConfig{
+		RetryTestCommand: "./gradlew {{testExamples}} --rerun",
+	})
+
+	testCases := []plan.TestCase{
+		{
+			Scope: "com.acme.app.UserTest",
+			Name:  "validatesTheEmail()",
+			Path:  "app/src/test/kotlin/com/acme/app/UserTest.kt",
+		},
+		{
+			Scope: "com.acme.app.UserTest$Validation",
+			Name:  "rejectsBlankNames()",
+			Path:  "app/src/test/kotlin/com/acme/app/UserTest.kt",
+		},
+		{
+			Scope: "com.acme.core.MoneyTest",
+			Name:  "roundsHalfUp(String)[1]",
+			Path:  "lib/core/src/test/java/com/acme/core/MoneyTest.java",
+		},
+		{
+			Scope: "com.acme.core.MoneyTest",
+			Name:  "roundsHalfUp(String)[2]",
+			Path:  "lib/core/src/test/java/com/acme/core/MoneyTest.java",
+		},
+	}
+
+	gotName, gotArgs, err := gradle.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Fatalf("Gradle.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "./gradlew" {
+		t.Errorf("Gradle.CommandNameAndArgs() name = %q, want %q", gotName, "./gradlew")
+	}
+
+	wantArgs := []string{
+		":app:test", "--tests", "com.acme.app.UserTest.validatesTheEmail", "--tests", "com.acme.app.UserTest$Validation.rejectsBlankNames",
+		":lib:core:test", "--tests", "com.acme.core.MoneyTest.roundsHalfUp",
+		"--rerun",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Gradle.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGradleCommandNameAndArgs_WithoutPlaceholder(t *testing.T) {
+	gradle := NewGradle(RunnerConfig{
+		TestCommand: "./gradlew --offline",
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "app/src/test/kotlin/com/acme/app/UserTest.kt"},
+	}
+
+	_, gotArgs, err := gradle.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("Gradle.CommandNameAndArgs() error = %v", err)
+	}
+
+	wantArgs := []string{"--offline", ":app:test", "--tests", "com.acme.app.UserTest"}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Gradle.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGradleDiscoverTestTargets(t *testing.T) {
+	changeCwd(t, "./testdata/gradle")
+
+	gradle := NewGradle(RunnerConfig{})
+
+	got, err := gradle.DiscoverTestTargets()
+	if err != nil {
+		t.Fatalf("Gradle.DiscoverTestTargets() error = %v", err)
+	}
+
+	want := []string{
+		"app/src/test/kotlin/com/acme/app/UserTest.kt",
+		"lib/core/src/test/java/com/acme/core/MoneyTest.java",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Gradle.DiscoverTestTargets() diff (-want +got):\n%s", diff)
+	}
+}
@@ -75,64 +75,83 @@ func (j Jest) DiscoverTestTargets() ([]string, error) {
 }
 
 func (j Jest) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(j, testCases, retry)
+	cmd, cleanup, err := buildCommand(j, testCases, retry)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
+
+	// Reports from a previous attempt would otherwise be read again, and tests
+	// that are not part of this attempt would be counted twice.
+	if err := removeResultFiles(j.ResultPath); err != nil {
+		return err
+	}
 
 	cmdErr := runAndForwardSignal(cmd)
 
 	// Jest exits with a non-zero status code when there are test failures,
-	// so we should always attempt to parse the report even if the command returns an error.
-	report, parseErr := j.ParseReport(j.ResultPath)
+	// so we should always attempt to parse the reports even if the command returns an error.
+	files, parseErr := ResultFiles(j.ResultPath)
+	if parseErr == nil && len(files) == 0 {
+		parseErr = fmt.Errorf("no result files found matching %q", j.ResultPath)
+	}
 	if parseErr != nil {
 		fmt.Printf("Buildkite Test Engine Client: Failed to read Jest output, tests will not be retried: %v\n", parseErr)
-		// We don't want to fail the build if we fail to parse the report,
-		// therefore we return the command error (which can be nil), instead of the parse error.
 		return cmdErr
 	}
 
-	for _, testResult := range report.TestResults {
-		// TestResult represents a test file result, while AssertionResult represents the individual test cases result.
-		// When a TestResult has status "failed" but has no AssertionResults, it indicates a runtime error at the file level.
-		if testResult.Status == "failed" && len(testResult.AssertionResults) == 0 {
-			result.error = fmt.Errorf("Jest failed with runtime error test suites")
+	// A glob result path matches several reports, e.g. one per project of a multi-project setup.
+	for _, file := range files {
+		report, parseErr := j.ParseReport(file)
+		if parseErr != nil {
+			fmt.Printf("Buildkite Test Engine Client: Failed to read Jest output, tests will not be retried: %v\n", parseErr)
+			// We don't want to fail the build if we fail to parse the report,
+			// therefore we return the command error (which can be nil), instead of the parse error.
+			return cmdErr
 		}
 
-		for _, example := range testResult.AssertionResults {
-			var status TestStatus
-			switch example.Status {
-			case "failed":
-				status = TestStatusFailed
-			case "passed":
-				status = TestStatusPassed
-			case "pending":
-				status = TestStatusSkipped
-			case "todo":
-				status = TestStatusSkipped
-			default:
-				status = TestStatusUnknown
-			}
-
-			wordDir, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("failed to get current working directory: %v", err)
-			}
-			testPath, err := filepath.Rel(wordDir, testResult.FileName)
-			if err != nil {
-				return fmt.Errorf("failed to get relative path of test file: %v", err)
+		for _, testResult := range report.TestResults {
+			// TestResult represents a test file result, while AssertionResult represents the individual test cases result.
+			// When a TestResult has status "failed" but has no AssertionResults, it indicates a runtime error at the file level.
+			if testResult.Status == "failed" && len(testResult.AssertionResults) == 0 {
+				result.error = fmt.Errorf("Jest failed with runtime error test suites")
 			}
 
-			// The scope and name has to match with the scope generated by Buildkite test collector.
-			// For more details, see:
-			// [Buildkite Test Collector - Jest implementation](https://github.com/buildkite/test-collector-javascript/blob/42b803a618a15a07edf0169038ef4b5eba88f98d/jest/reporter.js#L40)
-			testCase := plan.TestCase{
-				Name:  example.Title,
-				Scope: strings.Join(example.AncestorTitles, " "),
-				Path:  testPath,
+			for _, example := range testResult.AssertionResults {
+				var status TestStatus
+				switch example.Status {
+				case "failed":
+					status = TestStatusFailed
+				case "passed":
+					status = TestStatusPassed
+				case "pending":
+					status = TestStatusSkipped
+				case "todo":
+					status = TestStatusSkipped
+				default:
+					status = TestStatusUnknown
+				}
+
+				wordDir, err := os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get current working directory: %v", err)
+				}
+				testPath, err := filepath.Rel(wordDir, testResult.FileName)
+				if err != nil {
+					return fmt.Errorf("failed to get relative path of test file: %v", err)
+				}
+
+				// The scope and name has to match with the scope generated by Buildkite test collector.
+				// For more details, see:
+				// [Buildkite Test Collector - Jest implementation](https://github.com/buildkite/test-collector-javascript/blob/42b803a618a15a07edf0169038ef4b5eba88f98d/jest/reporter.js#L40)
+				testCase := plan.TestCase{
+					Name:  example.Title,
+					Scope: strings.Join(example.AncestorTitles, " "),
+					Path:  testPath,
+				}
+
+				result.RecordTestAttempt(testCase, example.attempt(status))
 			}
-
-			result.RecordTestResult(testCase, status)
 		}
 	}
 
@@ -149,6 +168,23 @@ type JestExample struct {
 		Line   int
 		Column int
 	}
+	// Duration is the duration of the test in milliseconds.
+	Duration        float64  `json:"duration"`
+	FailureMessages []string `json:"failureMessages"`
+}
+
+// attempt returns the result of the example with the given status, including its duration and failure.
+// Each failure message is an error message followed by its stack trace, and an example can have several
+// when it fails more than one assertion or hook. The first one is reported, as that is usually the cause.
+func (e JestExample) attempt(status TestStatus) TestAttempt {
+	attempt := TestAttempt{
+		Status:   status,
+		Duration: millisecondsToDuration(e.Duration),
+	}
+	if len(e.FailureMessages) > 0 {
+		attempt.Failure = parseTestFailure("", e.FailureMessages[0])
+	}
+	return attempt
 }
 
 type JestReport struct {
@@ -180,6 +216,12 @@ func ParseJestReport(path string) (JestReport, error) {
 	return report, nil
 }
 
+func (j Jest) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	testPaths := pathsFromTestCases(testCases)
+	slices.Sort(testPaths)
+	return slices.Compact(testPaths)
+}
+
 func (j Jest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
 	cmd := j.TestCommand
 	if retry {
@@ -191,9 +233,7 @@ func (j Jest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string,
 		return "", []string{}, err
 	}
 
-	testPaths := pathsFromTestCases(testCases)
-	slices.Sort(testPaths)
-	testPaths = slices.Compact(testPaths)
+	testPaths := j.testListPaths(testCases, retry)
 
 	if retry {
 		idx := slices.Index(words, "{{testNamePattern}}")
@@ -217,14 +257,24 @@ func (j Jest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string,
 		}
 	} else {
 		idx := slices.Index(words, "{{testExamples}}")
-		if idx < 0 {
-			words = append(words, testPaths...)
-		} else {
+		if idx >= 0 {
 			words = slices.Replace(words, idx, idx+1, testPaths...)
+		} else if !hasTestListFile(words) {
+			words = append(words, testPaths...)
 		}
 	}
 
 	outputIdx := slices.Index(words, "{{resultPath}}")
+
+	// A glob result path can't be the output file of Jest, so the command writes the reports matching it,
+	// e.g. with a script running each project of a multi-project setup with its own --outputFile.
+	if isResultPathGlob(j.ResultPath) {
+		if outputIdx >= 0 {
+			return "", []string{}, fmt.Errorf("'{{resultPath}}' can't be replaced with the glob result path %q, the command must write the reports matching it", j.ResultPath)
+		}
+		return words[0], words[1:], nil
+	}
+
 	if outputIdx < 0 {
 		err := fmt.Errorf("couldn't find '{{resultPath}}' sentinel in command, exiting")
 		return "", []string{}, err
@@ -1,12 +1,15 @@
 package runner
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/buildkite/test-engine-client/v3/internal/plan"
 	"github.com/google/go-cmp/cmp"
@@ -624,3 +627,92 @@ func TestJestDiscoverTestTargets(t *testing.T) {
 		t.Errorf("Jest.DiscoverTestTargets() diff (-got +want):\n%s", diff)
 	}
 }
+
+func TestJestExampleAttempt(t *testing.T) {
+	var example JestExample
+	err := json.Unmarshal([]byte(`{
+		"title": "adds numbers",
+		"status": "failed",
+		"duration": 12.5,
+		"failureMessages": [
+			"Error: expect(received).toBe(expected)\n\nExpected: 3\nReceived: 2\n    at Object.<anonymous> (src/math.spec.js:4:15)",
+			"Error: afterEach hook failed"
+		]
+	}`), &example)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := TestAttempt{
+		Status:   TestStatusFailed,
+		Duration: 12500 * time.Microsecond,
+		Failure: &TestFailure{
+			Message:   "Error: expect(received).toBe(expected)\n\nExpected: 3\nReceived: 2",
+			Backtrace: []string{"at Object.<anonymous> (src/math.spec.js:4:15)"},
+		},
+	}
+	if diff := cmp.Diff(want, example.attempt(TestStatusFailed)); diff != "" {
+		t.Errorf("JestExample.attempt() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestJestRun_ResultGlob(t *testing.T) {
+	changeCwd(t, "./testdata/jest")
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd() error = %v", err)
+	}
+
+	// Reports of a multi-project setup, one per project.
+	reportDir := t.TempDir()
+	reports := map[string]string{
+		"unit.json":        `{"testResults": [{"name": %q, "status": "passed", "assertionResults": [{"ancestorTitles": ["expelliarmus"], "title": "disarms the opponent", "status": "passed"}]}]}`,
+		"integration.json": `{"testResults": [{"name": %q, "status": "failed", "assertionResults": [{"ancestorTitles": ["this will fail"], "title": "for sure", "status": "failed"}]}]}`,
+	}
+	paths := map[string]string{
+		"unit.json":        filepath.Join(cwd, "spells/expelliarmus.spec.js"),
+		"integration.json": filepath.Join(cwd, "failure.spec.js"),
+	}
+	for name, report := range reports {
+		if err := os.WriteFile(filepath.Join(reportDir, name), []byte(fmt.Sprintf(report, paths[name])), 0644); err != nil {
+			t.Fatalf("os.WriteFile(%q) error = %v", name, err)
+		}
+	}
+
+	resultDir := t.TempDir()
+	jest := NewJest(RunnerConfig{
+		TestCommand: fmt.Sprintf(`sh -c 'cp "$0"/*.json "$1"; exit 1' %s %s`, reportDir, resultDir),
+		ResultPath:  filepath.Join(resultDir, "*.json"),
+	})
+
+	testCases := []plan.TestCase{{Path: "spells/expelliarmus.spec.js"}, {Path: "failure.spec.js"}}
+	result := NewRunResult([]plan.TestCase{})
+	err = jest.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if got := result.Statistics().Total; got != 2 {
+		t.Errorf("Jest.Run(%q) RunResult.Statistics().Total = %d, want %d", testCases, got, 2)
+	}
+
+	want := []plan.TestCase{{Scope: "this will fail", Name: "for sure", Path: "failure.spec.js"}}
+	if diff := cmp.Diff(result.FailedTests(), want); diff != "" {
+		t.Errorf("Jest.Run(%q) RunResult.FailedTests() diff (-got +want):\n%s", testCases, diff)
+	}
+}
+
+func TestJestCommandNameAndArgs_ResultGlobWithResultPathPlaceholder(t *testing.T) {
+	testCases := []plan.TestCase{{Path: "spec/user.spec.js"}}
+	testCommand := "jest {{testExamples}} --outputFile {{resultPath}}"
+
+	jest := NewJest(RunnerConfig{
+		TestCommand: testCommand,
+		ResultPath:  "tmp/jest-*.json",
+	})
+
+	_, _, err := jest.CommandNameAndArgs(testCases, false)
+	if err == nil {
+		t.Errorf("commandNameAndArgs(%q, %q) error = nil, want an error", testCases, testCommand)
+	}
+}
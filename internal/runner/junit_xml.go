@@ -1,20 +1,35 @@
 package runner
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // JUnitXMLTestCase represents a single <testcase> element in JUnit XML.
 type JUnitXMLTestCase struct {
-	Classname string           `xml:"classname,attr"`
-	Name      string           `xml:"name,attr"`
-	Result    TestStatus       // passed | failed | skipped
-	Failure   *JUnitXMLFailure `xml:"failure"`
-	Error     *JUnitXMLError   `xml:"error"`
-	Skipped   *JUnitXMLSkipped `xml:"skipped"`
+	Classname string `xml:"classname,attr"`
+	// Class is the class attribute of the <testcase> element, as written by PHPUnit.
+	// Unlike Classname, its namespace separators are not replaced with dots.
+	Class string `xml:"class,attr"`
+	Name  string `xml:"name,attr"`
+	// File is the file attribute of the <testcase> element, or of the
+	// enclosing <testsuite> element when the testcase doesn't have one.
+	File string `xml:"file,attr"`
+	// Line is the lineno attribute of the <testcase> element, as written by
+	// minitest-reporters. Most reporters don't write it.
+	Line string `xml:"lineno,attr"`
+	// Time is the duration of the test in seconds. It is kept as a string,
+	// as some reporters leave it empty.
+	Time    string           `xml:"time,attr"`
+	Result  TestStatus       // passed | failed | skipped
+	Failure *JUnitXMLFailure `xml:"failure"`
+	Error   *JUnitXMLError   `xml:"error"`
+	Skipped *JUnitXMLSkipped `xml:"skipped"`
 	// SuiteName is the name attribute of the enclosing <testsuite> element.
 	SuiteName string `xml:"-"`
 }
@@ -38,10 +53,53 @@ type JUnitXMLSkipped struct {
 	Message string `xml:"message,attr"`
 }
 
+// Attempt returns the result of the testcase, including its duration and failure.
+func (tc JUnitXMLTestCase) Attempt() TestAttempt {
+	attempt := TestAttempt{Status: tc.Result}
+	if seconds, err := strconv.ParseFloat(tc.Time, 64); err == nil {
+		attempt.Duration = secondsToDuration(seconds)
+	}
+
+	switch {
+	case tc.Failure != nil:
+		attempt.Failure = junitXMLTestFailure(tc.Failure.Type, tc.Failure.Message, tc.Failure.Content)
+	case tc.Error != nil:
+		attempt.Failure = junitXMLTestFailure(tc.Error.Type, tc.Error.Message, tc.Error.Content)
+	}
+	return attempt
+}
+
+// junitXMLTestFailure builds a TestFailure from a <failure> or <error> element.
+// The content of the element is the stack trace or the output of the test,
+// and the message attribute is the error message. When the message is missing,
+// the first line of the content is used instead.
+func junitXMLTestFailure(exception, message, content string) *TestFailure {
+	var backtrace []string
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		if line = strings.TrimRight(line, " \t\r"); line != "" {
+			backtrace = append(backtrace, line)
+		}
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" && len(backtrace) > 0 {
+		message = strings.TrimSpace(backtrace[0])
+	}
+
+	if message == "" && exception == "" && len(backtrace) == 0 {
+		return nil
+	}
+	return &TestFailure{Message: message, Exception: exception, Backtrace: backtrace}
+}
+
 type junitXMLTestSuite struct {
 	XMLName   xml.Name           `xml:"testsuite"`
 	Name      string             `xml:"name,attr"`
+	File      string             `xml:"file,attr"`
 	TestCases []JUnitXMLTestCase `xml:"testcase"`
+	// TestSuites are the nested <testsuite> elements. PHPUnit nests a suite for
+	// each test class, and for each test method with a data provider.
+	TestSuites []junitXMLTestSuite `xml:"testsuite"`
 }
 
 type junitXMLTestSuites struct {
@@ -49,6 +107,50 @@ type junitXMLTestSuites struct {
 	TestSuites []junitXMLTestSuite `xml:"testsuite"`
 }
 
+// mergeJUnitXMLReports merges the reports into a single report, with the <testsuite>
+// elements of every report under one <testsuites> root element.
+func mergeJUnitXMLReports(reports [][]byte) ([]byte, error) {
+	var merged bytes.Buffer
+	merged.WriteString(xml.Header + "<testsuites>\n")
+
+	for _, report := range reports {
+		decoder := xml.NewDecoder(bytes.NewReader(report))
+		for {
+			offset := decoder.InputOffset()
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JUnit XML report: %w", err)
+			}
+
+			start, ok := token.(xml.StartElement)
+			if !ok {
+				continue
+			}
+
+			// A report with a single <testsuite> root element is kept whole.
+			if start.Name.Local == "testsuite" {
+				if err := decoder.Skip(); err != nil {
+					return nil, fmt.Errorf("failed to read JUnit XML report: %w", err)
+				}
+				merged.Write(report[offset:decoder.InputOffset()])
+			} else {
+				var testSuites struct {
+					Inner []byte `xml:",innerxml"`
+				}
+				if err := decoder.DecodeElement(&testSuites, &start); err != nil {
+					return nil, fmt.Errorf("failed to read JUnit XML report: %w", err)
+				}
+				merged.Write(bytes.TrimSpace(testSuites.Inner))
+			}
+			merged.WriteString("\n")
+			break
+		}
+	}
+
+	merged.WriteString("</testsuites>\n")
+	return merged.Bytes(), nil
+}
+
 func loadAndParseJUnitXML(path string) ([]JUnitXMLTestCase, error) {
 	xmlFile, err := os.Open(path)
 	if err != nil {
@@ -61,27 +163,61 @@ func loadAndParseJUnitXML(path string) ([]JUnitXMLTestCase, error) {
 		return nil, fmt.Errorf("failed to read JUnit XML file %s: %w", path, err)
 	}
 
+	// Most reports have a <testsuites> root element, but Gradle and Maven Surefire
+	// write a report per test class, with a single <testsuite> root element.
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(byteValue, &root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JUnit XML file %s: %w", path, err)
+	}
+
 	var testSuites junitXMLTestSuites
-	err = xml.Unmarshal(byteValue, &testSuites)
+	if root.XMLName.Local == "testsuite" {
+		var testSuite junitXMLTestSuite
+		err = xml.Unmarshal(byteValue, &testSuite)
+		testSuites.TestSuites = []junitXMLTestSuite{testSuite}
+	} else {
+		err = xml.Unmarshal(byteValue, &testSuites)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JUnit XML file %s: %w", path, err)
 	}
 
 	var results []JUnitXMLTestCase
 	for _, suite := range testSuites.TestSuites {
-		for _, tc := range suite.TestCases {
-			testCase := tc
-			testCase.SuiteName = suite.Name
-			if testCase.Failure != nil || testCase.Error != nil {
-				testCase.Result = TestStatusFailed
-			} else if testCase.Skipped != nil {
-				testCase.Result = TestStatusSkipped
-			} else {
-				testCase.Result = TestStatusPassed
-			}
-			results = append(results, testCase)
-		}
+		results = appendJUnitXMLTestCases(results, suite, "")
 	}
 
 	return results, nil
 }
+
+// appendJUnitXMLTestCases appends the testcases of the suite and its nested suites to results.
+// A testcase without a file attribute takes the file of its nearest enclosing suite that has one.
+func appendJUnitXMLTestCases(results []JUnitXMLTestCase, suite junitXMLTestSuite, file string) []JUnitXMLTestCase {
+	if suite.File != "" {
+		file = suite.File
+	}
+
+	for _, tc := range suite.TestCases {
+		testCase := tc
+		testCase.SuiteName = suite.Name
+		if testCase.File == "" {
+			testCase.File = file
+		}
+		if testCase.Failure != nil || testCase.Error != nil {
+			testCase.Result = TestStatusFailed
+		} else if testCase.Skipped != nil {
+			testCase.Result = TestStatusSkipped
+		} else {
+			testCase.Result = TestStatusPassed
+		}
+		results = append(results, testCase)
+	}
+
+	for _, nested := range suite.TestSuites {
+		results = appendJUnitXMLTestCases(results, nested, file)
+	}
+
+	return results
+}
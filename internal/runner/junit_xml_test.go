@@ -3,6 +3,7 @@ package runner
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -100,3 +101,144 @@ func TestLoadAndParseJUnitXML_Error(t *testing.T) {
 	assert.Nil(t, results[1].Failure)
 	assert.Nil(t, results[1].Error)
 }
+
+const exampleNestedJUnitXML = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite name="Unit" tests="3" failures="0" errors="0">
+		<testsuite name="Tests\Unit\UserTest" file="/app/tests/Unit/UserTest.php" tests="3">
+			<testcase name="testSaves" class="Tests\Unit\UserTest" classname="Tests.Unit.UserTest" file="/app/tests/Unit/UserTest.php" line="9" time="0.001"/>
+			<testsuite name="Tests\Unit\UserTest::testEmail" tests="2">
+				<testcase name="testEmail with data set #0" class="Tests\Unit\UserTest" classname="Tests.Unit.UserTest" time="0.002"/>
+				<testcase name="testEmail with data set #1" class="Tests\Unit\UserTest" classname="Tests.Unit.UserTest" time="0.002"/>
+			</testsuite>
+		</testsuite>
+	</testsuite>
+</testsuites>`
+
+func TestLoadAndParseJUnitXML_NestedTestSuites(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "junit.*.xml")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.WriteString(exampleNestedJUnitXML)
+	require.NoError(t, err)
+	err = tmpfile.Close()
+	require.NoError(t, err)
+
+	results, err := loadAndParseJUnitXML(tmpfile.Name())
+	require.NoError(t, err)
+
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "testSaves", results[0].Name)
+	assert.Equal(t, `Tests\Unit\UserTest`, results[0].Class)
+	assert.Equal(t, "Tests.Unit.UserTest", results[0].Classname)
+	assert.Equal(t, `Tests\Unit\UserTest`, results[0].SuiteName)
+
+	// Testcases of a nested suite without a file take the file of the enclosing suite.
+	assert.Equal(t, "testEmail with data set #1", results[2].Name)
+	assert.Equal(t, "/app/tests/Unit/UserTest.php", results[2].File)
+	assert.Equal(t, `Tests\Unit\UserTest::testEmail`, results[2].SuiteName)
+}
+
+const exampleTestSuiteRootJUnitXML = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="com.example.UserTest" tests="2" skipped="1" failures="0" errors="0" timestamp="2026-10-18T09:00:00" hostname="ci" time="0.012">
+	<properties/>
+	<testcase name="savesTheUser()" classname="com.example.UserTest" time="0.011"/>
+	<testcase name="sendsTheWelcomeEmail()" classname="com.example.UserTest" time="0.001">
+		<skipped/>
+	</testcase>
+	<system-out><![CDATA[]]></system-out>
+	<system-err><![CDATA[]]></system-err>
+</testsuite>`
+
+func TestLoadAndParseJUnitXML_TestSuiteRoot(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "junit.*.xml")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.WriteString(exampleTestSuiteRootJUnitXML)
+	require.NoError(t, err)
+	err = tmpfile.Close()
+	require.NoError(t, err)
+
+	results, err := loadAndParseJUnitXML(tmpfile.Name())
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "savesTheUser()", results[0].Name)
+	assert.Equal(t, "com.example.UserTest", results[0].Classname)
+	assert.Equal(t, "com.example.UserTest", results[0].SuiteName)
+	assert.Equal(t, TestStatusPassed, results[0].Result)
+
+	assert.Equal(t, "sendsTheWelcomeEmail()", results[1].Name)
+	assert.Equal(t, TestStatusSkipped, results[1].Result)
+}
+
+func TestMergeJUnitXMLReports(t *testing.T) {
+	merged, err := mergeJUnitXMLReports([][]byte{[]byte(exampleJUnitXML), []byte(exampleTestSuiteRootJUnitXML)})
+	require.NoError(t, err)
+
+	tmpfile, err := os.CreateTemp("", "junit.*.xml")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.Write(merged)
+	require.NoError(t, err)
+	err = tmpfile.Close()
+	require.NoError(t, err)
+
+	results, err := loadAndParseJUnitXML(tmpfile.Name())
+	require.NoError(t, err)
+
+	require.Len(t, results, 6)
+
+	assert.Equal(t, "TestPrintf", results[0].Name)
+	assert.Equal(t, TestStatusFailed, results[0].Result)
+	assert.Equal(t, "savesTheUser()", results[4].Name)
+	assert.Equal(t, "com.example.UserTest", results[4].SuiteName)
+}
+
+func TestJUnitXMLTestCaseAttempt(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "junit.*.xml")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.WriteString(exampleJUnitXMLWithError)
+	require.NoError(t, err)
+	err = tmpfile.Close()
+	require.NoError(t, err)
+
+	results, err := loadAndParseJUnitXML(tmpfile.Name())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, TestAttempt{
+		Status: TestStatusFailed,
+		Failure: &TestFailure{
+			Message:   "panic in setup",
+			Exception: "RuntimeError",
+			Backtrace: []string{"goroutine 1 [running]: panic..."},
+		},
+	}, results[0].Attempt())
+
+	assert.Equal(t, TestAttempt{Status: TestStatusPassed}, results[1].Attempt())
+}
+
+func TestJUnitXMLTestCaseAttempt_MessageFromContent(t *testing.T) {
+	testCase := JUnitXMLTestCase{
+		Result:  TestStatusFailed,
+		Time:    "1.5",
+		Failure: &JUnitXMLFailure{Content: "\n  expected 1 to equal 2\n  at Context.<anonymous> (test/math.js:3:10)\n"},
+	}
+
+	assert.Equal(t, TestAttempt{
+		Status:   TestStatusFailed,
+		Duration: 1500 * time.Millisecond,
+		Failure: &TestFailure{
+			Message:   "expected 1 to equal 2",
+			Backtrace: []string{"expected 1 to equal 2", "  at Context.<anonymous> (test/math.js:3:10)"},
+		},
+	}, testCase.Attempt())
+}
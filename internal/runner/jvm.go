@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+)
+
+// jvmTestClassFromPath returns the module directory and the fully qualified name of the test class
+// in a test source file, following the Gradle and Maven layout of src/test/<language>/<package>/<Class>.<ext>.
+// For example, "services/api/src/test/kotlin/com/example/UserTest.kt" is the class
+// "com.example.UserTest" in the module "services/api". The module is empty for the root project,
+// and the class is the file name for files outside of src/test.
+func jvmTestClassFromPath(path string) (module string, class string) {
+	path = filepath.ToSlash(path)
+
+	var source string
+	if strings.HasPrefix(path, "src/test/") {
+		source = strings.TrimPrefix(path, "src/test/")
+	} else if i := strings.Index(path, "/src/test/"); i >= 0 {
+		module = path[:i]
+		source = path[i+len("/src/test/"):]
+	} else {
+		// The package can't be derived from a file outside of the standard layout.
+		return "", strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	// The first directory is the language, e.g. "java" or "kotlin".
+	_, source, _ = strings.Cut(source, "/")
+	source = strings.TrimSuffix(source, filepath.Ext(source))
+
+	return module, strings.ReplaceAll(source, "/", ".")
+}
+
+// jvmTestMethod returns the name of the test method of a JUnit testcase name, which is a display name
+// with the parameters of the method and its invocation for JUnit 5, e.g. "validatesTheEmail(String)[1]".
+func jvmTestMethod(name string) string {
+	if i := strings.IndexAny(name, "(["); i > 0 {
+		name = name[:i]
+	}
+	return strings.TrimSpace(name)
+}
+
+// jvmOuterClass returns the top-level class of a class name, without its nested classes, e.g.
+// "com.example.UserTest" for "com.example.UserTest$Validation".
+func jvmOuterClass(class string) string {
+	outer, _, _ := strings.Cut(class, "$")
+	return outer
+}
+
+// jvmTestClassPaths returns the test file of each test class of the test cases.
+// Test cases of a test file are mapped by the class derived from their path, and test cases
+// from a previous attempt by their scope, which is the test class reported by JUnit.
+func jvmTestClassPaths(testCases []plan.TestCase) map[string]string {
+	paths := map[string]string{}
+	for i, path := range pathsFromTestCases(testCases) {
+		if testCases[i].Scope != "" {
+			paths[jvmOuterClass(testCases[i].Scope)] = path
+			continue
+		}
+		_, class := jvmTestClassFromPath(path)
+		paths[class] = path
+	}
+	return paths
+}
+
+// parseJVMResults records the results of the JUnit reports matching resultPath, which Gradle and
+// Maven Surefire write for each test class. The scope of a test is its class and the name is its
+// testcase name. Reports don't include the test file, so it is looked up from the test cases of the run.
+func parseJVMResults(result *RunResult, resultPath string, testCases []plan.TestCase) error {
+	files, err := ResultFiles(resultPath)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no result files found matching %q", resultPath)
+	}
+
+	paths := jvmTestClassPaths(testCases)
+	for _, file := range files {
+		tests, err := loadAndParseJUnitXML(file)
+		if err != nil {
+			return err
+		}
+
+		for _, test := range tests {
+			class := test.Classname
+			if class == "" {
+				class = test.SuiteName
+			}
+
+			result.RecordTestAttempt(plan.TestCase{
+				Scope: class,
+				Name:  test.Name,
+				Path:  paths[jvmOuterClass(class)],
+			}, test.Attempt())
+		}
+	}
+
+	return nil
+}
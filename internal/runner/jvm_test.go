@@ -0,0 +1,56 @@
+package runner
+
+import "testing"
+
+func TestJVMTestClassFromPath(t *testing.T) {
+	cases := []struct {
+		path       string
+		wantModule string
+		wantClass  string
+	}{
+		{
+			path:       "src/test/java/com/acme/UserTest.java",
+			wantModule: "",
+			wantClass:  "com.acme.UserTest",
+		},
+		{
+			path:       "app/src/test/kotlin/com/acme/app/UserTest.kt",
+			wantModule: "app",
+			wantClass:  "com.acme.app.UserTest",
+		},
+		{
+			path:       "services/billing/src/test/java/InvoiceTest.java",
+			wantModule: "services/billing",
+			wantClass:  "InvoiceTest",
+		},
+		{
+			path:       "test/UserTest.java",
+			wantModule: "",
+			wantClass:  "UserTest",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			module, class := jvmTestClassFromPath(tc.path)
+			if module != tc.wantModule || class != tc.wantClass {
+				t.Errorf("jvmTestClassFromPath(%q) = %q, %q, want %q, %q", tc.path, module, class, tc.wantModule, tc.wantClass)
+			}
+		})
+	}
+}
+
+func TestJVMTestMethod(t *testing.T) {
+	cases := map[string]string{
+		"savesTheUser()":          "savesTheUser",
+		"savesTheUser":            "savesTheUser",
+		"roundsHalfUp(String)[1]": "roundsHalfUp",
+		"roundsHalfUp[1]":         "roundsHalfUp",
+	}
+
+	for name, want := range cases {
+		if got := jvmTestMethod(name); got != want {
+			t.Errorf("jvmTestMethod(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// Maven is a runner for JVM tests run with the Maven Surefire plugin.
+//
+// Tests are split by test file, and each file is run as its test class with the -Dtest option of Surefire.
+// Surefire writes a JUnit report per test class, so ResultPath is a glob.
+type Maven struct {
+	RunnerConfig
+}
+
+func NewMaven(m RunnerConfig) Maven {
+	if m.TestCommand == "" {
+		m.TestCommand = "mvn test -Dsurefire.failIfNoSpecifiedTests=false -Dtest={{testExamples}}"
+	}
+
+	if m.TestFilePattern == "" {
+		m.TestFilePattern = "**/src/test/**/*Test.{kt,java}"
+	}
+
+	if m.RetryTestCommand == "" {
+		m.RetryTestCommand = m.TestCommand
+	}
+
+	if m.ResultPath == "" {
+		m.ResultPath = "**/target/surefire-reports/TEST-*.xml"
+	}
+
+	return Maven{
+		RunnerConfig: m,
+	}
+}
+
+func (m Maven) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     true,
+		SplitByExample:  false,
+		FilterTestFiles: true,
+		FilterTestByTag: false,
+		AutoRetry:       true,
+		Mute:            true,
+		Skip:            false,
+		SplitBySelector: true,
+	}
+}
+
+func (m Maven) Name() string {
+	return "Maven"
+}
+
+// DiscoverTestTargets returns file names using the discovery pattern.
+func (m Maven) DiscoverTestTargets() ([]string, error) {
+	debug.Println("Discovering test files with include pattern:", m.TestFilePattern, "exclude pattern:", m.TestFileExcludePattern)
+	files, err := discoverTestFiles(m.TestFilePattern, m.TestFileExcludePattern)
+	debug.Println("Discovered", len(files), "files")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found with pattern %q and exclude pattern %q", m.TestFilePattern, m.TestFileExcludePattern)
+	}
+
+	return files, nil
+}
+
+func (m Maven) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(m, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := removeResultFiles(m.ResultPath); err != nil {
+		return err
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// Maven exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the reports even if the command returns an error.
+	if parseErr := parseJVMResults(result, m.ResultPath, testCases); parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read Maven output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the reports,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	// Return any command error after processing the reports
+	return cmdErr
+}
+
+// CommandNameAndArgs replaces the "{{testExamples}}" placeholder in the test command with a comma
+// separated list of the test classes, e.g. "-Dtest=com.example.UserTest,com.example.PostTest".
+// On retry, the list selects the failed test methods of each class, e.g. "com.example.UserTest#savesTheUser+validatesTheEmail".
+// When the command has no "{{testExamples}}" placeholder, the list is appended with -Dtest.
+func (m Maven) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := m.TestCommand
+	if retry {
+		cmd = m.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	tests := strings.Join(mavenTestFilters(testCases), ",")
+	if !slices.ContainsFunc(words, func(word string) bool { return strings.Contains(word, "{{testExamples}}") }) {
+		words = append(words, "-Dtest="+tests)
+	}
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, "{{testExamples}}", tests)
+	}
+
+	return words[0], words[1:], nil
+}
+
+// mavenTestFilters returns a Surefire test filter for each test class of the test cases,
+// in the order the classes first appear.
+func mavenTestFilters(testCases []plan.TestCase) []string {
+	var classes []string
+	classMethods := map[string][]string{}
+	for i, path := range pathsFromTestCases(testCases) {
+		testCase := testCases[i]
+		_, class := jvmTestClassFromPath(path)
+		if testCase.Name != "" {
+			class = testCase.Scope
+		}
+
+		methods, ok := classMethods[class]
+		if !ok {
+			classes = append(classes, class)
+		}
+		if testCase.Name != "" {
+			if method := jvmTestMethod(testCase.Name); !slices.Contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+		classMethods[class] = methods
+	}
+
+	filters := make([]string, 0, len(classes))
+	for _, class := range classes {
+		filter := class
+		if methods := classMethods[class]; len(methods) > 0 {
+			filter = fmt.Sprintf("%s#%s", class, strings.Join(methods, "+"))
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
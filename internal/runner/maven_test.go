@@ -0,0 +1,170 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMavenRun(t *testing.T) {
+	changeCwd(t, "./testdata/gradle")
+	t.Cleanup(func() {
+		os.RemoveAll("app/target")
+		os.RemoveAll("lib/core/target")
+	})
+
+	// Surefire writes the reports of each module to its target directory.
+	maven := NewMaven(RunnerConfig{
+		TestCommand: `sh -c '` +
+			`mkdir -p app/target/surefire-reports lib/core/target/surefire-reports && ` +
+			`cp reports/TEST-com.acme.app.*.xml app/target/surefire-reports/ && ` +
+			`cp reports/TEST-com.acme.core.*.xml lib/core/target/surefire-reports/; ` +
+			`exit 1'`,
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "app/src/test/kotlin/com/acme/app/UserTest.kt"},
+		{Path: "lib/core/src/test/java/com/acme/core/MoneyTest.java"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := maven.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Maven.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	want := []plan.TestCase{
+		{
+			Scope: "com.acme.app.UserTest",
+			Name:  "validatesTheEmail()",
+			Path:  "app/src/test/kotlin/com/acme/app/UserTest.kt",
+		},
+	}
+	if diff := cmp.Diff(want, result.FailedTests()); diff != "" {
+		t.Errorf("Maven.Run(%q) RunResult.FailedTests() diff (-want +got):\n%s", testCases, diff)
+	}
+
+	if stats := result.Statistics(); stats.Total != 6 {
+		t.Errorf("Maven.Run(%q) RunResult.Statistics() = %+v, want 6 tests", testCases, stats)
+	}
+}
+
+func TestMavenRun_CommandFailed(t *testing.T) {
+	changeCwd(t, "./testdata/gradle")
+
+	maven := NewMaven(RunnerConfig{
+		TestCommand: "sh -c 'exit 1'",
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "app/src/test/kotlin/com/acme/app/UserTest.kt"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := maven.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("Maven.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
+func TestMavenCommandNameAndArgs(t *testing.T) {
+	maven := NewMaven(RunnerConfig{})
+
+	testCases := []plan.TestCase{
+		{Path: "app/src/test/kotlin/com/acme/app/UserTest.kt"},
+		{Path: "lib/core/src/test/java/com/acme/core/MoneyTest.java"},
+	}
+
+	gotName, gotArgs, err := maven.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("Maven.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "mvn" {
+		t.Errorf("Maven.CommandNameAndArgs() name = %q, want %q", gotName, "mvn")
+	}
+
+	wantArgs := []string{
+		"test",
+		"-Dsurefire.failIfNoSpecifiedTests=false",
+		"-Dtest=com.acme.app.UserTest,com.acme.core.MoneyTest",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Maven.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMavenCommandNameAndArgs_Retry(t *testing.T) {
+	maven := NewMaven(RunnerConfig{})
+
+	testCases := []plan.TestCase{
+		{
+			Scope: "com.acme.app.UserTest",
+			Name:  "validatesTheEmail()",
+			Path:  "app/src/test/kotlin/com/acme/app/UserTest.kt",
+		},
+		{
+			Scope: "com.acme.core.MoneyTest",
+			Name:  "roundsHalfUp(String)[1]",
+			Path:  "lib/core/src/test/java/com/acme/core/MoneyTest.java",
+		},
+		{
+			Scope: "com.acme.app.UserTest",
+			Name:  "savesTheUser()",
+			Path:  "app/src/test/kotlin/com/acme/app/UserTest.kt",
+		},
+		{
+			Scope: "com.acme.core.MoneyTest",
+			Name:  "roundsHalfUp(String)[2]",
+			Path:  "lib/core/src/test/java/com/acme/core/MoneyTest.java",
+		},
+	}
+
+	_, gotArgs, err := maven.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Fatalf("Maven.CommandNameAndArgs() error = %v", err)
+	}
+
+	wantArgs := []string{
+		"test",
+		"-Dsurefire.failIfNoSpecifiedTests=false",
+		"-Dtest=com.acme.app.UserTest#validatesTheEmail+savesTheUser,com.acme.core.MoneyTest#roundsHalfUp",
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Maven.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMavenCommandNameAndArgs_WithoutPlaceholder(t *testing.T) {
+	maven := NewMaven(RunnerConfig{
+		TestCommand: "./mvnw -B verify",
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "app/src/test/kotlin/com/acme/app/UserTest.kt"},
+	}
+
+	gotName, gotArgs, err := maven.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("Maven.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "./mvnw" {
+		t.Errorf("Maven.CommandNameAndArgs() name = %q, want %q", gotName, "./mvnw")
+	}
+
+	wantArgs := []string{"-B", "verify", "-Dtest=com.acme.app.UserTest"}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Maven.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,333 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+type Minitest struct {
+	RunnerConfig
+}
+
+func (m Minitest) Name() string {
+	return "Minitest"
+}
+
+func NewMinitest(c RunnerConfig) Minitest {
+	if c.TestCommand == "" {
+		c.TestCommand = "bin/rails test {{testExamples}}"
+	}
+
+	if c.TestFilePattern == "" {
+		c.TestFilePattern = "test/**/*_test.rb"
+	}
+
+	if c.RetryTestCommand == "" {
+		c.RetryTestCommand = "bin/rails test {{testExamples}} -n {{testNamePattern}}"
+	}
+
+	if c.ResultPath == "" {
+		// minitest-reporters' JUnitReporter writes a report for each test class to test/reports by default.
+		c.ResultPath = "test/reports/TEST-*.xml"
+	}
+
+	return Minitest{
+		RunnerConfig: c,
+	}
+}
+
+func (m Minitest) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     true,
+		SplitByExample:  true,
+		FilterTestFiles: true,
+		FilterTestByTag: false,
+		AutoRetry:       true,
+		Mute:            true,
+		Skip:            false,
+		SplitBySelector: true,
+	}
+}
+
+// DiscoverTestTargets returns file names using the discovery pattern.
+func (m Minitest) DiscoverTestTargets() ([]string, error) {
+	debug.Println("Discovering test files with include pattern:", m.TestFilePattern, "exclude pattern:", m.TestFileExcludePattern)
+	files, err := discoverTestFiles(m.TestFilePattern, m.TestFileExcludePattern)
+	debug.Println("Discovered", len(files), "files")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found with pattern %q and exclude pattern %q", m.TestFilePattern, m.TestFileExcludePattern)
+	}
+
+	return files, nil
+}
+
+// Run executes the test command with the given test cases, and records the results
+// from the JUnit XML reports written by minitest-reporters' JUnitReporter.
+//
+// JUnitReporter writes a report for each test class, so ResultPath is a glob,
+// and every matching file is read after the run.
+func (m Minitest) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(m, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := removeResultFiles(m.ResultPath); err != nil {
+		return err
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// Minitest exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the reports even if the command returns an error.
+	if parseErr := m.parseResults(result); parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read Minitest output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the reports,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	// Return any command error after processing the reports
+	return cmdErr
+}
+
+func (m Minitest) parseResults(result *RunResult) error {
+	files, err := ResultFiles(m.ResultPath)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no result files found matching %q", m.ResultPath)
+	}
+
+	for _, file := range files {
+		tests, err := loadAndParseJUnitXML(file)
+		if err != nil {
+			return err
+		}
+
+		for _, test := range tests {
+			result.RecordTestAttempt(mapMinitestJUnitToTestCase(test), test.Attempt())
+		}
+	}
+
+	return nil
+}
+
+// mapMinitestJUnitToTestCase maps a testcase of a JUnitReporter report to a test case.
+// The scope is the test class and the name is the test method, the same as the Ruby test collector.
+// The path is the file and line of the test, which `bin/rails test` can run on its own.
+func mapMinitestJUnitToTestCase(test JUnitXMLTestCase) plan.TestCase {
+	path := test.File
+	if test.Line != "" {
+		path = fmt.Sprintf("%s:%s", test.File, test.Line)
+	}
+
+	return plan.TestCase{
+		Identifier: path,
+		Format:     plan.TestCaseFormatExample,
+		Scope:      test.Classname,
+		Name:       test.Name,
+		Path:       path,
+	}
+}
+
+// testListPaths returns the test files, or on retry the files of the failed tests, which are selected
+// by name, so each file only needs to be loaded once.
+func (m Minitest) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	testPaths := pathsFromTestCases(testCases)
+	if retry {
+		return minitestFilePaths(testPaths)
+	}
+	return testPaths
+}
+
+// CommandNameAndArgs replaces the "{{testExamples}}" placeholder in the test command with the test cases.
+//
+// On retry, the "{{testNamePattern}}" placeholder is replaced with a pattern matching the names of
+// the failed tests, such as "/^(test_a|test_b)$/", for the -n option of Minitest, and
+// "{{testExamples}}" with the files of the failed tests.
+func (m Minitest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := m.TestCommand
+	if retry {
+		cmd = m.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	testPaths := m.testListPaths(testCases, retry)
+
+	if retry {
+		idx := slices.Index(words, "{{testNamePattern}}")
+		if idx < 0 {
+			err := fmt.Errorf("couldn't find '{{testNamePattern}}' sentinel in retry command")
+			return "", []string{}, err
+		}
+		words = slices.Replace(words, idx, idx+1, minitestNamePattern(testCases))
+	}
+
+	idx := slices.Index(words, "{{testExamples}}")
+	if idx >= 0 {
+		words = slices.Replace(words, idx, idx+1, testPaths...)
+	} else if !hasTestListFile(words) {
+		words = append(words, testPaths...)
+	}
+
+	return words[0], words[1:], nil
+}
+
+// minitestNamePattern returns a Ruby regular expression matching the names of the test cases exactly.
+func minitestNamePattern(testCases []plan.TestCase) string {
+	names := make([]string, 0, len(testCases))
+	for _, testCase := range testCases {
+		names = append(names, rubyRegexpEscape(testCase.Name))
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	return fmt.Sprintf("/^(%s)$/", strings.Join(names, "|"))
+}
+
+// rubyRegexpEscape escapes the characters that are special in a Ruby regular expression literal,
+// like Ruby's Regexp.escape, as well as the "/" delimiter.
+func rubyRegexpEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '.', '*', '?', '+', '^', '$', '|', '(', ')', '[', ']', '{', '}', '\\', '/', '-', '#', ' ':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// minitestFilePaths returns the unique files of the test paths, without their line numbers.
+func minitestFilePaths(testPaths []string) []string {
+	files := make([]string, 0, len(testPaths))
+	for _, path := range testPaths {
+		if i := strings.LastIndex(path, ":"); i >= 0 {
+			if _, err := strconv.Atoi(path[i+1:]); err == nil {
+				path = path[:i]
+			}
+		}
+		if !slices.Contains(files, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// minitestListScript loads the test files and writes the test methods of every Minitest test class
+// to the file given as the first argument, as JSON. Minitest doesn't have a dry run, so the tests
+// are listed by Minitest itself, the same way it finds the tests to run.
+// It exits without running at_exit hooks, so Minitest.autorun doesn't run the tests.
+const minitestListScript = `
+require "json"
+output = ARGV.shift
+ARGV.each { |file| require File.expand_path(file) }
+tests = Minitest::Runnable.runnables.flat_map do |runnable|
+  runnable.runnable_methods.map do |name|
+    file, line = runnable.instance_method(name).source_location
+    { scope: runnable.name, name: name, file: file, line: line }
+  end
+end
+File.write(output, JSON.generate(tests))
+exit!(0)
+`
+
+// minitestListedTest is a test listed by minitestListScript.
+type minitestListedTest struct {
+	Scope string `json:"scope"`
+	Name  string `json:"name"`
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+}
+
+// GetExamples returns an array of test examples within the given files.
+// The tests are listed with `bundle exec ruby -Itest`, which loads the test files without running them.
+func (m Minitest) GetExamples(files []string) ([]plan.TestCase, error) {
+	if len(files) == 0 {
+		return []plan.TestCase{}, nil
+	}
+
+	// Create a temporary file to store the listed tests.
+	// We cannot simply read them from stdout because test files may print when they are loaded.
+	f, err := os.CreateTemp("", "minitest-list-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file for listing Minitest tests: %w", err)
+	}
+
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	args := append([]string{"exec", "ruby", "-Itest", "-e", minitestListScript, f.Name()}, files...)
+	debug.Printf("Running `bundle exec ruby -Itest` to list the tests in %d files", len(files))
+
+	output, err := exec.Command("bundle", args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Minitest tests: %s", output)
+	}
+
+	return parseMinitestListOutput(f.Name())
+}
+
+// parseMinitestListOutput reads the tests written by minitestListScript.
+// The files are made relative to the working directory, like the test files given to bktec.
+func parseMinitestListOutput(path string) ([]plan.TestCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Minitest test list: %w", err)
+	}
+
+	var tests []minitestListedTest
+	if err := json.Unmarshal(data, &tests); err != nil {
+		return nil, fmt.Errorf("failed to parse Minitest test list: %w", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	testCases := make([]plan.TestCase, 0, len(tests))
+	for _, test := range tests {
+		file := test.File
+		if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+
+		path := fmt.Sprintf("%s:%d", file, test.Line)
+		testCases = append(testCases, plan.TestCase{
+			Identifier: path,
+			Format:     plan.TestCaseFormatExample,
+			Scope:      test.Scope,
+			Name:       test.Name,
+			Path:       path,
+		})
+	}
+
+	return testCases, nil
+}
@@ -0,0 +1,224 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinitestRun(t *testing.T) {
+	changeCwd(t, "./testdata/minitest")
+
+	resultDir := t.TempDir()
+	// Stale reports from a previous attempt are removed before the run.
+	if err := os.WriteFile(filepath.Join(resultDir, "TEST-StaleTest.xml"), []byte("not xml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	minitest := NewMinitest(RunnerConfig{
+		TestCommand: `sh -c 'cp reports/*.xml "$0"' ` + resultDir,
+		ResultPath:  filepath.Join(resultDir, "TEST-*.xml"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/models/user_test.rb"},
+		{Path: "test/models/post_test.rb"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := minitest.Run(result, testCases, false)
+
+	assert.NoError(t, err)
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Minitest.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	want := []plan.TestCase{
+		{
+			Identifier: "test/models/user_test.rb:8",
+			Format:     plan.TestCaseFormatExample,
+			Scope:      "UserTest",
+			Name:       "test_validates_the_email",
+			Path:       "test/models/user_test.rb:8",
+		},
+	}
+	if diff := cmp.Diff(want, result.FailedTests()); diff != "" {
+		t.Errorf("Minitest.Run(%q) RunResult.FailedTests() diff (-want +got):\n%s", testCases, diff)
+	}
+
+	stats := result.Statistics()
+	if stats.Total != 4 || stats.Skipped != 1 {
+		t.Errorf("Minitest.Run(%q) RunResult.Statistics() = %+v, want 4 tests with 1 skipped", testCases, stats)
+	}
+
+	failed := result.tests["UserTest/test_validates_the_email/test/models/user_test.rb:8"]
+	if failed == nil {
+		t.Fatalf("Minitest.Run(%q) didn't record test_validates_the_email", testCases)
+	}
+	if failed.Duration() != 4200*time.Microsecond {
+		t.Errorf("test_validates_the_email Duration() = %v, want %v", failed.Duration(), 4200*time.Microsecond)
+	}
+	if failure := failed.Failure(); failure == nil || failure.Message != "Expected true to be nil or false" || failure.Exception != "Minitest::Assertion" {
+		t.Errorf("test_validates_the_email Failure() = %+v, want the Minitest::Assertion", failure)
+	}
+}
+
+func TestMinitestRun_CommandFailed(t *testing.T) {
+	changeCwd(t, "./testdata/minitest")
+
+	minitest := NewMinitest(RunnerConfig{
+		TestCommand: "sh -c 'exit 1'",
+		ResultPath:  filepath.Join(t.TempDir(), "TEST-*.xml"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/models/user_test.rb"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := minitest.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("Minitest.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
+func TestMinitestCommandNameAndArgs(t *testing.T) {
+	minitest := NewMinitest(RunnerConfig{})
+
+	testCases := []plan.TestCase{
+		{Path: "test/models/user_test.rb"},
+		{Path: "test/models/post_test.rb:8"},
+	}
+
+	gotName, gotArgs, err := minitest.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("Minitest.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "bin/rails" {
+		t.Errorf("Minitest.CommandNameAndArgs() name = %q, want %q", gotName, "bin/rails")
+	}
+
+	wantArgs := []string{"test", "test/models/user_test.rb", "test/models/post_test.rb:8"}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Minitest.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMinitestCommandNameAndArgs_Retry(t *testing.T) {
+	minitest := NewMinitest(RunnerConfig{})
+
+	testCases := []plan.TestCase{
+		{Scope: "UserTest", Name: "test_validates_the_email", Path: "test/models/user_test.rb:8"},
+		{Scope: "UserTest", Name: "test_saves_the_user", Path: "test/models/user_test.rb:4"},
+		{Scope: "PostTest", Name: "test_is_published?", Path: "test/models/post_test.rb:8"},
+	}
+
+	gotName, gotArgs, err := minitest.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Fatalf("Minitest.CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "bin/rails" {
+		t.Errorf("Minitest.CommandNameAndArgs() name = %q, want %q", gotName, "bin/rails")
+	}
+
+	wantArgs := []string{
+		"test",
+		"test/models/user_test.rb",
+		"test/models/post_test.rb",
+		"-n",
+		`/^(test_is_published\?|test_saves_the_user|test_validates_the_email)$/`,
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("Minitest.CommandNameAndArgs() args diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMinitestCommandNameAndArgs_RetryWithoutTestNamePattern(t *testing.T) {
+	minitest := NewMinitest(RunnerConfig{
+		RetryTestCommand: "bin/rails test {{testExamples}}",
+	})
+
+	_, _, err := minitest.CommandNameAndArgs([]plan.TestCase{{Name: "test_a", Path: "test/a_test.rb:1"}}, true)
+	if err == nil {
+		t.Errorf("Minitest.CommandNameAndArgs() error = nil, want an error about the missing {{testNamePattern}}")
+	}
+}
+
+func TestRubyRegexpEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "test_saves_the_user", want: "test_saves_the_user"},
+		{in: "test_is_valid?", want: `test_is_valid\?`},
+		{in: "test_a/b (c)", want: `test_a\/b\ \(c\)`},
+		{in: "test_1.5+2", want: `test_1\.5\+2`},
+	}
+
+	for _, tc := range tests {
+		if got := rubyRegexpEscape(tc.in); got != tc.want {
+			t.Errorf("rubyRegexpEscape(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestMinitestDiscoverTestTargets(t *testing.T) {
+	changeCwd(t, "./testdata/minitest")
+
+	minitest := NewMinitest(RunnerConfig{})
+
+	got, err := minitest.DiscoverTestTargets()
+	if err != nil {
+		t.Fatalf("Minitest.DiscoverTestTargets() error = %v", err)
+	}
+
+	want := []string{"test/models/post_test.rb", "test/models/user_test.rb"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Minitest.DiscoverTestTargets() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseMinitestListOutput(t *testing.T) {
+	changeCwd(t, "./testdata/minitest")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "list.json")
+	list := fmt.Sprintf(`[
+		{"scope": "UserTest", "name": "test_saves_the_user", "file": %q, "line": 4},
+		{"scope": "UserTest", "name": "test_validates_the_email", "file": %q, "line": 8},
+		{"scope": "SharedTest", "name": "test_shared", "file": "/gems/shared/test.rb", "line": 2}
+	]`, filepath.Join(wd, "test/models/user_test.rb"), filepath.Join(wd, "test/models/user_test.rb"))
+	if err := os.WriteFile(path, []byte(list), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseMinitestListOutput(path)
+	if err != nil {
+		t.Fatalf("parseMinitestListOutput() error = %v", err)
+	}
+
+	want := []plan.TestCase{
+		{Identifier: "test/models/user_test.rb:4", Format: plan.TestCaseFormatExample, Scope: "UserTest", Name: "test_saves_the_user", Path: "test/models/user_test.rb:4"},
+		{Identifier: "test/models/user_test.rb:8", Format: plan.TestCaseFormatExample, Scope: "UserTest", Name: "test_validates_the_email", Path: "test/models/user_test.rb:8"},
+		{Identifier: "/gems/shared/test.rb:2", Format: plan.TestCaseFormatExample, Scope: "SharedTest", Name: "test_shared", Path: "/gems/shared/test.rb:2"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseMinitestListOutput() diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,276 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// Mocha is a first-class runner for the Mocha test framework.
+//
+// Results are read from the report of Mocha's built-in JSON reporter, which is written
+// to the result path with the "output" reporter option.
+type Mocha struct {
+	RunnerConfig
+}
+
+func NewMocha(m RunnerConfig) Mocha {
+	if m.TestCommand == "" {
+		m.TestCommand = "npx mocha {{testExamples}} --reporter json --reporter-option output={{resultPath}}"
+	}
+
+	if m.TestFilePattern == "" {
+		m.TestFilePattern = "test/**/*.{js,cjs,mjs,ts}"
+	}
+
+	if m.RetryTestCommand == "" {
+		m.RetryTestCommand = "npx mocha {{testExamples}} --grep '{{testNamePattern}}' --reporter json --reporter-option output={{resultPath}}"
+	}
+
+	return Mocha{
+		RunnerConfig: m,
+	}
+}
+
+func (m Mocha) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     true,
+		SplitByExample:  false,
+		FilterTestFiles: true,
+		FilterTestByTag: false,
+		AutoRetry:       true,
+		Mute:            true,
+		Skip:            false,
+		SplitBySelector: true,
+	}
+}
+
+func (m Mocha) Name() string {
+	return "Mocha"
+}
+
+// DiscoverTestTargets returns file names using the discovery pattern.
+func (m Mocha) DiscoverTestTargets() ([]string, error) {
+	debug.Println("Discovering test files with include pattern:", m.TestFilePattern, "exclude pattern:", m.TestFileExcludePattern)
+	files, err := discoverTestFiles(m.TestFilePattern, m.TestFileExcludePattern)
+	debug.Println("Discovered", len(files), "files")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found with pattern %q and exclude pattern %q", m.TestFilePattern, m.TestFileExcludePattern)
+	}
+
+	return files, nil
+}
+
+func (m Mocha) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(m, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Mocha doesn't write a report when it crashes before running the tests,
+	// in which case the report of a previous attempt or chunk would be read instead.
+	if err := os.Remove(m.ResultPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove previous Mocha result file: %w", err)
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// Mocha exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the report even if the command returns an error.
+	report, parseErr := ParseMochaReport(m.ResultPath)
+	if parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read Mocha output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the report,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %v", err)
+	}
+
+	record := func(tests []MochaTest, status TestStatus) error {
+		for _, test := range tests {
+			// A failing hook is reported as a failure of the hook rather than of a test,
+			// and Mocha skips the rest of the tests of its suite.
+			if mochaHookPattern.MatchString(test.Title) {
+				result.error = fmt.Errorf("Mocha failed with a hook failure: %s", test.FullTitle)
+				continue
+			}
+
+			testPath, err := filepath.Rel(workDir, test.File)
+			if err != nil {
+				return fmt.Errorf("failed to get relative path of test file: %v", err)
+			}
+
+			testCase := plan.TestCase{
+				Name:  test.Title,
+				Scope: test.scope(),
+				Path:  testPath,
+			}
+			result.RecordTestAttempt(testCase, test.attempt(status))
+		}
+		return nil
+	}
+
+	if err := record(report.Passes, TestStatusPassed); err != nil {
+		return err
+	}
+	if err := record(report.Failures, TestStatusFailed); err != nil {
+		return err
+	}
+	if err := record(report.Pending, TestStatusSkipped); err != nil {
+		return err
+	}
+
+	// Return any command error after processing the report
+	return cmdErr
+}
+
+// IsTestFailureExitCode reports whether the exit code can be caused by test failures.
+// Mocha exits with the number of failed tests, rather than 1.
+func (m Mocha) IsTestFailureExitCode(code int) bool {
+	return code >= 1
+}
+
+// mochaHookPattern matches the title Mocha gives to a failing hook, e.g. `"before each" hook for "saves the user"`.
+var mochaHookPattern = regexp.MustCompile(`^"(before|after) (all|each)" hook`)
+
+type MochaTest struct {
+	Title     string `json:"title"`
+	FullTitle string `json:"fullTitle"`
+	File      string `json:"file"`
+	// Duration is the duration of the test in milliseconds.
+	Duration float64 `json:"duration"`
+	Err      struct {
+		Message string `json:"message"`
+		Stack   string `json:"stack"`
+	} `json:"err"`
+}
+
+// scope returns the titles of the suites of the test, which Mocha joins with the title of the test
+// to make its full title.
+func (t MochaTest) scope() string {
+	return strings.TrimSpace(strings.TrimSuffix(t.FullTitle, t.Title))
+}
+
+// attempt returns the result of the test with the given status, including its duration and failure.
+// The stack of the error starts with its message, so it is preferred when present.
+func (t MochaTest) attempt(status TestStatus) TestAttempt {
+	attempt := TestAttempt{
+		Status:   status,
+		Duration: millisecondsToDuration(t.Duration),
+	}
+	if status == TestStatusFailed {
+		output := t.Err.Stack
+		if output == "" {
+			output = t.Err.Message
+		}
+		attempt.Failure = parseTestFailure("", output)
+	}
+	return attempt
+}
+
+// MochaReport is the report of Mocha's JSON reporter, which lists the tests by their outcome.
+// Failing hooks are listed in Failures as well.
+type MochaReport struct {
+	Passes   []MochaTest `json:"passes"`
+	Failures []MochaTest `json:"failures"`
+	Pending  []MochaTest `json:"pending"`
+}
+
+// ParseMochaReport parses the report of Mocha's JSON reporter.
+func ParseMochaReport(path string) (MochaReport, error) {
+	var report MochaReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MochaReport{}, fmt.Errorf("failed to read report output: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &report); err != nil {
+		return MochaReport{}, fmt.Errorf("failed to parse report output: %s", err)
+	}
+
+	return report, nil
+}
+
+func (m Mocha) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	testPaths := pathsFromTestCases(testCases)
+	slices.Sort(testPaths)
+	return slices.Compact(testPaths)
+}
+
+// CommandNameAndArgs replaces the "{{testExamples}}" and "{{resultPath}}" placeholders in the test command.
+// The result path is usually part of a reporter option, e.g. "output={{resultPath}}".
+//
+// On retry, the "{{testNamePattern}}" placeholder is replaced with a pattern matching the full titles
+// of the failed tests exactly, for the --grep option of Mocha.
+func (m Mocha) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := m.TestCommand
+	if retry {
+		cmd = m.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	testPaths := m.testListPaths(testCases, retry)
+
+	if retry {
+		idx := slices.Index(words, "{{testNamePattern}}")
+		if idx < 0 {
+			return "", []string{}, fmt.Errorf("couldn't find '{{testNamePattern}}' sentinel in retry command")
+		}
+		words = slices.Replace(words, idx, idx+1, mochaTestNamePattern(testCases))
+	}
+
+	idx := slices.Index(words, "{{testExamples}}")
+	if idx >= 0 {
+		words = slices.Replace(words, idx, idx+1, testPaths...)
+	} else if !hasTestListFile(words) {
+		words = append(words, testPaths...)
+	}
+
+	if !slices.ContainsFunc(words, func(word string) bool { return strings.Contains(word, "{{resultPath}}") }) {
+		return "", []string{}, fmt.Errorf("couldn't find '{{resultPath}}' sentinel in command, exiting")
+	}
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, "{{resultPath}}", m.ResultPath)
+	}
+
+	return words[0], words[1:], nil
+}
+
+// mochaTestNamePattern returns a regular expression matching the full titles of the test cases exactly.
+// Mocha matches --grep against the full title of each test, which is its scope and name joined by a space.
+func mochaTestNamePattern(testCases []plan.TestCase) string {
+	escapedTestCases := make([]string, 0, len(testCases))
+	for _, testCase := range testCases {
+		fullTitle := testCase.Name
+		if testCase.Scope != "" {
+			fullTitle = fmt.Sprintf("%s %s", testCase.Scope, testCase.Name)
+		}
+		escapedTestCases = append(escapedTestCases, regexp.QuoteMeta(fullTitle))
+	}
+	slices.Sort(escapedTestCases)
+	escapedTestCases = slices.Compact(escapedTestCases)
+
+	return fmt.Sprintf("^(%s)$", strings.Join(escapedTestCases, "|"))
+}
@@ -0,0 +1,371 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/kballard/go-shellquote"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMocha(t *testing.T) {
+	cases := []struct {
+		input RunnerConfig
+		want  RunnerConfig
+	}{
+		// default
+		{
+			input: RunnerConfig{},
+			want: RunnerConfig{
+				TestCommand:            "npx mocha {{testExamples}} --reporter json --reporter-option output={{resultPath}}",
+				TestFilePattern:        "test/**/*.{js,cjs,mjs,ts}",
+				TestFileExcludePattern: "",
+				RetryTestCommand:       "npx mocha {{testExamples}} --grep '{{testNamePattern}}' --reporter json --reporter-option output={{resultPath}}",
+			},
+		},
+		// custom
+		{
+			input: RunnerConfig{
+				TestCommand:            "yarn mocha --reporter mocha-multi-reporters --reporter-options configFile=reporters.json {{testExamples}}",
+				TestFilePattern:        "spec/**/*.spec.ts",
+				TestFileExcludePattern: "spec/e2e",
+				RetryTestCommand:       "yarn mocha --grep '{{testNamePattern}}' {{testExamples}}",
+			},
+			want: RunnerConfig{
+				TestCommand:            "yarn mocha --reporter mocha-multi-reporters --reporter-options configFile=reporters.json {{testExamples}}",
+				TestFilePattern:        "spec/**/*.spec.ts",
+				TestFileExcludePattern: "spec/e2e",
+				RetryTestCommand:       "yarn mocha --grep '{{testNamePattern}}' {{testExamples}}",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		got := NewMocha(c.input)
+		if diff := cmp.Diff(got.RunnerConfig, c.want, cmp.AllowUnexported(RunnerConfig{})); diff != "" {
+			t.Errorf("NewMocha(%v) diff (-got +want):\n%s", c.input, diff)
+		}
+	}
+}
+
+func TestMochaDiscoverTestTargets(t *testing.T) {
+	changeCwd(t, "./testdata/mocha")
+	mocha := NewMocha(RunnerConfig{})
+
+	got, err := mocha.DiscoverTestTargets()
+	if err != nil {
+		t.Errorf("Mocha.DiscoverTestTargets() error = %v", err)
+	}
+
+	want := []string{
+		"test/hook.spec.js",
+		"test/models/spell.spec.js",
+		"test/models/user.spec.js",
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Mocha.DiscoverTestTargets() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestMochaCommandNameAndArgs_WithInterpolationPlaceholder(t *testing.T) {
+	testCases := []plan.TestCase{{Path: "test/user.spec.js"}, {Path: "test/billing.spec.js"}}
+
+	mocha := NewMocha(RunnerConfig{
+		ResultPath: "mocha.json",
+	})
+
+	gotName, gotArgs, err := mocha.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs(%q, %v) error = %v", testCases, false, err)
+	}
+
+	wantName := "npx"
+	wantArgs := []string{"mocha", "test/billing.spec.js", "test/user.spec.js", "--reporter", "json", "--reporter-option", "output=mocha.json"}
+
+	if diff := cmp.Diff(gotName, wantName); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, false, diff)
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, false, diff)
+	}
+}
+
+func TestMochaCommandNameAndArgs_WithoutInterpolationPlaceholder(t *testing.T) {
+	testCases := []plan.TestCase{{Path: "test/user.spec.js"}}
+	testCommand := "mocha --reporter json --reporter-option output={{resultPath}}"
+
+	mocha := NewMocha(RunnerConfig{
+		TestCommand: testCommand,
+		ResultPath:  "mocha.json",
+	})
+
+	gotName, gotArgs, err := mocha.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs(%q, %q) error = %v", testCases, testCommand, err)
+	}
+
+	wantName := "mocha"
+	wantArgs := []string{"--reporter", "json", "--reporter-option", "output=mocha.json", "test/user.spec.js"}
+
+	if diff := cmp.Diff(gotName, wantName); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %q) diff (-got +want):\n%s", testCases, testCommand, diff)
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %q) diff (-got +want):\n%s", testCases, testCommand, diff)
+	}
+}
+
+func TestMochaCommandNameAndArgs_WithoutResultPath(t *testing.T) {
+	testCases := []plan.TestCase{{Path: "test/user.spec.js"}}
+
+	mocha := NewMocha(RunnerConfig{
+		TestCommand: "mocha {{testExamples}}",
+		ResultPath:  "mocha.json",
+	})
+
+	_, _, err := mocha.CommandNameAndArgs(testCases, false)
+
+	want := "couldn't find '{{resultPath}}' sentinel in command, exiting"
+	if err == nil || err.Error() != want {
+		t.Errorf("CommandNameAndArgs() error = %v, want %v", err, want)
+	}
+}
+
+func TestMochaRetryCommandNameAndArgs_HappyPath(t *testing.T) {
+	testCases := []plan.TestCase{
+		{Scope: "User", Name: "validates the email (with a domain)", Path: "test/models/user.spec.js"},
+		{Scope: "User", Name: "saves the user", Path: "test/models/user.spec.js"},
+		{Scope: "Spell Expelliarmus", Name: "costs $5?", Path: "test/models/spell.spec.js"},
+	}
+
+	mocha := NewMocha(RunnerConfig{
+		ResultPath: "mocha.json",
+	})
+
+	gotName, gotArgs, err := mocha.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs(%q, %v) error = %v", testCases, true, err)
+	}
+
+	wantName := "npx"
+	wantArgs := []string{
+		"mocha",
+		"test/models/spell.spec.js",
+		"test/models/user.spec.js",
+		"--grep",
+		`^(Spell Expelliarmus costs \$5\?|User saves the user|User validates the email \(with a domain\))$`,
+		"--reporter",
+		"json",
+		"--reporter-option",
+		"output=mocha.json",
+	}
+
+	if diff := cmp.Diff(gotName, wantName); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, true, diff)
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, true, diff)
+	}
+}
+
+func TestMochaRetryCommandNameAndArgs_WithoutInterpolationPlaceholder(t *testing.T) {
+	testCases := []plan.TestCase{{Scope: "User", Name: "saves the user", Path: "test/user.spec.js"}}
+
+	mocha := NewMocha(RunnerConfig{
+		RetryTestCommand: "mocha {{testExamples}} --reporter json --reporter-option output={{resultPath}}",
+		ResultPath:       "mocha.json",
+	})
+
+	_, _, err := mocha.CommandNameAndArgs(testCases, true)
+
+	want := "couldn't find '{{testNamePattern}}' sentinel in retry command"
+	if err == nil || err.Error() != want {
+		t.Errorf("CommandNameAndArgs() error = %v, want %v", err, want)
+	}
+}
+
+func TestMochaCommandNameAndArgs_InvalidTestCommand(t *testing.T) {
+	testCases := []plan.TestCase{{Path: "test/user.spec.js"}}
+
+	mocha := NewMocha(RunnerConfig{TestCommand: "mocha --options '{{testExamples}}"})
+
+	_, _, err := mocha.CommandNameAndArgs(testCases, false)
+	if !errors.Is(err, shellquote.UnterminatedSingleQuoteError) {
+		t.Errorf("CommandNameAndArgs() error = %v, want %v", err, shellquote.UnterminatedSingleQuoteError)
+	}
+}
+
+// The Run tests copy a report of Mocha's JSON reporter to the result path rather than running Mocha.
+// Mocha writes absolute file paths, so WORKDIR in the reports is replaced with the working directory.
+func TestMochaRun(t *testing.T) {
+	changeCwd(t, "./testdata/mocha")
+
+	mocha := NewMocha(RunnerConfig{
+		TestCommand: `sh -c 'sed "s|WORKDIR|$PWD|g" report.json > "$0"; exit 1' {{resultPath}}`,
+		ResultPath:  filepath.Join(t.TempDir(), "mocha.json"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/models/spell.spec.js"},
+		{Path: "test/models/user.spec.js"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := mocha.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Mocha.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	wantFailedTests := []plan.TestCase{
+		{Scope: "User", Name: "validates the email", Path: "test/models/user.spec.js"},
+	}
+	if diff := cmp.Diff(result.FailedTests(), wantFailedTests); diff != "" {
+		t.Errorf("Mocha.Run(%q) RunResult.FailedTests() diff (-got +want):\n%s", testCases, diff)
+	}
+
+	wantSkippedTests := []plan.TestCase{
+		{Scope: "Spell Expelliarmus", Name: "works underwater", Path: "test/models/spell.spec.js"},
+	}
+	if diff := cmp.Diff(result.SkippedTests(), wantSkippedTests); diff != "" {
+		t.Errorf("Mocha.Run(%q) RunResult.SkippedTests() diff (-got +want):\n%s", testCases, diff)
+	}
+
+	stats := result.Statistics()
+	if stats.Total != 4 || stats.PassedOnFirstRun != 2 {
+		t.Errorf("Mocha.Run(%q) RunResult.Statistics() = %+v, want 4 tests with 2 passed", testCases, stats)
+	}
+
+	failed := result.getTest(wantFailedTests[0])
+	if failed.Duration() != 4*time.Millisecond {
+		t.Errorf("validates the email Duration() = %v, want %v", failed.Duration(), 4*time.Millisecond)
+	}
+	wantFailure := &TestFailure{
+		Message: "AssertionError [ERR_ASSERTION]: Expected values to be strictly equal:\n\n'harry@hogwarts' !== 'harry@hogwarts.edu'",
+		Backtrace: []string{
+			"at Context.<anonymous> (test/models/user.spec.js:9:12)",
+			"at process.processImmediate (node:internal/timers:476:21)",
+		},
+	}
+	if diff := cmp.Diff(failed.Failure(), wantFailure); diff != "" {
+		t.Errorf("validates the email Failure() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestMochaRun_HookFailed(t *testing.T) {
+	changeCwd(t, "./testdata/mocha")
+
+	mocha := NewMocha(RunnerConfig{
+		TestCommand: `sh -c 'sed "s|WORKDIR|$PWD|g" hook-failure.json > "$0"; exit 1' {{resultPath}}`,
+		ResultPath:  filepath.Join(t.TempDir(), "mocha.json"),
+	})
+
+	testCases := []plan.TestCase{{Path: "test/hook.spec.js"}}
+	result := NewRunResult([]plan.TestCase{})
+	err := mocha.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusError {
+		t.Errorf("Mocha.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusError)
+	}
+
+	if len(result.tests) != 0 {
+		t.Errorf("Mocha.Run(%q) recorded %d tests, want the hook not to be recorded as a test", testCases, len(result.tests))
+	}
+}
+
+func TestMochaRun_CommandFailed(t *testing.T) {
+	changeCwd(t, "./testdata/mocha")
+
+	mocha := NewMocha(RunnerConfig{
+		TestCommand: "sh -c 'exit 1' {{resultPath}}",
+		ResultPath:  filepath.Join(t.TempDir(), "mocha.json"),
+	})
+
+	testCases := []plan.TestCase{{Path: "test/models/user.spec.js"}}
+	result := NewRunResult([]plan.TestCase{})
+	err := mocha.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("Mocha.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
+func TestMochaRun_MutedFailures(t *testing.T) {
+	changeCwd(t, "./testdata/mocha")
+
+	// Mocha exits with the number of failed tests.
+	mocha := NewMocha(RunnerConfig{
+		TestCommand: `sh -c 'sed "s|WORKDIR|$PWD|g" muted-failures.json > "$0"; exit 2' {{resultPath}}`,
+		ResultPath:  filepath.Join(t.TempDir(), "mocha.json"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "test/models/spell.spec.js"},
+		{Path: "test/models/user.spec.js"},
+	}
+	result := NewRunResult([]plan.TestCase{
+		{Scope: "User", Name: "validates the email", Path: "test/models/user.spec.js"},
+		{Scope: "Spell Expelliarmus", Name: "disarms the opponent", Path: "test/models/spell.spec.js"},
+	})
+	err := mocha.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	if !errors.As(err, &exitError) {
+		t.Fatalf("Mocha.Run(%q) error = %v, want an exit error", testCases, err)
+	}
+
+	if !mocha.IsTestFailureExitCode(exitError.ExitCode()) {
+		t.Errorf("Mocha.IsTestFailureExitCode(%d) = false, want true", exitError.ExitCode())
+	}
+	if !result.OnlyMutedFailures() {
+		t.Errorf("Mocha.Run(%q) RunResult.OnlyMutedFailures() = false, want true", testCases)
+	}
+	if got := result.Statistics().MutedFailed; got != 2 {
+		t.Errorf("Mocha.Run(%q) RunResult.Statistics().MutedFailed = %d, want 2", testCases, got)
+	}
+}
+
+func TestMochaRun_RemovesPreviousReport(t *testing.T) {
+	changeCwd(t, "./testdata/mocha")
+
+	resultPath := filepath.Join(t.TempDir(), "mocha.json")
+	report, err := os.ReadFile("report.json")
+	if err != nil {
+		t.Fatalf("os.ReadFile(report.json) error = %v", err)
+	}
+	if err := os.WriteFile(resultPath, report, 0644); err != nil {
+		t.Fatalf("os.WriteFile(%q) error = %v", resultPath, err)
+	}
+
+	// Mocha crashes before writing its report.
+	mocha := NewMocha(RunnerConfig{
+		TestCommand: "sh -c 'exit 1' {{resultPath}}",
+		ResultPath:  resultPath,
+	})
+
+	testCases := []plan.TestCase{{Path: "test/models/user.spec.js"}}
+	result := NewRunResult([]plan.TestCase{})
+	err = mocha.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if len(result.tests) != 0 {
+		t.Errorf("Mocha.Run(%q) recorded %d tests, want the previous report not to be read", testCases, len(result.tests))
+	}
+}
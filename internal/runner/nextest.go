@@ -0,0 +1,308 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// Nextest is a runner for Rust tests run with cargo-nextest.
+//
+// Tests are split by test binary, identified by its nextest binary ID, e.g. "my-crate" for
+// the unit tests of a library or "my-crate::integration" for an integration test.
+// Slow binaries can be split further by test. The tests to run are selected with a
+// nextest filterset expression, which replaces the "{{testFilter}}" placeholder.
+type Nextest struct {
+	RunnerConfig
+}
+
+func NewNextest(n RunnerConfig) Nextest {
+	if n.TestCommand == "" {
+		n.TestCommand = "cargo nextest run --profile ci --no-fail-fast -E {{testFilter}}"
+	}
+
+	if n.RetryTestCommand == "" {
+		n.RetryTestCommand = n.TestCommand
+	}
+
+	if n.ResultPath == "" {
+		// nextest writes the JUnit report of the "ci" profile to target/nextest/ci,
+		// with the file name set by profile.ci.junit.path in .config/nextest.toml.
+		n.ResultPath = "target/nextest/ci/junit.xml"
+	}
+
+	return Nextest{
+		RunnerConfig: n,
+	}
+}
+
+func (n Nextest) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     true,
+		SplitByExample:  true,
+		FilterTestFiles: false,
+		FilterTestByTag: false,
+		AutoRetry:       true,
+		Mute:            true,
+		Skip:            false,
+		SplitBySelector: true,
+	}
+}
+
+func (n Nextest) Name() string {
+	return "cargo nextest"
+}
+
+// Run executes the test command with a filterset expression selecting the test cases,
+// and records the results from nextest's JUnit report.
+func (n Nextest) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(n, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// nextest doesn't write a report when the tests fail to build,
+	// in which case the report of a previous attempt would be read instead.
+	if err := os.Remove(n.ResultPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove previous nextest result file: %w", err)
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// nextest exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the report even if the command returns an error.
+	tests, parseErr := loadAndParseJUnitXML(n.ResultPath)
+	if parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read nextest output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the report,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	for _, test := range tests {
+		// Each test binary is a <testsuite>, named after its binary ID, which is also the classname of its tests.
+		binaryID := test.Classname
+		if binaryID == "" {
+			binaryID = test.SuiteName
+		}
+
+		result.RecordTestAttempt(plan.TestCase{
+			Format: plan.TestCaseFormatExample,
+			Scope:  binaryID,
+			Name:   test.Name,
+			Path:   binaryID,
+		}, test.Attempt())
+	}
+
+	// Return any command error after processing the report
+	return cmdErr
+}
+
+// IsTestFailureExitCode reports whether the exit code can be caused by test failures.
+// nextest exits with 100 when tests fail, and with other codes for errors such as build failures.
+// Ref: https://docs.rs/nextest-metadata/latest/nextest_metadata/enum.NextestExitCode.html
+func (n Nextest) IsTestFailureExitCode(code int) bool {
+	return code == 100
+}
+
+// DiscoverTestTargets returns the binary IDs of the test binaries that have tests to run,
+// as listed by `cargo nextest list`. Listing builds the test binaries.
+func (n Nextest) DiscoverTestTargets() ([]string, error) {
+	debug.Println("Discovering Rust test binaries using `cargo nextest list`")
+	list, err := nextestList()
+	if err != nil {
+		return nil, err
+	}
+
+	var binaries []string
+	for binaryID, suite := range list.RustSuites {
+		if len(suite.matchingTests()) > 0 {
+			binaries = append(binaries, binaryID)
+		}
+	}
+	slices.Sort(binaries)
+
+	debug.Println("Discovered", len(binaries), "test binaries")
+	if len(binaries) == 0 {
+		return nil, fmt.Errorf("no Rust test binaries with tests found using `cargo nextest list`")
+	}
+	return binaries, nil
+}
+
+// GetExamples returns the tests of the given test binaries, as listed by `cargo nextest list`.
+// Ignored tests are left out, since nextest doesn't run them by default.
+func (n Nextest) GetExamples(binaries []string) ([]plan.TestCase, error) {
+	if len(binaries) == 0 {
+		return []plan.TestCase{}, nil
+	}
+
+	debug.Printf("Listing tests in %d test binaries using `cargo nextest list`", len(binaries))
+	list, err := nextestList("-E", nextestBinaryFilter(binaries))
+	if err != nil {
+		return nil, err
+	}
+
+	return list.testCases(binaries), nil
+}
+
+// nextestList runs `cargo nextest list` with the given arguments and parses its JSON output.
+func nextestList(args ...string) (nextestTestList, error) {
+	args = append([]string{"nextest", "list", "--message-format", "json"}, args...)
+	output, err := exec.Command("cargo", args...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return nextestTestList{}, fmt.Errorf("cargo nextest list failed: %w\nstderr:\n%s", err, string(ee.Stderr))
+		}
+		return nextestTestList{}, fmt.Errorf("failed to run cargo nextest list: %w", err)
+	}
+
+	return parseNextestListOutput(output)
+}
+
+// nextestTestList is the output of `cargo nextest list --message-format json`.
+type nextestTestList struct {
+	RustSuites map[string]nextestRustSuite `json:"rust-suites"`
+}
+
+type nextestRustSuite struct {
+	BinaryID  string                     `json:"binary-id"`
+	TestCases map[string]nextestTestCase `json:"testcases"`
+}
+
+type nextestTestCase struct {
+	Ignored     bool `json:"ignored"`
+	FilterMatch struct {
+		Status string `json:"status"`
+	} `json:"filter-match"`
+}
+
+func parseNextestListOutput(output []byte) (nextestTestList, error) {
+	var list nextestTestList
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nextestTestList{}, fmt.Errorf("failed to parse cargo nextest list output: %w", err)
+	}
+	return list, nil
+}
+
+// matchingTests returns the sorted names of the tests in the suite that nextest would run.
+func (s nextestRustSuite) matchingTests() []string {
+	var names []string
+	for name, testCase := range s.TestCases {
+		if testCase.FilterMatch.Status == "matches" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// testCases returns the tests of the given binaries that nextest would run, in the order of the binaries.
+func (l nextestTestList) testCases(binaries []string) []plan.TestCase {
+	testCases := []plan.TestCase{}
+	for _, binaryID := range binaries {
+		suite, ok := l.RustSuites[binaryID]
+		if !ok {
+			continue
+		}
+		for _, name := range suite.matchingTests() {
+			testCases = append(testCases, plan.TestCase{
+				Format: plan.TestCaseFormatExample,
+				Scope:  binaryID,
+				Name:   name,
+				Path:   binaryID,
+			})
+		}
+	}
+	return testCases
+}
+
+// CommandNameAndArgs replaces the "{{testFilter}}" placeholder in the test command with a filterset
+// expression selecting the test cases. When the command has no "{{testFilter}}" placeholder,
+// the expression is appended with -E.
+//
+// There is no "{{resultPath}}" placeholder, as the path of nextest's JUnit report is set in its configuration.
+func (n Nextest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := n.TestCommand
+	if retry {
+		cmd = n.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	filter := nextestFilter(testCases)
+	idx := slices.Index(words, "{{testFilter}}")
+	if idx < 0 {
+		words = append(words, "-E", filter)
+	} else {
+		words[idx] = filter
+	}
+
+	return words[0], words[1:], nil
+}
+
+// nextestFilter returns a filterset expression matching the test cases exactly.
+// A test binary that isn't split by test runs as a whole, e.g. "binary_id(=my-crate)",
+// and the tests of a split binary are matched by name, e.g.
+// "(binary_id(=my-crate) & (test(=tests::a) | test(=tests::b)))".
+func nextestFilter(testCases []plan.TestCase) string {
+	var binaries []string
+	binaryTests := map[string][]string{}
+	wholeBinaries := map[string]bool{}
+	for _, testCase := range testCases {
+		binaryID := nextestBinaryFromTestCase(testCase)
+		if _, ok := binaryTests[binaryID]; !ok {
+			binaries = append(binaries, binaryID)
+			binaryTests[binaryID] = []string{}
+		}
+		if testCase.Name == "" {
+			wholeBinaries[binaryID] = true
+			continue
+		}
+		binaryTests[binaryID] = append(binaryTests[binaryID], fmt.Sprintf("test(=%s)", testCase.Name))
+	}
+
+	expressions := make([]string, 0, len(binaries))
+	for _, binaryID := range binaries {
+		binary := fmt.Sprintf("binary_id(=%s)", binaryID)
+		tests := binaryTests[binaryID]
+		if wholeBinaries[binaryID] {
+			expressions = append(expressions, binary)
+			continue
+		}
+
+		slices.Sort(tests)
+		tests = slices.Compact(tests)
+		if len(tests) == 1 {
+			expressions = append(expressions, fmt.Sprintf("(%s & %s)", binary, tests[0]))
+		} else {
+			expressions = append(expressions, fmt.Sprintf("(%s & (%s))", binary, strings.Join(tests, " | ")))
+		}
+	}
+
+	return strings.Join(expressions, " | ")
+}
+
+// nextestBinaryFromTestCase returns the binary ID of the test case, which is its selector
+// value for selector-based plans, and its path otherwise.
+func nextestBinaryFromTestCase(tc plan.TestCase) string {
+	if tc.Format == plan.TestCaseFormatSelector {
+		return tc.Value
+	}
+	return tc.Path
+}
+
+// nextestBinaryFilter returns a filterset expression matching the given test binaries.
+func nextestBinaryFilter(binaries []string) string {
+	return nextestFilter(testCasesFromPaths(binaries))
+}
@@ -0,0 +1,317 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// useNextestTargetDir builds the test binaries of testdata/nextest in a temporary directory.
+// nextest still writes its reports to target/nextest in the crate, which is removed after the test.
+func useNextestTargetDir(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("cargo"); err != nil {
+		t.Skip("cargo is not installed")
+	}
+
+	t.Setenv("CARGO_TARGET_DIR", t.TempDir())
+	t.Cleanup(func() { os.RemoveAll("target") })
+}
+
+func TestNewNextest(t *testing.T) {
+	cases := []struct {
+		input RunnerConfig
+		want  RunnerConfig
+	}{
+		// default
+		{
+			input: RunnerConfig{},
+			want: RunnerConfig{
+				TestCommand:      "cargo nextest run --profile ci --no-fail-fast -E {{testFilter}}",
+				RetryTestCommand: "cargo nextest run --profile ci --no-fail-fast -E {{testFilter}}",
+				ResultPath:       "target/nextest/ci/junit.xml",
+			},
+		},
+		// custom
+		{
+			input: RunnerConfig{
+				TestCommand: "cargo nextest run --profile buildkite --workspace -E {{testFilter}}",
+				ResultPath:  "target/nextest/buildkite/junit.xml",
+			},
+			want: RunnerConfig{
+				TestCommand:      "cargo nextest run --profile buildkite --workspace -E {{testFilter}}",
+				RetryTestCommand: "cargo nextest run --profile buildkite --workspace -E {{testFilter}}",
+				ResultPath:       "target/nextest/buildkite/junit.xml",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		got := NewNextest(c.input)
+		if diff := cmp.Diff(got.RunnerConfig, c.want, cmp.AllowUnexported(RunnerConfig{})); diff != "" {
+			t.Errorf("NewNextest(%v) diff (-got +want):\n%s", c.input, diff)
+		}
+	}
+}
+
+func TestNextestCommandNameAndArgs(t *testing.T) {
+	testCases := []plan.TestCase{
+		{Path: "spells"},
+		{Scope: "spells::duel", Name: "duel_ends", Path: "spells::duel"},
+		{Scope: "spells::duel", Name: "duel_begins", Path: "spells::duel"},
+		{Format: plan.TestCaseFormatSelector, Value: "wands::bin/ollivander"},
+	}
+
+	nextest := NewNextest(RunnerConfig{})
+
+	gotName, gotArgs, err := nextest.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs(%q, %v) error = %v", testCases, false, err)
+	}
+
+	wantName := "cargo"
+	wantArgs := []string{
+		"nextest", "run", "--profile", "ci", "--no-fail-fast", "-E",
+		"binary_id(=spells) | (binary_id(=spells::duel) & (test(=duel_begins) | test(=duel_ends))) | binary_id(=wands::bin/ollivander)",
+	}
+
+	if diff := cmp.Diff(gotName, wantName); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, false, diff)
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, false, diff)
+	}
+}
+
+func TestNextestCommandNameAndArgs_Retry(t *testing.T) {
+	testCases := []plan.TestCase{
+		{Scope: "spells", Name: "tests::stupefy_stuns", Path: "spells"},
+	}
+
+	nextest := NewNextest(RunnerConfig{
+		RetryTestCommand: "cargo nextest run --profile ci --retries 0",
+	})
+
+	gotName, gotArgs, err := nextest.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs(%q, %v) error = %v", testCases, true, err)
+	}
+
+	wantName := "cargo"
+	wantArgs := []string{
+		"nextest", "run", "--profile", "ci", "--retries", "0",
+		"-E", "(binary_id(=spells) & test(=tests::stupefy_stuns))",
+	}
+
+	if diff := cmp.Diff(gotName, wantName); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, true, diff)
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, true, diff)
+	}
+}
+
+func TestNextestFilter_WholeBinaryAndTests(t *testing.T) {
+	// A binary that runs as a whole includes all of its tests.
+	testCases := []plan.TestCase{
+		{Scope: "spells", Name: "tests::stupefy_stuns", Path: "spells"},
+		{Path: "spells"},
+	}
+
+	got := nextestFilter(testCases)
+
+	want := "binary_id(=spells)"
+	if got != want {
+		t.Errorf("nextestFilter(%q) = %q, want %q", testCases, got, want)
+	}
+}
+
+func TestParseNextestListOutput(t *testing.T) {
+	output, err := os.ReadFile("testdata/nextest/list.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := parseNextestListOutput(output)
+	if err != nil {
+		t.Fatalf("parseNextestListOutput() error = %v", err)
+	}
+
+	got := list.testCases([]string{"spells::duel", "spells", "missing"})
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "spells::duel", Name: "duel_ends", Path: "spells::duel"},
+		{Format: plan.TestCaseFormatExample, Scope: "spells", Name: "tests::expelliarmus_disarms", Path: "spells"},
+		{Format: plan.TestCaseFormatExample, Scope: "spells", Name: "tests::stupefy_stuns", Path: "spells"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("nextestTestList.testCases() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestNextestDiscoverTestTargets(t *testing.T) {
+	changeCwd(t, "./testdata/nextest")
+	useNextestTargetDir(t)
+
+	nextest := NewNextest(RunnerConfig{})
+
+	got, err := nextest.DiscoverTestTargets()
+	if err != nil {
+		t.Fatalf("Nextest.DiscoverTestTargets() error = %v", err)
+	}
+
+	want := []string{"spells", "spells::duel"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Nextest.DiscoverTestTargets() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestNextestGetExamples(t *testing.T) {
+	changeCwd(t, "./testdata/nextest")
+	useNextestTargetDir(t)
+
+	nextest := NewNextest(RunnerConfig{})
+
+	got, err := nextest.GetExamples([]string{"spells"})
+	if err != nil {
+		t.Fatalf("Nextest.GetExamples() error = %v", err)
+	}
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "spells", Name: "tests::expelliarmus_disarms", Path: "spells"},
+		{Format: plan.TestCaseFormatExample, Scope: "spells", Name: "tests::stupefy_stuns", Path: "spells"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Nextest.GetExamples() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestNextestRun(t *testing.T) {
+	changeCwd(t, "./testdata/nextest")
+	useNextestTargetDir(t)
+
+	nextest := NewNextest(RunnerConfig{})
+
+	testCases := []plan.TestCase{
+		{Path: "spells"},
+		{Scope: "spells::duel", Name: "duel_ends", Path: "spells::duel"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := nextest.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	if assert.ErrorAs(t, err, &exitError) {
+		assert.Equal(t, 100, exitError.ExitCode())
+	}
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("Nextest.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	wantFailedTests := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "spells", Name: "tests::stupefy_stuns", Path: "spells"},
+	}
+	if diff := cmp.Diff(result.FailedTests(), wantFailedTests); diff != "" {
+		t.Errorf("Nextest.Run(%q) RunResult.FailedTests() diff (-got +want):\n%s", testCases, diff)
+	}
+
+	stats := result.Statistics()
+	if stats.Total != 3 || stats.PassedOnFirstRun != 2 {
+		t.Errorf("Nextest.Run(%q) RunResult.Statistics() = %+v, want 3 tests with 2 passed", testCases, stats)
+	}
+
+	failure := result.getTest(wantFailedTests[0]).Failure()
+	if failure == nil || failure.Message != "thread 'tests::stupefy_stuns' panicked at src/lib.rs:20:9" {
+		t.Errorf("tests::stupefy_stuns Failure() = %+v, want the panic", failure)
+	}
+}
+
+func TestNextestRun_Retry(t *testing.T) {
+	changeCwd(t, "./testdata/nextest")
+	useNextestTargetDir(t)
+
+	nextest := NewNextest(RunnerConfig{})
+
+	testCases := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "spells::duel", Name: "duel_ends", Path: "spells::duel"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := nextest.Run(result, testCases, true)
+
+	assert.NoError(t, err)
+
+	if result.Status() != RunStatusPassed {
+		t.Errorf("Nextest.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusPassed)
+	}
+
+	if got := len(result.tests); got != 1 {
+		t.Errorf("Nextest.Run(%q) recorded %d tests, want only the retried test", testCases, got)
+	}
+}
+
+func TestNextestRun_MutedFailures(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.xml")
+	if err := os.WriteFile(report, []byte(exampleJUnitXML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// nextest exits with 100 when tests fail.
+	nextest := NewNextest(RunnerConfig{
+		TestCommand: fmt.Sprintf(`sh -c 'cp %s "$0"; exit 100' %s`, report, filepath.Join(dir, "junit.xml")),
+		ResultPath:  filepath.Join(dir, "junit.xml"),
+	})
+
+	const binaryID = "github.com/buildkite/test-engine-client/v2/internal/debug"
+	testCases := []plan.TestCase{{Path: binaryID}}
+	result := NewRunResult([]plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: binaryID, Name: "TestPrintf", Path: binaryID},
+	})
+	err := nextest.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	if !errors.As(err, &exitError) {
+		t.Fatalf("Nextest.Run(%q) error = %v, want an exit error", testCases, err)
+	}
+
+	if !nextest.IsTestFailureExitCode(exitError.ExitCode()) {
+		t.Errorf("Nextest.IsTestFailureExitCode(%d) = false, want true", exitError.ExitCode())
+	}
+	if nextest.IsTestFailureExitCode(101) {
+		t.Errorf("Nextest.IsTestFailureExitCode(101) = true, want false")
+	}
+	if !result.OnlyMutedFailures() {
+		t.Errorf("Nextest.Run(%q) RunResult.OnlyMutedFailures() = false, want true", testCases)
+	}
+}
+
+func TestNextestRun_CommandFailed(t *testing.T) {
+	resultPath := filepath.Join(t.TempDir(), "junit.xml")
+	// A report of a previous attempt must not be read again.
+	if err := os.WriteFile(resultPath, []byte(exampleJUnitXML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	nextest := NewNextest(RunnerConfig{
+		TestCommand: "sh -c 'exit 101' --",
+		ResultPath:  resultPath,
+	})
+
+	testCases := []plan.TestCase{{Path: "spells"}}
+	result := NewRunResult([]plan.TestCase{})
+	err := nextest.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("Nextest.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
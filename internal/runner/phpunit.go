@@ -0,0 +1,200 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// PHPUnit is a first-class runner for PHPUnit.
+//
+// Results are read from the JUnit XML report written with --log-junit.
+type PHPUnit struct {
+	RunnerConfig
+}
+
+func NewPHPUnit(p RunnerConfig) PHPUnit {
+	if p.TestCommand == "" {
+		p.TestCommand = "vendor/bin/phpunit --log-junit {{resultPath}} {{testExamples}}"
+	}
+
+	if p.TestFilePattern == "" {
+		p.TestFilePattern = "tests/**/*Test.php"
+	}
+
+	if p.RetryTestCommand == "" {
+		p.RetryTestCommand = "vendor/bin/phpunit --log-junit {{resultPath}} --filter {{testNamePattern}} {{testExamples}}"
+	}
+
+	return PHPUnit{
+		RunnerConfig: p,
+	}
+}
+
+func (p PHPUnit) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     true,
+		SplitByExample:  false,
+		FilterTestFiles: true,
+		FilterTestByTag: false,
+		AutoRetry:       true,
+		Mute:            true,
+		Skip:            false,
+		SplitBySelector: true,
+	}
+}
+
+func (p PHPUnit) Name() string {
+	return "PHPUnit"
+}
+
+// DiscoverTestTargets returns file names using the discovery pattern.
+func (p PHPUnit) DiscoverTestTargets() ([]string, error) {
+	debug.Println("Discovering test files with include pattern:", p.TestFilePattern, "exclude pattern:", p.TestFileExcludePattern)
+	files, err := discoverTestFiles(p.TestFilePattern, p.TestFileExcludePattern)
+	debug.Println("Discovered", len(files), "files")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found with pattern %q and exclude pattern %q", p.TestFilePattern, p.TestFileExcludePattern)
+	}
+
+	return files, nil
+}
+
+func (p PHPUnit) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	cmd, cleanup, err := buildCommand(p, testCases, retry)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// PHPUnit doesn't write a report when PHP fails with a fatal error,
+	// in which case the report of a previous attempt would be read instead.
+	if err := os.Remove(p.ResultPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove previous PHPUnit result file: %w", err)
+	}
+
+	cmdErr := runAndForwardSignal(cmd)
+
+	// PHPUnit exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the report even if the command returns an error.
+	tests, parseErr := loadAndParseJUnitXML(p.ResultPath)
+	if parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read PHPUnit output, tests will not be retried: %v\n", parseErr)
+		// We don't want to fail the build if we fail to parse the report,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %v", err)
+	}
+
+	for _, test := range tests {
+		result.RecordTestAttempt(mapPHPUnitJUnitToTestCase(test, workDir), test.Attempt())
+	}
+
+	// Return any command error after processing the report
+	return cmdErr
+}
+
+// IsTestFailureExitCode reports whether the exit code can be caused by test failures.
+// PHPUnit exits with 1 when tests fail, and with 2 when tests error, e.g. with an uncaught exception.
+func (p PHPUnit) IsTestFailureExitCode(code int) bool {
+	return code == 1 || code == 2
+}
+
+// mapPHPUnitJUnitToTestCase maps a testcase of a PHPUnit JUnit report to a test case.
+// The scope is the fully qualified test class, and the name is the test method,
+// followed by the data set for tests with a data provider, e.g. "testEmail with data set #0".
+// PHPUnit writes absolute file paths, which are made relative to the working directory
+// like the test files given to bktec.
+func mapPHPUnitJUnitToTestCase(test JUnitXMLTestCase, workDir string) plan.TestCase {
+	class := test.Class
+	if class == "" {
+		// PHPUnit before 9.5 only writes the classname, with dots in place of the namespace separators.
+		class = strings.ReplaceAll(test.Classname, ".", `\`)
+	}
+
+	path := test.File
+	if rel, err := filepath.Rel(workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+
+	return plan.TestCase{
+		Scope: class,
+		Name:  test.Name,
+		Path:  path,
+	}
+}
+
+// CommandNameAndArgs replaces the "{{testExamples}}" and "{{resultPath}}" placeholders in the test command.
+//
+// On retry, the "{{testNamePattern}}" placeholder is replaced with a pattern matching the failed tests
+// exactly, for the --filter option of PHPUnit, and "{{testExamples}}" with the files of the failed tests.
+func (p PHPUnit) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	cmd := p.TestCommand
+	if retry {
+		cmd = p.RetryTestCommand
+	}
+
+	words, err := shellquote.Split(cmd)
+	if err != nil {
+		return "", []string{}, err
+	}
+
+	testPaths := pathsFromTestCases(testCases)
+	slices.Sort(testPaths)
+	testPaths = slices.Compact(testPaths)
+
+	if retry {
+		idx := slices.Index(words, "{{testNamePattern}}")
+		if idx < 0 {
+			return "", []string{}, fmt.Errorf("couldn't find '{{testNamePattern}}' sentinel in retry command")
+		}
+		words = slices.Replace(words, idx, idx+1, phpunitFilterPattern(testCases))
+	}
+
+	idx := slices.Index(words, "{{testExamples}}")
+	if idx < 0 {
+		words = append(words, testPaths...)
+	} else {
+		words = slices.Replace(words, idx, idx+1, testPaths...)
+	}
+
+	outputIdx := slices.Index(words, "{{resultPath}}")
+	if outputIdx < 0 {
+		return "", []string{}, fmt.Errorf("couldn't find '{{resultPath}}' sentinel in command, exiting")
+	}
+	words = slices.Replace(words, outputIdx, outputIdx+1, p.ResultPath)
+
+	return words[0], words[1:], nil
+}
+
+// phpunitFilterPattern returns a regular expression matching the test cases exactly.
+// PHPUnit matches --filter against "Class::method", followed by the data set of the test if it has one,
+// which is the same as the scope and name of the test case.
+func phpunitFilterPattern(testCases []plan.TestCase) string {
+	names := make([]string, 0, len(testCases))
+	for _, testCase := range testCases {
+		name := fmt.Sprintf("%s::%s", testCase.Scope, testCase.Name)
+		// "/" is the delimiter of the pattern, so it has to be escaped as well.
+		names = append(names, strings.ReplaceAll(regexp.QuoteMeta(name), "/", `\/`))
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	return fmt.Sprintf("/^(%s)$/", strings.Join(names, "|"))
+}
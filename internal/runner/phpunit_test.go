@@ -0,0 +1,301 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+	"github.com/kballard/go-shellquote"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPHPUnit(t *testing.T) {
+	cases := []struct {
+		input RunnerConfig
+		want  RunnerConfig
+	}{
+		// default
+		{
+			input: RunnerConfig{},
+			want: RunnerConfig{
+				TestCommand:            "vendor/bin/phpunit --log-junit {{resultPath}} {{testExamples}}",
+				TestFilePattern:        "tests/**/*Test.php",
+				TestFileExcludePattern: "",
+				RetryTestCommand:       "vendor/bin/phpunit --log-junit {{resultPath}} --filter {{testNamePattern}} {{testExamples}}",
+			},
+		},
+		// custom
+		{
+			input: RunnerConfig{
+				TestCommand:            "php artisan test --log-junit {{resultPath}} {{testExamples}}",
+				TestFilePattern:        "tests/Unit/**/*Test.php",
+				TestFileExcludePattern: "tests/Unit/Legacy",
+				RetryTestCommand:       "php artisan test --log-junit {{resultPath}} --filter {{testNamePattern}}",
+			},
+			want: RunnerConfig{
+				TestCommand:            "php artisan test --log-junit {{resultPath}} {{testExamples}}",
+				TestFilePattern:        "tests/Unit/**/*Test.php",
+				TestFileExcludePattern: "tests/Unit/Legacy",
+				RetryTestCommand:       "php artisan test --log-junit {{resultPath}} --filter {{testNamePattern}}",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		got := NewPHPUnit(c.input)
+		if diff := cmp.Diff(got.RunnerConfig, c.want, cmp.AllowUnexported(RunnerConfig{})); diff != "" {
+			t.Errorf("NewPHPUnit(%v) diff (-got +want):\n%s", c.input, diff)
+		}
+	}
+}
+
+func TestPHPUnitDiscoverTestTargets(t *testing.T) {
+	changeCwd(t, "./testdata/phpunit")
+	phpunit := NewPHPUnit(RunnerConfig{})
+
+	got, err := phpunit.DiscoverTestTargets()
+	if err != nil {
+		t.Errorf("PHPUnit.DiscoverTestTargets() error = %v", err)
+	}
+
+	want := []string{
+		"tests/Feature/CheckoutTest.php",
+		"tests/Unit/UserTest.php",
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("PHPUnit.DiscoverTestTargets() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestPHPUnitCommandNameAndArgs(t *testing.T) {
+	testCases := []plan.TestCase{{Path: "tests/Unit/UserTest.php"}, {Path: "tests/Feature/CheckoutTest.php"}}
+
+	phpunit := NewPHPUnit(RunnerConfig{
+		ResultPath: "junit.xml",
+	})
+
+	gotName, gotArgs, err := phpunit.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs(%q, %v) error = %v", testCases, false, err)
+	}
+
+	wantName := "vendor/bin/phpunit"
+	wantArgs := []string{"--log-junit", "junit.xml", "tests/Feature/CheckoutTest.php", "tests/Unit/UserTest.php"}
+
+	if diff := cmp.Diff(gotName, wantName); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, false, diff)
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, false, diff)
+	}
+}
+
+func TestPHPUnitCommandNameAndArgs_WithoutResultPath(t *testing.T) {
+	testCases := []plan.TestCase{{Path: "tests/Unit/UserTest.php"}}
+
+	phpunit := NewPHPUnit(RunnerConfig{
+		TestCommand: "vendor/bin/phpunit {{testExamples}}",
+		ResultPath:  "junit.xml",
+	})
+
+	_, _, err := phpunit.CommandNameAndArgs(testCases, false)
+
+	want := "couldn't find '{{resultPath}}' sentinel in command, exiting"
+	if err == nil || err.Error() != want {
+		t.Errorf("CommandNameAndArgs() error = %v, want %v", err, want)
+	}
+}
+
+func TestPHPUnitRetryCommandNameAndArgs(t *testing.T) {
+	testCases := []plan.TestCase{
+		{Scope: `Tests\Unit\UserTest`, Name: "testValidatesTheEmail with data set #1", Path: "tests/Unit/UserTest.php"},
+		{Scope: `Tests\Unit\UserTest`, Name: "testSavesTheUser", Path: "tests/Unit/UserTest.php"},
+		{Scope: `Tests\Feature\CheckoutTest`, Name: `testRedirects with data set "/cart"`, Path: "tests/Feature/CheckoutTest.php"},
+	}
+
+	phpunit := NewPHPUnit(RunnerConfig{
+		ResultPath: "junit.xml",
+	})
+
+	gotName, gotArgs, err := phpunit.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs(%q, %v) error = %v", testCases, true, err)
+	}
+
+	wantName := "vendor/bin/phpunit"
+	wantArgs := []string{
+		"--log-junit",
+		"junit.xml",
+		"--filter",
+		`/^(Tests\\Feature\\CheckoutTest::testRedirects with data set "\/cart"|Tests\\Unit\\UserTest::testSavesTheUser|Tests\\Unit\\UserTest::testValidatesTheEmail with data set #1)$/`,
+		"tests/Feature/CheckoutTest.php",
+		"tests/Unit/UserTest.php",
+	}
+
+	if diff := cmp.Diff(gotName, wantName); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, true, diff)
+	}
+	if diff := cmp.Diff(gotArgs, wantArgs); diff != "" {
+		t.Errorf("CommandNameAndArgs(%q, %v) diff (-got +want):\n%s", testCases, true, diff)
+	}
+}
+
+func TestPHPUnitRetryCommandNameAndArgs_WithoutInterpolationPlaceholder(t *testing.T) {
+	testCases := []plan.TestCase{{Scope: `Tests\Unit\UserTest`, Name: "testSavesTheUser", Path: "tests/Unit/UserTest.php"}}
+
+	phpunit := NewPHPUnit(RunnerConfig{
+		RetryTestCommand: "vendor/bin/phpunit --log-junit {{resultPath}} {{testExamples}}",
+		ResultPath:       "junit.xml",
+	})
+
+	_, _, err := phpunit.CommandNameAndArgs(testCases, true)
+
+	want := "couldn't find '{{testNamePattern}}' sentinel in retry command"
+	if err == nil || err.Error() != want {
+		t.Errorf("CommandNameAndArgs() error = %v, want %v", err, want)
+	}
+}
+
+func TestPHPUnitCommandNameAndArgs_InvalidTestCommand(t *testing.T) {
+	testCases := []plan.TestCase{{Path: "tests/Unit/UserTest.php"}}
+
+	phpunit := NewPHPUnit(RunnerConfig{TestCommand: "vendor/bin/phpunit --log-junit '{{resultPath}}"})
+
+	_, _, err := phpunit.CommandNameAndArgs(testCases, false)
+	if !errors.Is(err, shellquote.UnterminatedSingleQuoteError) {
+		t.Errorf("CommandNameAndArgs() error = %v, want %v", err, shellquote.UnterminatedSingleQuoteError)
+	}
+}
+
+// The Run tests copy a JUnit report written by PHPUnit to the result path rather than running PHPUnit.
+// PHPUnit writes absolute file paths, so WORKDIR in the report is replaced with the working directory.
+func TestPHPUnitRun(t *testing.T) {
+	changeCwd(t, "./testdata/phpunit")
+
+	phpunit := NewPHPUnit(RunnerConfig{
+		TestCommand: `sh -c 'sed "s|WORKDIR|$PWD|g" junit.xml > "$0"; exit 1' {{resultPath}}`,
+		ResultPath:  filepath.Join(t.TempDir(), "junit.xml"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "tests/Feature/CheckoutTest.php"},
+		{Path: "tests/Unit/UserTest.php"},
+	}
+	result := NewRunResult([]plan.TestCase{})
+	err := phpunit.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusFailed {
+		t.Errorf("PHPUnit.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusFailed)
+	}
+
+	wantFailedTests := []plan.TestCase{
+		{Scope: `Tests\Unit\UserTest`, Name: "testValidatesTheEmail with data set #1", Path: "tests/Unit/UserTest.php"},
+	}
+	if diff := cmp.Diff(result.FailedTests(), wantFailedTests); diff != "" {
+		t.Errorf("PHPUnit.Run(%q) RunResult.FailedTests() diff (-got +want):\n%s", testCases, diff)
+	}
+
+	wantSkippedTests := []plan.TestCase{
+		{Scope: `Tests\Feature\CheckoutTest`, Name: "testSendsTheReceipt", Path: "tests/Feature/CheckoutTest.php"},
+	}
+	if diff := cmp.Diff(result.SkippedTests(), wantSkippedTests); diff != "" {
+		t.Errorf("PHPUnit.Run(%q) RunResult.SkippedTests() diff (-got +want):\n%s", testCases, diff)
+	}
+
+	stats := result.Statistics()
+	if stats.Total != 5 || stats.PassedOnFirstRun != 3 {
+		t.Errorf("PHPUnit.Run(%q) RunResult.Statistics() = %+v, want 5 tests with 3 passed", testCases, stats)
+	}
+
+	failed := result.getTest(wantFailedTests[0])
+	if failed.Duration() != 8133*time.Microsecond {
+		t.Errorf("testValidatesTheEmail with data set #1 Duration() = %v, want %v", failed.Duration(), 8133*time.Microsecond)
+	}
+	if failure := failed.Failure(); failure == nil || failure.Exception != `PHPUnit\Framework\ExpectationFailedException` {
+		t.Errorf("testValidatesTheEmail with data set #1 Failure() = %+v, want an ExpectationFailedException", failure)
+	}
+}
+
+func TestPHPUnitRun_MutedFailures(t *testing.T) {
+	changeCwd(t, "./testdata/phpunit")
+
+	// PHPUnit exits with 2 when a test errors.
+	phpunit := NewPHPUnit(RunnerConfig{
+		TestCommand: `sh -c 'sed "s|WORKDIR|$PWD|g" junit.xml > "$0"; exit 2' {{resultPath}}`,
+		ResultPath:  filepath.Join(t.TempDir(), "junit.xml"),
+	})
+
+	testCases := []plan.TestCase{
+		{Path: "tests/Feature/CheckoutTest.php"},
+		{Path: "tests/Unit/UserTest.php"},
+	}
+	result := NewRunResult([]plan.TestCase{
+		{Scope: `Tests\Unit\UserTest`, Name: "testValidatesTheEmail with data set #1", Path: "tests/Unit/UserTest.php"},
+	})
+	err := phpunit.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	if !errors.As(err, &exitError) {
+		t.Fatalf("PHPUnit.Run(%q) error = %v, want an exit error", testCases, err)
+	}
+
+	if !phpunit.IsTestFailureExitCode(exitError.ExitCode()) {
+		t.Errorf("PHPUnit.IsTestFailureExitCode(%d) = false, want true", exitError.ExitCode())
+	}
+	if phpunit.IsTestFailureExitCode(255) {
+		t.Errorf("PHPUnit.IsTestFailureExitCode(255) = true, want false")
+	}
+	if !result.OnlyMutedFailures() {
+		t.Errorf("PHPUnit.Run(%q) RunResult.OnlyMutedFailures() = false, want true", testCases)
+	}
+}
+
+func TestPHPUnitRun_CommandFailed(t *testing.T) {
+	changeCwd(t, "./testdata/phpunit")
+
+	resultPath := filepath.Join(t.TempDir(), "junit.xml")
+	// A report of a previous attempt must not be read again.
+	if err := os.WriteFile(resultPath, []byte(exampleJUnitXML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	phpunit := NewPHPUnit(RunnerConfig{
+		TestCommand: "sh -c 'exit 255' {{resultPath}}",
+		ResultPath:  resultPath,
+	})
+
+	testCases := []plan.TestCase{{Path: "tests/Unit/UserTest.php"}}
+	result := NewRunResult([]plan.TestCase{})
+	err := phpunit.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if result.Status() != RunStatusUnknown {
+		t.Errorf("PHPUnit.Run(%q) RunResult.Status = %v, want %v", testCases, result.Status(), RunStatusUnknown)
+	}
+}
+
+func TestMapPHPUnitJUnitToTestCase_Classname(t *testing.T) {
+	test := JUnitXMLTestCase{
+		Classname: "Tests.Unit.UserTest",
+		Name:      "testSavesTheUser",
+		File:      "/app/tests/Unit/UserTest.php",
+	}
+
+	got := mapPHPUnitJUnitToTestCase(test, "/app")
+
+	want := plan.TestCase{Scope: `Tests\Unit\UserTest`, Name: "testSavesTheUser", Path: "tests/Unit/UserTest.php"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("mapPHPUnitJUnitToTestCase(%v) diff (-got +want):\n%s", test, diff)
+	}
+}
@@ -67,37 +67,65 @@ func (p Playwright) SupportedFeatures() SupportedFeatures {
 }
 
 func (p Playwright) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(p, testCases, retry)
+	cmd, cleanup, err := buildCommand(p, testCases, retry)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
+
+	// Reports from a previous attempt would otherwise be read again, and tests
+	// that are not part of this attempt would be counted twice.
+	if err := removeResultFiles(p.ResultPath); err != nil {
+		return err
+	}
 
 	cmdErr := runAndForwardSignal(cmd)
 
 	// Playwright exits with a non-zero status code when there are test failures,
-	// so we should always attempt to parse the report even if the command returns an error.
-	report, parseErr := p.parseReport(p.ResultPath)
-	if parseErr != nil {
+	// so we should always attempt to parse the reports even if the command returns an error.
+	if parseErr := p.parseResults(result); parseErr != nil {
 		fmt.Printf("Buildkite Test Engine Client: Failed to read Playwright output, tests will not be retried: %v\n", parseErr)
 		// We don't want to fail the build if we fail to parse the report,
 		// therefore we return the command error (which can be nil), instead of the parse error.
 		return cmdErr
 	}
 
-	for _, suite := range report.Suites {
-		testResults := p.getTestResultsFromSuite(suite, suite.Title)
-		for _, testResult := range testResults {
-			result.RecordTestResult(testResult.TestCase, testResult.Status)
-		}
+	// Return any command error after processing the report
+	return cmdErr
+
+}
+
+// parseResults records the results of the reports matching the result path. A glob result path matches
+// several reports, e.g. the JSON reports of the shards of a run, or of each project run separately.
+func (p Playwright) parseResults(result *RunResult) error {
+	files, err := ResultFiles(p.ResultPath)
+	if err != nil {
+		return err
 	}
 
-	if len(report.Errors) > 0 {
-		result.error = fmt.Errorf("Playwright failed with errors")
+	if len(files) == 0 {
+		return fmt.Errorf("no result files found matching %q", p.ResultPath)
 	}
 
-	// Return any command error after processing the report
-	return cmdErr
+	for _, file := range files {
+		report, err := p.parseReport(file)
+		if err != nil {
+			return err
+		}
+
+		for _, suite := range report.Suites {
+			testResults := p.getTestResultsFromSuite(suite, suite.Title)
+			for _, testResult := range testResults {
+				result.RecordTestAttempt(testResult.TestCase, testResult.LastAttempt())
+			}
+		}
 
+		if len(report.Errors) > 0 {
+			result.error = fmt.Errorf("Playwright failed with errors")
+		}
+	}
+
+	return nil
 }
 
 // getTestCasesFromSuite recursively traverses the Playwright report suite and returns all test cases.
@@ -119,6 +147,7 @@ func (p Playwright) getTestResultsFromSuite(suite PlaywrightReportSuite, suiteNa
 		testResults = append(testResults, TestResult{
 			TestCase: mapSpecToTestCase(spec, suiteName),
 			Status:   status,
+			Attempts: []TestAttempt{spec.attempt(status)},
 		})
 	}
 
@@ -129,6 +158,10 @@ func (p Playwright) getTestResultsFromSuite(suite PlaywrightReportSuite, suiteNa
 	return testResults
 }
 
+func (p Playwright) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	return pathsFromTestCases(testCases)
+}
+
 func (p Playwright) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
 	cmd := p.TestCommand
 	if retry {
@@ -140,13 +173,13 @@ func (p Playwright) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (s
 		return "", []string{}, err
 	}
 
-	testPaths := pathsFromTestCases(testCases)
+	testPaths := p.testListPaths(testCases, retry)
 
 	idx := slices.Index(words, "{{testExamples}}")
-	if idx < 0 {
-		words = append(words, testPaths...)
-	} else {
+	if idx >= 0 {
 		words = slices.Replace(words, idx, idx+1, testPaths...)
+	} else if !hasTestListFile(words) {
+		words = append(words, testPaths...)
 	}
 
 	return words[0], words[1:], nil
@@ -204,6 +237,13 @@ func (p Playwright) GetExamples(files []string) ([]plan.TestCase, error) {
 		return []plan.TestCase{}, err
 	}
 
+	words, cleanup, err := writeTestListFile(append([]string{cmdName}, cmdArgs...), p.testListPaths(testCases, false))
+	if err != nil {
+		return []plan.TestCase{}, err
+	}
+	defer cleanup()
+	cmdName, cmdArgs = words[0], words[1:]
+
 	cmdArgs = append(cmdArgs, "--list", "--reporter=json")
 
 	debug.Printf("Running `%s %s` to list tests", cmdName, strings.Join(cmdArgs, " "))
@@ -269,6 +309,21 @@ func mapSpecToTestCase(spec PlaywrightSpec, suiteName string) plan.TestCase {
 type PlaywrightTest struct {
 	ProjectName string
 	Status      string
+	// Results are the results of each run of the test, including Playwright's own retries.
+	Results []PlaywrightTestResult
+}
+
+type PlaywrightTestResult struct {
+	Status string
+	// Duration is the duration of the run in milliseconds.
+	Duration float64
+	Error    *PlaywrightError
+}
+
+type PlaywrightError struct {
+	Message string
+	// Stack is the error message followed by the stack trace.
+	Stack string
 }
 
 type PlaywrightSpec struct {
@@ -281,6 +336,30 @@ type PlaywrightSpec struct {
 	Tests  []PlaywrightTest
 }
 
+// attempt returns the result of the spec with the given status, including its duration and failure.
+// A spec has a test for each project, and each test can be run several times by Playwright's retries.
+// The duration is the total of the last run of each test, and the failure is the error of the first
+// test whose last run failed.
+func (s PlaywrightSpec) attempt(status TestStatus) TestAttempt {
+	attempt := TestAttempt{Status: status}
+	for _, test := range s.Tests {
+		if len(test.Results) == 0 {
+			continue
+		}
+
+		last := test.Results[len(test.Results)-1]
+		attempt.Duration += millisecondsToDuration(last.Duration)
+		if attempt.Failure == nil && last.Error != nil {
+			output := last.Error.Stack
+			if output == "" {
+				output = last.Error.Message
+			}
+			attempt.Failure = parseTestFailure("", output)
+		}
+	}
+	return attempt
+}
+
 type PlaywrightReportSuite struct {
 	Title  string
 	Specs  []PlaywrightSpec
@@ -2,12 +2,15 @@ package runner
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/buildkite/test-engine-client/v3/internal/plan"
 	"github.com/google/go-cmp/cmp"
@@ -328,3 +331,76 @@ func TestPlaywrightGetExamples_EmptyFiles(t *testing.T) {
 		t.Errorf("Playwright.GetExamples([]) = %v, want empty slice", got)
 	}
 }
+
+func TestPlaywrightSpecAttempt(t *testing.T) {
+	spec := PlaywrightSpec{
+		Title: "has title",
+		Tests: []PlaywrightTest{
+			{
+				ProjectName: "chromium",
+				Results: []PlaywrightTestResult{
+					{Status: "failed", Duration: 100, Error: &PlaywrightError{Message: "first try"}},
+					{Status: "failed", Duration: 200, Error: &PlaywrightError{
+						Message: "Error: expect(page).toHaveTitle(expected)",
+						Stack:   "Error: expect(page).toHaveTitle(expected)\n    at tests/example.spec.ts:5:22",
+					}},
+				},
+			},
+			{
+				ProjectName: "firefox",
+				Results:     []PlaywrightTestResult{{Status: "passed", Duration: 300}},
+			},
+		},
+	}
+
+	want := TestAttempt{
+		Status:   TestStatusFailed,
+		Duration: 500 * time.Millisecond,
+		Failure: &TestFailure{
+			Message:   "Error: expect(page).toHaveTitle(expected)",
+			Backtrace: []string{"at tests/example.spec.ts:5:22"},
+		},
+	}
+	if diff := cmp.Diff(want, spec.attempt(TestStatusFailed)); diff != "" {
+		t.Errorf("PlaywrightSpec.attempt() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlaywrightRun_ResultGlob(t *testing.T) {
+	changeCwd(t, "./testdata/playwright")
+	resultDir := t.TempDir()
+
+	// A report from a previous attempt, which must not be read again.
+	stale := filepath.Join(resultDir, "stale.json")
+	if err := os.WriteFile(stale, []byte(`{"suites": []}`), 0644); err != nil {
+		t.Fatalf("os.WriteFile(%q) error = %v", stale, err)
+	}
+
+	// The command writes a report per project, like separate runs of each project would.
+	playwright := NewPlaywright(RunnerConfig{
+		TestCommand: fmt.Sprintf(`sh -c 'cp reports/*.json "$0"; exit 1' %s`, resultDir),
+		ResultPath:  filepath.Join(resultDir, "*.json"),
+	})
+
+	testCases := []plan.TestCase{{Path: "example.spec.js"}}
+	result := NewRunResult([]plan.TestCase{})
+	err := playwright.Run(result, testCases, false)
+
+	exitError := new(exec.ExitError)
+	assert.ErrorAs(t, err, &exitError)
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(%q) error = %v, want the stale report to be removed", stale, err)
+	}
+
+	if got := result.Statistics().Total; got != 2 {
+		t.Errorf("Playwright.Run() RunResult.Statistics().Total = %d, want %d", got, 2)
+	}
+
+	want := []plan.TestCase{
+		{Scope: " firefox example.spec.js has title", Name: "has title", Path: "example.spec.js:3"},
+	}
+	if diff := cmp.Diff(result.FailedTests(), want); diff != "" {
+		t.Errorf("Playwright.Run() RunResult.FailedTests() diff (-got +want):\n%s", diff)
+	}
+}
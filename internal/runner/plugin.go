@@ -0,0 +1,364 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/debug"
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/kballard/go-shellquote"
+)
+
+// PluginProtocolVersions are the versions of the plugin protocol supported by bktec.
+var PluginProtocolVersions = []int{1}
+
+// Requests of the plugin protocol. The plugin is run once per request, with the request type as its
+// only argument and the request as JSON on stdin, and writes its response as JSON to stdout.
+const (
+	PluginRequestHandshake    = "handshake"
+	PluginRequestDiscover     = "discover"
+	PluginRequestExamples     = "examples"
+	PluginRequestCommand      = "command"
+	PluginRequestParseResults = "parse-results"
+)
+
+// PluginRequest is a request sent to a runner plugin.
+type PluginRequest struct {
+	Type string `json:"type"`
+	// ProtocolVersion is the version of the protocol of the request. In a handshake request, it is the
+	// latest version supported by bktec, and SupportedVersions lists every version it supports.
+	ProtocolVersion   int          `json:"protocol_version"`
+	SupportedVersions []int        `json:"supported_versions,omitempty"`
+	Config            PluginConfig `json:"config"`
+	// Files are the test files to list the examples of, in an examples request.
+	Files []string `json:"files,omitempty"`
+	// Tests are the tests to run in a command request, and the tests that were run in a parse-results request.
+	Tests []plan.TestCase `json:"tests,omitempty"`
+	// Retry is whether the tests of a command request are failed tests being retried.
+	Retry bool `json:"retry,omitempty"`
+	// ExitCode is the exit code of the test command, in a parse-results request.
+	ExitCode int `json:"exit_code,omitempty"`
+}
+
+// PluginConfig is the test runner configuration of bktec, sent with every request.
+type PluginConfig struct {
+	TestCommand            string `json:"test_command,omitempty"`
+	RetryCommand           string `json:"retry_command,omitempty"`
+	TestFilePattern        string `json:"test_file_pattern,omitempty"`
+	TestFileExcludePattern string `json:"test_file_exclude_pattern,omitempty"`
+	ResultPath             string `json:"result_path,omitempty"`
+}
+
+// PluginResponse is the response of a runner plugin. Only the attributes of the request type are set.
+type PluginResponse struct {
+	ProtocolVersion int `json:"protocol_version"`
+	// Error fails the request with the given message.
+	Error string `json:"error,omitempty"`
+
+	// Name is the name of the test runner shown to users, in a handshake response.
+	Name     string         `json:"name,omitempty"`
+	Features PluginFeatures `json:"features"`
+	// ResultPath is the default path of the report of the test runner, in a handshake response.
+	ResultPath string `json:"result_path,omitempty"`
+	// ResultFormat is the format of the report for raw result uploads, "junit" or "json", in a handshake response.
+	// The report isn't uploaded when it is empty.
+	ResultFormat string `json:"result_format,omitempty"`
+
+	// Targets are the test files, or selectors, in a discover response.
+	Targets []string `json:"targets,omitempty"`
+	// Examples are the tests of the files, in an examples response.
+	Examples []plan.TestCase `json:"examples,omitempty"`
+	// Command is the name and arguments of the command running the tests, in a command response.
+	Command []string `json:"command,omitempty"`
+	// Env are additional environment variables of the command, in a command response.
+	Env map[string]string `json:"env,omitempty"`
+	// Results are the results of the tests, in a parse-results response.
+	Results []PluginTestResult `json:"results,omitempty"`
+}
+
+// PluginFeatures are the features supported by a runner plugin.
+type PluginFeatures struct {
+	SplitByFile     bool `json:"split_by_file"`
+	SplitByExample  bool `json:"split_by_example"`
+	SplitBySelector bool `json:"split_by_selector"`
+	FilterTestFiles bool `json:"filter_test_files"`
+	AutoRetry       bool `json:"auto_retry"`
+	Mute            bool `json:"mute"`
+}
+
+// PluginTestResult is the result of a test, in a parse-results response.
+type PluginTestResult struct {
+	Identifier string     `json:"identifier,omitempty"`
+	Scope      string     `json:"scope,omitempty"`
+	Name       string     `json:"name"`
+	Path       string     `json:"path,omitempty"`
+	Status     TestStatus `json:"status"`
+	// Duration is the duration of the test in seconds.
+	Duration float64            `json:"duration,omitempty"`
+	Failure  *PluginTestFailure `json:"failure,omitempty"`
+}
+
+// PluginTestFailure describes why a test failed, in a parse-results response.
+type PluginTestFailure struct {
+	Message   string   `json:"message,omitempty"`
+	Exception string   `json:"exception,omitempty"`
+	Backtrace []string `json:"backtrace,omitempty"`
+}
+
+// TestCase returns the test case of the result, as it's retried.
+func (r PluginTestResult) TestCase() plan.TestCase {
+	return plan.TestCase{
+		Identifier: r.Identifier,
+		Format:     plan.TestCaseFormatExample,
+		Scope:      r.Scope,
+		Name:       r.Name,
+		Path:       r.Path,
+	}
+}
+
+// Attempt returns the result of the test, including its duration and failure.
+func (r PluginTestResult) Attempt() TestAttempt {
+	attempt := TestAttempt{
+		Status:   r.Status,
+		Duration: secondsToDuration(r.Duration),
+	}
+	if r.Failure != nil && r.Status == TestStatusFailed {
+		attempt.Failure = &TestFailure{
+			Message:   r.Failure.Message,
+			Exception: r.Failure.Exception,
+			Backtrace: r.Failure.Backtrace,
+		}
+	}
+	return attempt
+}
+
+// Plugin is a test runner implemented by an external executable, which bktec drives with the requests
+// of the plugin protocol. See docs/runner-plugins.md for the protocol.
+type Plugin struct {
+	RunnerConfig
+	name            string
+	features        PluginFeatures
+	resultFormat    string
+	protocolVersion int
+}
+
+// NewPlugin runs the handshake with the plugin of the config, and returns the runner driving it.
+func NewPlugin(r RunnerConfig) (Plugin, error) {
+	if r.PluginCommand == "" {
+		return Plugin{}, errors.New("runner plugin must be provided for plugin runner")
+	}
+
+	p := Plugin{RunnerConfig: r, protocolVersion: PluginProtocolVersions[len(PluginProtocolVersions)-1]}
+	response, err := p.request(PluginRequest{
+		Type:              PluginRequestHandshake,
+		SupportedVersions: PluginProtocolVersions,
+	})
+	if err != nil {
+		return Plugin{}, err
+	}
+
+	if !slices.Contains(PluginProtocolVersions, response.ProtocolVersion) {
+		return Plugin{}, fmt.Errorf("runner plugin %q uses protocol version %d, supported versions are %v", r.PluginCommand, response.ProtocolVersion, PluginProtocolVersions)
+	}
+
+	if response.Name == "" {
+		return Plugin{}, fmt.Errorf("runner plugin %q didn't return a name in the handshake", r.PluginCommand)
+	}
+
+	p.protocolVersion = response.ProtocolVersion
+	p.name = response.Name
+	p.features = response.Features
+	p.resultFormat = response.ResultFormat
+	if p.ResultPath == "" {
+		p.ResultPath = response.ResultPath
+	}
+
+	return p, nil
+}
+
+func (p Plugin) Name() string {
+	return p.name
+}
+
+func (p Plugin) SupportedFeatures() SupportedFeatures {
+	return SupportedFeatures{
+		SplitByFile:     p.features.SplitByFile,
+		SplitByExample:  p.features.SplitByExample,
+		FilterTestFiles: p.features.FilterTestFiles,
+		FilterTestByTag: false,
+		AutoRetry:       p.features.AutoRetry,
+		Mute:            p.features.Mute,
+		Skip:            false,
+		SplitBySelector: p.features.SplitBySelector,
+	}
+}
+
+func (p Plugin) ResultFormat() string {
+	return p.resultFormat
+}
+
+func (p Plugin) DiscoverTestTargets() ([]string, error) {
+	response, err := p.request(PluginRequest{Type: PluginRequestDiscover})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Targets) == 0 {
+		return nil, fmt.Errorf("runner plugin %q didn't discover any test targets", p.PluginCommand)
+	}
+
+	return response.Targets, nil
+}
+
+func (p Plugin) GetExamples(files []string) ([]plan.TestCase, error) {
+	response, err := p.request(PluginRequest{Type: PluginRequestExamples, Files: files})
+	if err != nil {
+		return nil, err
+	}
+
+	examples := make([]plan.TestCase, len(response.Examples))
+	for i, example := range response.Examples {
+		if example.Format == "" {
+			example.Format = plan.TestCaseFormatExample
+		}
+		examples[i] = example
+	}
+	return examples, nil
+}
+
+func (p Plugin) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
+	response, err := p.commandRequest(testCases, retry)
+	if err != nil {
+		return "", []string{}, err
+	}
+	return response.Command[0], response.Command[1:], nil
+}
+
+func (p Plugin) commandRequest(testCases []plan.TestCase, retry bool) (PluginResponse, error) {
+	response, err := p.request(PluginRequest{Type: PluginRequestCommand, Tests: testCases, Retry: retry})
+	if err != nil {
+		return PluginResponse{}, err
+	}
+
+	if len(response.Command) == 0 {
+		return PluginResponse{}, fmt.Errorf("runner plugin %q returned an empty command", p.PluginCommand)
+	}
+	return response, nil
+}
+
+func (p Plugin) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
+	response, err := p.commandRequest(testCases, retry)
+	if err != nil {
+		return fmt.Errorf("failed to build command: %w", err)
+	}
+
+	cmd := p.command(response)
+	cmdErr := runAndForwardSignal(cmd)
+	exitCode := commandExitCode(cmdErr)
+
+	// The command usually exits with a non-zero status code when there are test failures,
+	// so we should always attempt to parse the results even if the command returns an error.
+	results, parseErr := p.request(PluginRequest{Type: PluginRequestParseResults, Tests: testCases, Retry: retry, ExitCode: exitCode})
+	if parseErr != nil {
+		fmt.Printf("Buildkite Test Engine Client: Failed to read %s output, tests will not be retried: %v\n", p.Name(), parseErr)
+		// We don't want to fail the build if we fail to parse the results,
+		// therefore we return the command error (which can be nil), instead of the parse error.
+		return cmdErr
+	}
+
+	for _, test := range results.Results {
+		result.RecordTestAttempt(test.TestCase(), test.Attempt())
+	}
+
+	// Return any command error after processing the results
+	return cmdErr
+}
+
+// command returns the test command of a command response.
+func (p Plugin) command(response PluginResponse) *exec.Cmd {
+	cmd := exec.Command(response.Command[0], response.Command[1:]...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("BUILDKITE_ANALYTICS_TOKEN=%s", p.UploadToken()))
+	for key, value := range response.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return cmd
+}
+
+// commandExitCode returns the exit code of a command from the error of its run.
+func commandExitCode(err error) int {
+	if exitError := new(exec.ExitError); errors.As(err, &exitError) {
+		return exitError.ExitCode()
+	}
+	return 0
+}
+
+// PluginResponseError is the error of a request that the plugin failed with an error response,
+// as opposed to a plugin that failed to run or returned an invalid response.
+type PluginResponseError struct {
+	Plugin  string
+	Request string
+	Message string
+}
+
+func (e *PluginResponseError) Error() string {
+	return fmt.Sprintf("runner plugin %q failed on %s request: %s", e.Plugin, e.Request, e.Message)
+}
+
+// request runs the plugin with the request, and returns its response.
+// The output of the plugin on stderr is forwarded to the stderr of bktec.
+func (p Plugin) request(request PluginRequest) (PluginResponse, error) {
+	words, err := shellquote.Split(p.PluginCommand)
+	if err != nil {
+		return PluginResponse{}, fmt.Errorf("invalid runner plugin %q: %w", p.PluginCommand, err)
+	}
+
+	if len(words) == 0 {
+		return PluginResponse{}, errors.New("runner plugin is empty")
+	}
+
+	if request.ProtocolVersion == 0 {
+		request.ProtocolVersion = p.protocolVersion
+	}
+	request.Config = PluginConfig{
+		TestCommand:            p.TestCommand,
+		RetryCommand:           p.RetryTestCommand,
+		TestFilePattern:        p.TestFilePattern,
+		TestFileExcludePattern: p.TestFileExcludePattern,
+		ResultPath:             p.ResultPath,
+	}
+
+	input, err := json.Marshal(request)
+	if err != nil {
+		return PluginResponse{}, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(words[0], append(words[1:], request.Type)...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	debug.Printf("Runner plugin %s request took %s", request.Type, time.Since(start))
+	if err != nil {
+		return PluginResponse{}, fmt.Errorf("runner plugin %q failed on %s request: %w", p.PluginCommand, request.Type, err)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return PluginResponse{}, fmt.Errorf("runner plugin %q returned an invalid %s response: %w", p.PluginCommand, request.Type, err)
+	}
+
+	if response.Error != "" {
+		return PluginResponse{}, &PluginResponseError{Plugin: p.PluginCommand, Request: request.Type, Message: response.Error}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,177 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+)
+
+// pluginTestStatuses are the statuses a plugin can report for a test.
+var pluginTestStatuses = []TestStatus{TestStatusPassed, TestStatusFailed, TestStatusSkipped, TestStatusUnknown}
+
+// pluginConformance reports the conformance checks of a runner plugin.
+type pluginConformance struct {
+	plugin   Plugin
+	run      bool
+	out      io.Writer
+	failures int
+}
+
+// pluginConformanceMaxTargets is the maximum number of discovered test targets that the examples are listed of,
+// and that are run.
+const pluginConformanceMaxTargets = 3
+
+// CheckPluginConformance checks that a runner plugin implements the plugin protocol, by sending it each
+// request with the test targets it discovers, and validating its responses. The checks are reported to out,
+// and an error is returned when any of them failed.
+//
+// When run is true, the test command of the discovered test targets is run before the parse-results request.
+// Otherwise, the plugin may not find any results to parse, so an error response to the request is allowed.
+func CheckPluginConformance(p Plugin, out io.Writer, run bool) error {
+	c := &pluginConformance{plugin: p, run: run, out: out}
+	return c.check()
+}
+
+func (c *pluginConformance) check() error {
+	p := c.plugin
+	c.pass("handshake", "%s uses protocol version %d", p.Name(), p.protocolVersion)
+	if p.resultFormat != "" && p.resultFormat != "junit" && p.resultFormat != "json" {
+		c.fail("handshake", "result_format %q is invalid, possible values are junit, json", p.resultFormat)
+	}
+
+	targets, err := p.DiscoverTestTargets()
+	if err != nil {
+		c.fail("discover", "%v", err)
+		return c.result()
+	}
+	c.checkTargets(targets)
+	targets = targets[:min(len(targets), pluginConformanceMaxTargets)]
+
+	tests := testCasesFromPaths(targets)
+	if p.features.SplitByExample {
+		if examples := c.checkExamples(targets); len(examples) > 0 {
+			tests = examples
+		}
+	} else {
+		c.skip("examples", "split_by_example isn't supported")
+	}
+
+	response, err := p.commandRequest(tests, false)
+	if err != nil {
+		c.fail("command", "%v", err)
+	} else {
+		c.pass("command", "%v", response.Command)
+	}
+
+	if p.features.AutoRetry {
+		if response, err := p.commandRequest(tests[:1], true); err != nil {
+			c.fail("command (retry)", "%v", err)
+		} else {
+			c.pass("command (retry)", "%v", response.Command)
+		}
+	} else {
+		c.skip("command (retry)", "auto_retry isn't supported")
+	}
+
+	c.checkParseResults(tests, response)
+	return c.result()
+}
+
+func (c *pluginConformance) checkTargets(targets []string) {
+	for i, target := range targets {
+		if target == "" {
+			c.fail("discover", "target %d is empty", i+1)
+			return
+		}
+		if slices.Contains(targets[:i], target) {
+			c.fail("discover", "target %q is discovered more than once", target)
+			return
+		}
+	}
+	c.pass("discover", "%d test targets", len(targets))
+}
+
+func (c *pluginConformance) checkExamples(files []string) []plan.TestCase {
+	examples, err := c.plugin.GetExamples(files)
+	if err != nil {
+		c.fail("examples", "%v", err)
+		return nil
+	}
+
+	if len(examples) == 0 {
+		c.fail("examples", "no examples in %v", files)
+		return nil
+	}
+
+	for i, example := range examples {
+		if example.Path == "" || example.Name == "" {
+			c.fail("examples", "example %d doesn't have a path and a name: %+v", i+1, example)
+			return nil
+		}
+	}
+
+	c.pass("examples", "%d examples in %d test targets", len(examples), len(files))
+	return examples
+}
+
+func (c *pluginConformance) checkParseResults(tests []plan.TestCase, command PluginResponse) {
+	request := PluginRequest{Type: PluginRequestParseResults, Tests: tests}
+	if c.run {
+		if len(command.Command) == 0 {
+			c.skip("parse-results", "the command request failed")
+			return
+		}
+		request.ExitCode = commandExitCode(runAndForwardSignal(c.plugin.command(command)))
+	}
+
+	response, err := c.plugin.request(request)
+	if responseErr := new(PluginResponseError); errors.As(err, &responseErr) && !c.run {
+		c.skip("parse-results", "%s, set --run to run the tests before parsing their results", responseErr.Message)
+		return
+	}
+	if err != nil {
+		c.fail("parse-results", "%v", err)
+		return
+	}
+
+	for i, result := range response.Results {
+		if result.Name == "" {
+			c.fail("parse-results", "result %d doesn't have a name", i+1)
+			return
+		}
+		if !slices.Contains(pluginTestStatuses, result.Status) {
+			c.fail("parse-results", "result %d has an invalid status %q", i+1, result.Status)
+			return
+		}
+	}
+
+	if c.run && len(response.Results) == 0 {
+		c.fail("parse-results", "no results after running the tests")
+		return
+	}
+
+	c.pass("parse-results", "%d results", len(response.Results))
+}
+
+func (c *pluginConformance) pass(check, format string, args ...any) {
+	fmt.Fprintf(c.out, "✅ %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (c *pluginConformance) skip(check, format string, args ...any) {
+	fmt.Fprintf(c.out, "⏭️ %s: skipped, %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (c *pluginConformance) fail(check, format string, args ...any) {
+	c.failures++
+	fmt.Fprintf(c.out, "❌ %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (c *pluginConformance) result() error {
+	if c.failures > 0 {
+		return fmt.Errorf("runner plugin %q failed %d conformance checks", c.plugin.PluginCommand, c.failures)
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+)
+
+const fakePlugin = "sh ./testdata/plugin/plugin.sh"
+
+func newFakePlugin(t *testing.T, c RunnerConfig) Plugin {
+	t.Helper()
+	c.PluginCommand = fakePlugin
+	p, err := NewPlugin(c)
+	if err != nil {
+		t.Fatalf("NewPlugin() error = %v", err)
+	}
+	return p
+}
+
+// readPluginRequest returns the last request of the type received by the fake plugin.
+func readPluginRequest(t *testing.T, dir, requestType string) PluginRequest {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, requestType+".json"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	var request PluginRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return request
+}
+
+func TestNewPlugin(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PLUGIN_REQUEST_DIR", dir)
+
+	p := newFakePlugin(t, RunnerConfig{TestFilePattern: "**/BUILD"})
+
+	if p.Name() != "Fake" {
+		t.Errorf("Name() = %q, want %q", p.Name(), "Fake")
+	}
+	if p.ResultPath != "tmp/results.json" {
+		t.Errorf("ResultPath = %q, want %q", p.ResultPath, "tmp/results.json")
+	}
+	if p.ResultFormat() != "json" {
+		t.Errorf("ResultFormat() = %q, want %q", p.ResultFormat(), "json")
+	}
+
+	wantFeatures := SupportedFeatures{SplitByFile: true, SplitByExample: true, AutoRetry: true, Mute: true}
+	if diff := cmp.Diff(p.SupportedFeatures(), wantFeatures); diff != "" {
+		t.Errorf("SupportedFeatures() diff (-got +want):\n%s", diff)
+	}
+
+	want := PluginRequest{
+		Type:              PluginRequestHandshake,
+		ProtocolVersion:   1,
+		SupportedVersions: []int{1},
+		Config:            PluginConfig{TestFilePattern: "**/BUILD"},
+	}
+	if diff := cmp.Diff(readPluginRequest(t, dir, PluginRequestHandshake), want); diff != "" {
+		t.Errorf("handshake request diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestNewPlugin_ResultPathFromConfig(t *testing.T) {
+	p := newFakePlugin(t, RunnerConfig{ResultPath: "reports/results.json"})
+
+	if p.ResultPath != "reports/results.json" {
+		t.Errorf("ResultPath = %q, want %q", p.ResultPath, "reports/results.json")
+	}
+}
+
+func TestNewPlugin_UnsupportedProtocolVersion(t *testing.T) {
+	t.Setenv("PLUGIN_PROTOCOL_VERSION", "2")
+
+	_, err := NewPlugin(RunnerConfig{PluginCommand: fakePlugin})
+
+	want := `runner plugin "sh ./testdata/plugin/plugin.sh" uses protocol version 2, supported versions are [1]`
+	if err == nil || err.Error() != want {
+		t.Errorf("NewPlugin() error = %v, want %q", err, want)
+	}
+}
+
+func TestNewPlugin_MissingPlugin(t *testing.T) {
+	_, err := NewPlugin(RunnerConfig{})
+
+	if err == nil || err.Error() != "runner plugin must be provided for plugin runner" {
+		t.Errorf("NewPlugin() error = %v, want %q", err, "runner plugin must be provided for plugin runner")
+	}
+}
+
+func TestNewPlugin_PluginFails(t *testing.T) {
+	_, err := NewPlugin(RunnerConfig{PluginCommand: "sh -c 'exit 2'"})
+
+	if exitError := new(exec.ExitError); !errors.As(err, &exitError) {
+		t.Errorf("NewPlugin() error = %v, want an exec.ExitError", err)
+	}
+}
+
+func TestPlugin_DiscoverTestTargets(t *testing.T) {
+	p := newFakePlugin(t, RunnerConfig{})
+
+	got, err := p.DiscoverTestTargets()
+	if err != nil {
+		t.Errorf("DiscoverTestTargets() error = %v", err)
+	}
+
+	want := []string{"//math:test", "//strings:test"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("DiscoverTestTargets() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestPlugin_DiscoverTestTargets_ErrorResponse(t *testing.T) {
+	p := newFakePlugin(t, RunnerConfig{})
+	t.Setenv("PLUGIN_ERROR", PluginRequestDiscover)
+
+	_, err := p.DiscoverTestTargets()
+
+	want := `runner plugin "sh ./testdata/plugin/plugin.sh" failed on discover request: something went wrong`
+	if responseErr := new(PluginResponseError); !errors.As(err, &responseErr) || err.Error() != want {
+		t.Errorf("DiscoverTestTargets() error = %v, want %q", err, want)
+	}
+}
+
+func TestPlugin_GetExamples(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PLUGIN_REQUEST_DIR", dir)
+	p := newFakePlugin(t, RunnerConfig{})
+
+	got, err := p.GetExamples([]string{"//math:test", "//strings:test"})
+	if err != nil {
+		t.Errorf("GetExamples() error = %v", err)
+	}
+
+	want := []plan.TestCase{
+		{Format: plan.TestCaseFormatExample, Scope: "math", Name: "adds", Path: "//math:test"},
+		{Format: plan.TestCaseFormatExample, Scope: "math", Name: "divides", Path: "//math:test"},
+		{Format: plan.TestCaseFormatExample, Scope: "strings", Name: "joins", Path: "//strings:test"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("GetExamples() diff (-got +want):\n%s", diff)
+	}
+
+	request := readPluginRequest(t, dir, PluginRequestExamples)
+	if diff := cmp.Diff(request.Files, []string{"//math:test", "//strings:test"}); diff != "" {
+		t.Errorf("examples request files diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestPlugin_CommandNameAndArgs(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PLUGIN_REQUEST_DIR", dir)
+	p := newFakePlugin(t, RunnerConfig{})
+	testCases := []plan.TestCase{{Scope: "math", Name: "divides", Path: "//math:test"}}
+
+	gotName, gotArgs, err := p.CommandNameAndArgs(testCases, true)
+	if err != nil {
+		t.Errorf("CommandNameAndArgs() error = %v", err)
+	}
+
+	if gotName != "sh" {
+		t.Errorf("CommandNameAndArgs() name = %q, want %q", gotName, "sh")
+	}
+	if diff := cmp.Diff(gotArgs, []string{"-c", "echo $FAKE_TARGET; exit 3"}); diff != "" {
+		t.Errorf("CommandNameAndArgs() args diff (-got +want):\n%s", diff)
+	}
+
+	request := readPluginRequest(t, dir, PluginRequestCommand)
+	if !request.Retry {
+		t.Errorf("command request retry = false, want true")
+	}
+	if diff := cmp.Diff(request.Tests, testCases); diff != "" {
+		t.Errorf("command request tests diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestPlugin_Run(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PLUGIN_REQUEST_DIR", dir)
+	p := newFakePlugin(t, RunnerConfig{})
+	testCases := []plan.TestCase{{Path: "//math:test"}}
+
+	result := NewRunResult([]plan.TestCase{})
+	err := p.Run(result, testCases, false)
+
+	if exitError := new(exec.ExitError); !errors.As(err, &exitError) || exitError.ExitCode() != 3 {
+		t.Errorf("Run() error = %v, want exit status 3", err)
+	}
+
+	request := readPluginRequest(t, dir, PluginRequestParseResults)
+	if request.ExitCode != 3 {
+		t.Errorf("parse-results request exit code = %d, want 3", request.ExitCode)
+	}
+
+	want := []TestResult{
+		{
+			TestCase:       plan.TestCase{Format: plan.TestCaseFormatExample, Scope: "math", Name: "adds", Path: "//math:test"},
+			Status:         TestStatusPassed,
+			ExecutionCount: 1,
+			Attempts:       []TestAttempt{{Status: TestStatusPassed, Duration: 500 * time.Millisecond}},
+		},
+		{
+			TestCase:       plan.TestCase{Format: plan.TestCaseFormatExample, Scope: "math", Name: "divides", Path: "//math:test"},
+			Status:         TestStatusFailed,
+			ExecutionCount: 1,
+			Attempts: []TestAttempt{{
+				Status:   TestStatusFailed,
+				Duration: 1250 * time.Millisecond,
+				Failure:  &TestFailure{Message: "division by zero", Backtrace: []string{"math_test.go:12"}},
+			}},
+		},
+	}
+	if diff := cmp.Diff(result.Tests(), want); diff != "" {
+		t.Errorf("Run() tests diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestPlugin_Run_ParseResultsFails(t *testing.T) {
+	p := newFakePlugin(t, RunnerConfig{})
+	t.Setenv("PLUGIN_ERROR", PluginRequestParseResults)
+
+	result := NewRunResult([]plan.TestCase{})
+	err := p.Run(result, []plan.TestCase{{Path: "//math:test"}}, false)
+
+	if exitError := new(exec.ExitError); !errors.As(err, &exitError) {
+		t.Errorf("Run() error = %v, want the error of the command", err)
+	}
+	if len(result.Tests()) != 0 {
+		t.Errorf("Run() recorded %d tests, want 0", len(result.Tests()))
+	}
+}
+
+func TestCheckPluginConformance(t *testing.T) {
+	p := newFakePlugin(t, RunnerConfig{})
+
+	var out bytes.Buffer
+	if err := CheckPluginConformance(p, &out, false); err != nil {
+		t.Errorf("CheckPluginConformance() error = %v\n%s", err, out.String())
+	}
+
+	want := `✅ handshake: Fake uses protocol version 1
+✅ discover: 2 test targets
+✅ examples: 3 examples in 2 test targets
+✅ command: [sh -c echo $FAKE_TARGET; exit 3]
+✅ command (retry): [sh -c echo $FAKE_TARGET; exit 3]
+✅ parse-results: 2 results
+`
+	if diff := cmp.Diff(out.String(), want); diff != "" {
+		t.Errorf("CheckPluginConformance() output diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestCheckPluginConformance_ParseResultsErrorWithoutRun(t *testing.T) {
+	p := newFakePlugin(t, RunnerConfig{})
+	t.Setenv("PLUGIN_ERROR", PluginRequestParseResults)
+
+	var out bytes.Buffer
+	if err := CheckPluginConformance(p, &out, false); err != nil {
+		t.Errorf("CheckPluginConformance() error = %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "⏭️ parse-results: skipped, something went wrong") {
+		t.Errorf("CheckPluginConformance() output = %q, want parse-results to be skipped", out.String())
+	}
+}
+
+func TestCheckPluginConformance_Failures(t *testing.T) {
+	p := newFakePlugin(t, RunnerConfig{})
+	t.Setenv("PLUGIN_ERROR", PluginRequestExamples)
+
+	var out bytes.Buffer
+	err := CheckPluginConformance(p, &out, false)
+
+	want := `runner plugin "sh ./testdata/plugin/plugin.sh" failed 1 conformance checks`
+	if err == nil || err.Error() != want {
+		t.Errorf("CheckPluginConformance() error = %v, want %q", err, want)
+	}
+	if !strings.Contains(out.String(), "❌ examples: ") {
+		t.Errorf("CheckPluginConformance() output = %q, want a failed examples check", out.String())
+	}
+}
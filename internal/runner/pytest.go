@@ -111,10 +111,11 @@ func (p Pytest) SupportedFeatures() SupportedFeatures {
 }
 
 func (p Pytest) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(p, testCases, retry)
+	cmd, cleanup, err := buildCommand(p, testCases, retry)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 
 	cmdErr := runAndForwardSignal(cmd)
 	parseExit2JSON := false
@@ -164,7 +165,7 @@ const pytestCollectionErrorTag = "test.pytest_collection_error"
 func recordPytestJSONTestResult(result *RunResult, test TestEngineTest) {
 	path := pytestPathFromTestEngineResult(test.Scope, test.Name)
 
-	result.RecordTestResult(plan.TestCase{
+	result.RecordTestAttempt(plan.TestCase{
 		Identifier: test.ID,
 		Format:     plan.TestCaseFormatExample,
 		Scope:      test.Scope,
@@ -173,7 +174,7 @@ func recordPytestJSONTestResult(result *RunResult, test TestEngineTest) {
 		// class names (if any), and functions separated by `::`.
 		// Ref: https://docs.pytest.org/en/6.2.x/usage.html#nodeids
 		Path: path,
-	}, test.Result)
+	}, test.Attempt())
 
 	if test.Tags[pytestCollectionErrorTag] == "true" {
 		result.error = fmt.Errorf("pytest collection failed: %s", path)
@@ -196,14 +197,14 @@ func (p Pytest) runParseJUnit(result *RunResult) error {
 
 	for _, test := range tests {
 		path := pytestNodeIDFromJUnit(test.Classname, test.Name)
-		result.RecordTestResult(plan.TestCase{
+		result.RecordTestAttempt(plan.TestCase{
 			Identifier: path,
 			Format:     plan.TestCaseFormatExample,
 			// JUnit XML ingestion set Scope to the raw classname
 			Scope: test.Classname,
 			Name:  test.Name,
 			Path:  path,
-		}, test.Result)
+		}, test.Attempt())
 	}
 
 	return nil
@@ -392,19 +393,23 @@ func collectorScopeToJunitScope(scope string) string {
 	return classname
 }
 
+func (p Pytest) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	return pathsFromTestCases(testCases)
+}
+
 func (p Pytest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
 	cmd := p.TestCommand
 	if retry {
 		cmd = p.RetryTestCommand
 	}
 
-	testPaths := pathsFromTestCases(testCases)
+	testPaths := p.testListPaths(testCases, retry)
 
 	testExamples := shellquote.Join(testPaths...)
 
 	if strings.Contains(cmd, "{{testExamples}}") {
 		cmd = strings.Replace(cmd, "{{testExamples}}", testExamples, 1)
-	} else {
+	} else if !strings.Contains(cmd, testListFilePlaceholder) {
 		cmd = cmd + " " + testExamples
 	}
 
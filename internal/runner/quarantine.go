@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"gopkg.in/yaml.v3"
+)
+
+// Quarantine is a list of tests to mute, read from a quarantine file checked into the repository.
+// Unlike the muted tests from Test Engine, it doesn't depend on the server, so it is also honoured
+// when the test plan is a fallback plan.
+type Quarantine struct {
+	Tests []QuarantinedTest `yaml:"tests"`
+}
+
+// QuarantinedTest matches the tests to mute by their scope, name and path.
+// Each of them is a glob pattern, where "*" matches any sequence of characters
+// and "?" matches any single character. A test matches when it matches all of
+// the patterns that are set.
+type QuarantinedTest struct {
+	Scope string `yaml:"scope"`
+	Name  string `yaml:"name"`
+	Path  string `yaml:"path"`
+	// Reason documents why the test is quarantined. It isn't used by bktec.
+	Reason string `yaml:"reason"`
+
+	scope *regexp.Regexp
+	name  *regexp.Regexp
+	path  *regexp.Regexp
+}
+
+// LoadQuarantineFile reads a quarantine file in YAML or JSON, for example:
+//
+//	tests:
+//	  - scope: "User#save"
+//	    name: "persists the record"
+//	    reason: "Flaky, see #1234"
+//	  - path: "spec/system/*"
+func LoadQuarantineFile(path string) (Quarantine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Quarantine{}, fmt.Errorf("failed to read quarantine file: %w", err)
+	}
+
+	// JSON is a subset of YAML, so both formats are read by the YAML decoder.
+	var q Quarantine
+	if err := yaml.Unmarshal(data, &q); err != nil {
+		return Quarantine{}, fmt.Errorf("failed to parse quarantine file %s: %w", path, err)
+	}
+
+	for i := range q.Tests {
+		if err := q.Tests[i].compile(); err != nil {
+			return Quarantine{}, fmt.Errorf("invalid test %d in quarantine file %s: %w", i+1, path, err)
+		}
+	}
+
+	return q, nil
+}
+
+func (t *QuarantinedTest) compile() error {
+	if t.Scope == "" && t.Name == "" && t.Path == "" {
+		return errors.New("at least one of scope, name or path is required")
+	}
+
+	t.scope = globPattern(t.Scope)
+	t.name = globPattern(t.Name)
+	t.path = globPattern(t.Path)
+	return nil
+}
+
+// Matches reports whether the test case is quarantined.
+func (q Quarantine) Matches(testCase plan.TestCase) bool {
+	for _, t := range q.Tests {
+		if matchesGlob(t.scope, testCase.Scope) && matchesGlob(t.name, testCase.Name) && matchesGlob(t.path, testCase.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// globPattern compiles a glob pattern into a regular expression matching the whole string.
+// It returns nil for an empty pattern, which matches anything.
+func globPattern(glob string) *regexp.Regexp {
+	if glob == "" {
+		return nil
+	}
+
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+func matchesGlob(pattern *regexp.Regexp, s string) bool {
+	return pattern == nil || pattern.MatchString(s)
+}
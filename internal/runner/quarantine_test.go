@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+)
+
+func writeQuarantineFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("os.WriteFile(%q) error = %v", path, err)
+	}
+	return path
+}
+
+func TestLoadQuarantineFile_YAML(t *testing.T) {
+	path := writeQuarantineFile(t, "quarantine.yml", `
+tests:
+  - scope: "User#save"
+    name: "persists the record"
+    reason: "Flaky, see #1234"
+  - path: "spec/system/*"
+`)
+
+	q, err := LoadQuarantineFile(path)
+	if err != nil {
+		t.Fatalf("LoadQuarantineFile(%q) error = %v", path, err)
+	}
+
+	if len(q.Tests) != 2 {
+		t.Fatalf("len(Tests) = %d, want 2", len(q.Tests))
+	}
+	if q.Tests[0].Reason != "Flaky, see #1234" {
+		t.Errorf("Tests[0].Reason = %q, want %q", q.Tests[0].Reason, "Flaky, see #1234")
+	}
+}
+
+func TestLoadQuarantineFile_JSON(t *testing.T) {
+	path := writeQuarantineFile(t, "quarantine.json", `{"tests": [{"scope": "User#save", "name": "persists *"}]}`)
+
+	q, err := LoadQuarantineFile(path)
+	if err != nil {
+		t.Fatalf("LoadQuarantineFile(%q) error = %v", path, err)
+	}
+
+	if !q.Matches(plan.TestCase{Scope: "User#save", Name: "persists the record"}) {
+		t.Errorf("Matches() = false, want true")
+	}
+}
+
+func TestLoadQuarantineFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{
+			name:    "missing file",
+			path:    filepath.Join(t.TempDir(), "missing.yml"),
+			wantErr: "failed to read quarantine file",
+		},
+		{
+			name:    "invalid syntax",
+			path:    writeQuarantineFile(t, "invalid.yml", "tests: [\n"),
+			wantErr: "failed to parse quarantine file",
+		},
+		{
+			name:    "empty entry",
+			path:    writeQuarantineFile(t, "empty.yml", "tests:\n  - reason: \"no pattern\"\n"),
+			wantErr: "invalid test 1 in quarantine file",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadQuarantineFile(tc.path)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LoadQuarantineFile(%q) error = %v, want it to contain %q", tc.path, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuarantineMatches(t *testing.T) {
+	q := Quarantine{Tests: []QuarantinedTest{
+		{Scope: "User#save", Name: "persists the record"},
+		{Path: "spec/system/*"},
+		{Scope: "TestParse?", Name: "*"},
+	}}
+	for i := range q.Tests {
+		if err := q.Tests[i].compile(); err != nil {
+			t.Fatalf("compile() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		testCase plan.TestCase
+		want     bool
+	}{
+		{testCase: plan.TestCase{Scope: "User#save", Name: "persists the record", Path: "spec/models/user_spec.rb:10"}, want: true},
+		{testCase: plan.TestCase{Scope: "User#save", Name: "persists the record twice"}, want: false},
+		{testCase: plan.TestCase{Scope: "Checkout", Name: "pays", Path: "spec/system/checkout_spec.rb:3"}, want: true},
+		{testCase: plan.TestCase{Scope: "Checkout", Name: "pays", Path: "spec/models/checkout_spec.rb:3"}, want: false},
+		{testCase: plan.TestCase{Scope: "TestParse1", Name: "TestParse1/empty"}, want: true},
+		{testCase: plan.TestCase{Scope: "TestParse12", Name: "TestParse12/empty"}, want: false},
+		// Regular expression characters in patterns are matched literally.
+		{testCase: plan.TestCase{Scope: "User.save", Name: "persists the record"}, want: false},
+	}
+
+	for _, tc := range tests {
+		got := q.Matches(tc.testCase)
+		if got != tc.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tc.testCase, got, tc.want)
+		}
+	}
+}
+
+func TestQuarantineMatches_Empty(t *testing.T) {
+	if (Quarantine{}).Matches(plan.TestCase{Scope: "User#save", Name: "persists the record"}) {
+		t.Errorf("Matches() = true, want false")
+	}
+}
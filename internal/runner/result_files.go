@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"drjosh.dev/zzglob"
+)
+
+// ResultFiles returns the files matching a result path, which can be a glob for runners that write
+// several reports per run, such as "test/reports/TEST-*.xml". Patterns with "**" match any number of
+// directories, e.g. "**/build/test-results/**/TEST-*.xml" for the reports of every module of a Gradle build.
+// A result path without wildcards matches the file if it exists.
+func ResultFiles(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid result path %q: %w", pattern, err)
+		}
+		return files, nil
+	}
+
+	parsedPattern, err := zzglob.Parse(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result path %q: %w", pattern, err)
+	}
+
+	// Only the directory before the first wildcard has to be walked.
+	root := resultFilesRoot(pattern)
+	files := []string{}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == "node_modules" || d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if parsedPattern.Match(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find result files matching %q: %w", pattern, err)
+	}
+
+	return files, nil
+}
+
+// isResultPathGlob reports whether the result path is a glob matching several report files,
+// rather than the path of a single report.
+func isResultPathGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// resultFilesRoot returns the directory of the pattern before its first wildcard.
+func resultFilesRoot(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	var static []string
+	for _, segment := range segments[:len(segments)-1] {
+		if strings.ContainsAny(segment, "*?[{") {
+			break
+		}
+		static = append(static, segment)
+	}
+
+	root := strings.Join(static, "/")
+	if root == "" {
+		if strings.HasPrefix(pattern, "/") {
+			return "/"
+		}
+		return "."
+	}
+	return root
+}
+
+// removeResultFiles removes the files matching the result path. Reports from a previous attempt
+// would otherwise be read again, and tests that are not part of the attempt would be counted twice.
+func removeResultFiles(pattern string) error {
+	files, err := ResultFiles(pattern)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("failed to remove previous result file: %w", err)
+		}
+	}
+	return nil
+}
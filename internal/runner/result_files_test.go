@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResultFiles(t *testing.T) {
+	dir := t.TempDir()
+	changeCwd(t, dir)
+
+	for _, file := range []string{
+		"build/test-results/test/TEST-UserTest.xml",
+		"app/build/test-results/test/TEST-PostTest.xml",
+		"app/build/test-results/test/binary/output.bin",
+		"node_modules/app/build/test-results/TEST-Dependency.xml",
+	} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ResultFiles("**/build/test-results/**/TEST-*.xml")
+	if err != nil {
+		t.Fatalf("ResultFiles() error = %v", err)
+	}
+
+	want := []string{
+		"app/build/test-results/test/TEST-PostTest.xml",
+		"build/test-results/test/TEST-UserTest.xml",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ResultFiles() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestResultFiles_MissingDirectory(t *testing.T) {
+	got, err := ResultFiles(filepath.Join(t.TempDir(), "target", "**", "TEST-*.xml"))
+	if err != nil {
+		t.Fatalf("ResultFiles() error = %v", err)
+	}
+
+	if len(got) != 0 {
+		t.Errorf("ResultFiles() = %q, want no files", got)
+	}
+}
+
+func TestRemoveResultFiles(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "app", "TEST-UserTest.xml")
+	if err := os.MkdirAll(filepath.Dir(report), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(report, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := removeResultFiles(filepath.Join(dir, "**", "TEST-*.xml")); err != nil {
+		t.Fatalf("removeResultFiles() error = %v", err)
+	}
+
+	if _, err := os.Stat(report); !os.IsNotExist(err) {
+		t.Errorf("removeResultFiles() didn't remove %s", report)
+	}
+}
@@ -89,10 +89,11 @@ func (r Rspec) DiscoverTestTargets() ([]string, error) {
 //
 // Test failure is not considered an error, and is instead returned as a RunResult.
 func (r Rspec) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(r, testCases, retry)
+	cmd, cleanup, err := buildCommand(r, testCases, retry)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 
 	cmdErr := runAndForwardSignal(cmd)
 
@@ -119,7 +120,16 @@ func (r Rspec) Run(result *RunResult, testCases []plan.TestCase, retry bool) err
 			status = TestStatusUnknown
 		}
 
-		result.RecordTestResult(mapExampleToTestCase(example), status)
+		attempt := TestAttempt{Status: status, Duration: secondsToDuration(example.RunTime)}
+		if example.Exception != nil {
+			attempt.Failure = &TestFailure{
+				Message:   strings.TrimSpace(example.Exception.Message),
+				Exception: example.Exception.Class,
+				Backtrace: example.Exception.Backtrace,
+			}
+		}
+
+		result.RecordTestAttempt(mapExampleToTestCase(example), attempt)
 	}
 
 	if report.Summary.ErrorsOutsideOfExamplesCount > 0 {
@@ -139,6 +149,15 @@ type RspecExample struct {
 	FilePath        string  `json:"file_path"`
 	LineNumber      int     `json:"line_number"`
 	RunTime         float64 `json:"run_time"`
+	// Exception is set when the example failed.
+	Exception *RspecException `json:"exception,omitempty"`
+}
+
+// RspecException represents the exception of a failed example in an Rspec report.
+type RspecException struct {
+	Class     string   `json:"class"`
+	Message   string   `json:"message"`
+	Backtrace []string `json:"backtrace"`
 }
 
 // RspecReport is the structure for Rspec JSON report.
@@ -168,6 +187,10 @@ func (r Rspec) ParseReport(path string) (RspecReport, error) {
 	return report, nil
 }
 
+func (r Rspec) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	return pathsFromTestCases(testCases)
+}
+
 // CommandNameAndArgs replaces the "{{testExamples}}" placeholder in the test command with the test cases.
 // It returns the command name and arguments to run the tests.
 func (r Rspec) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
@@ -181,13 +204,13 @@ func (r Rspec) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string
 		return "", []string{}, err
 	}
 
-	testPaths := pathsFromTestCases(testCases)
+	testPaths := r.testListPaths(testCases, retry)
 
 	idx := slices.Index(words, "{{testExamples}}")
-	if idx < 0 {
-		words = append(words, testPaths...)
-	} else {
+	if idx >= 0 {
 		words = slices.Replace(words, idx, idx+1, testPaths...)
+	} else if !hasTestListFile(words) {
+		words = append(words, testPaths...)
 	}
 
 	idx = slices.Index(words, "{{resultPath}}")
@@ -219,6 +242,13 @@ func (r Rspec) GetExamples(files []string) ([]plan.TestCase, error) {
 		return nil, err
 	}
 
+	words, cleanup, err := writeTestListFile(append([]string{cmdName}, cmdArgs...), r.testListPaths(testCases, false))
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	cmdName, cmdArgs = words[0], words[1:]
+
 	cmdArgs = append(cmdArgs, "--dry-run", "--format", "json", "--out", f.Name(), "--format", "progress")
 
 	debug.Printf("Running `%s %s` for dry run", cmdName, strings.Join(cmdArgs, " "))
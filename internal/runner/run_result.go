@@ -1,9 +1,12 @@
 package runner
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/buildkite/test-engine-client/v3/internal/plan"
 )
@@ -30,7 +33,9 @@ type RunResult struct {
 	// mutedTestLookup is a map containing the test identifiers of muted tests.
 	// This list might contain tests that are not part of the current run (i.e. belong to a different node).
 	mutedTestLookup map[string]bool
-	error           error
+	// quarantine is the list of tests muted by the quarantine file.
+	quarantine Quarantine
+	error      error
 }
 
 func NewRunResult(mutedTests []plan.TestCase) *RunResult {
@@ -46,6 +51,11 @@ func NewRunResult(mutedTests []plan.TestCase) *RunResult {
 	return r
 }
 
+// MuteQuarantinedTests marks the tests matching the quarantine as muted, in addition to the muted tests from Test Engine.
+func (r *RunResult) MuteQuarantinedTests(q Quarantine) {
+	r.quarantine = q
+}
+
 // getTest finds or creates a TestResult struct for a given test case
 // in the tests map, and returns a pointer to it.
 func (r *RunResult) getTest(testCase plan.TestCase) *TestResult {
@@ -68,14 +78,56 @@ func (r *RunResult) getTest(testCase plan.TestCase) *TestResult {
 // RecordTestResult records the result of a test case.
 // If the test case found in the mutedTestLookup, it will be marked as muted.
 func (r *RunResult) RecordTestResult(testCase plan.TestCase, status TestStatus) {
+	r.RecordTestAttempt(testCase, TestAttempt{Status: status})
+}
+
+// RecordTestAttempt records the result of a test case, including the details of the attempt
+// such as its duration and failure.
+// If the test case found in the mutedTestLookup, it will be marked as muted.
+func (r *RunResult) RecordTestAttempt(testCase plan.TestCase, attempt TestAttempt) {
 	test := r.getTest(testCase)
-	test.Status = status
+	test.Status = attempt.Status
 	test.ExecutionCount++
-	if r.mutedTestLookup[mutedTestIdentifier(testCase)] {
+	test.Attempts = append(test.Attempts, attempt)
+	if r.mutedTestLookup[mutedTestIdentifier(testCase)] || r.quarantine.Matches(testCase) {
 		test.Muted = true
 	}
 }
 
+// Tests returns the results of all tests in the run, sorted by path, scope and name.
+func (r *RunResult) Tests() []TestResult {
+	tests := make([]TestResult, 0, len(r.tests))
+	for _, test := range r.tests {
+		tests = append(tests, *test)
+	}
+
+	slices.SortFunc(tests, func(a, b TestResult) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Scope, b.Scope),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return tests
+}
+
+// SlowestTests returns up to n tests with the longest total duration, slowest first.
+// Tests without a reported duration are left out.
+func (r *RunResult) SlowestTests(n int) []TestResult {
+	var tests []TestResult
+	for _, test := range r.Tests() {
+		if test.Duration() > 0 {
+			tests = append(tests, test)
+		}
+	}
+
+	// Tests() is already sorted by path, so a stable sort keeps ties in a consistent order.
+	slices.SortStableFunc(tests, func(a, b TestResult) int {
+		return cmp.Compare(b.Duration(), a.Duration())
+	})
+	return tests[:min(n, len(tests))]
+}
+
 // FailedTests returns a list of test cases that failed.
 func (r *RunResult) FailedTests() []plan.TestCase {
 	var failedTests []plan.TestCase
@@ -232,18 +284,54 @@ func (r *RunResult) Statistics() RunStatistics {
 }
 
 // TestEngineTest represents a Test Engine test result object.
-// Some attributes such as `history` and `failure_reason` are omitted as they are not needed by bktec.
+// Only the attributes needed by bktec are included.
 // Ref: https://buildkite.com/docs/test-engine/importing-json#json-test-results-data-reference-test-result-objects
 //
 // Currently, only pytest and custom runner uses result from test collector.
 type TestEngineTest struct {
-	ID       string
-	Name     string
-	Scope    string
-	Location string
-	FileName string `json:"file_name,omitempty"`
-	Result   TestStatus
-	Tags     map[string]string `json:"tags,omitempty"`
+	ID              string
+	Name            string
+	Scope           string
+	Location        string
+	FileName        string `json:"file_name,omitempty"`
+	Result          TestStatus
+	Tags            map[string]string        `json:"tags,omitempty"`
+	FailureReason   string                   `json:"failure_reason,omitempty"`
+	FailureExpanded []TestEngineFailureEntry `json:"failure_expanded,omitempty"`
+	History         struct {
+		// Duration is the duration of the test in seconds.
+		Duration float64 `json:"duration"`
+	} `json:"history"`
+}
+
+// TestEngineFailureEntry is an entry of the `failure_expanded` attribute of a Test Engine test result.
+type TestEngineFailureEntry struct {
+	Expanded  []string `json:"expanded"`
+	Backtrace []string `json:"backtrace"`
+}
+
+// Attempt returns the result of the test, including its duration and failure.
+func (t TestEngineTest) Attempt() TestAttempt {
+	attempt := TestAttempt{
+		Status:   t.Result,
+		Duration: secondsToDuration(t.History.Duration),
+	}
+
+	if t.FailureReason == "" && len(t.FailureExpanded) == 0 {
+		return attempt
+	}
+
+	// The failure reason is a summary of the failure, and the expanded failure
+	// has the details of each error, e.g. the expected and actual values.
+	message := []string{t.FailureReason}
+	failure := &TestFailure{}
+	for _, entry := range t.FailureExpanded {
+		message = append(message, entry.Expanded...)
+		failure.Backtrace = append(failure.Backtrace, entry.Backtrace...)
+	}
+	failure.Message = strings.TrimSpace(strings.Join(message, "\n"))
+	attempt.Failure = failure
+	return attempt
 }
 
 func parseTestEngineTestResult(path string) ([]TestEngineTest, error) {
@@ -5,6 +5,7 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/buildkite/test-engine-client/v3/internal/plan"
 	"github.com/google/go-cmp/cmp"
@@ -48,6 +49,28 @@ func TestRecordTestResult_MultipleExecution(t *testing.T) {
 	}
 }
 
+func TestTests(t *testing.T) {
+	r := NewRunResult([]plan.TestCase{{Scope: "banana", Name: "is yellow"}})
+
+	apple := plan.TestCase{Scope: "apple", Name: "is red", Path: "b.rb"}
+	banana := plan.TestCase{Scope: "banana", Name: "is yellow", Path: "a.rb"}
+	cherry := plan.TestCase{Scope: "apple", Name: "is green", Path: "b.rb"}
+	r.RecordTestResult(apple, TestStatusFailed)
+	r.RecordTestResult(banana, TestStatusFailed)
+	r.RecordTestResult(cherry, TestStatusPassed)
+	r.RecordTestResult(apple, TestStatusPassed)
+
+	want := []TestResult{
+		{TestCase: banana, Status: TestStatusFailed, ExecutionCount: 1, Muted: true, Attempts: []TestAttempt{{Status: TestStatusFailed}}},
+		{TestCase: cherry, Status: TestStatusPassed, ExecutionCount: 1, Attempts: []TestAttempt{{Status: TestStatusPassed}}},
+		{TestCase: apple, Status: TestStatusPassed, ExecutionCount: 2, Attempts: []TestAttempt{{Status: TestStatusFailed}, {Status: TestStatusPassed}}},
+	}
+
+	if diff := cmp.Diff(r.Tests(), want); diff != "" {
+		t.Errorf("Tests() diff (-got +want):\n%s", diff)
+	}
+}
+
 func TestFailedTests(t *testing.T) {
 	r := NewRunResult([]plan.TestCase{})
 
@@ -170,7 +193,7 @@ func TestMutedTests(t *testing.T) {
 	}
 
 	wantMutedTest := []TestResult{
-		{TestCase: apple, Status: TestStatusFailed, ExecutionCount: 1, Muted: true},
+		{TestCase: apple, Status: TestStatusFailed, ExecutionCount: 1, Muted: true, Attempts: []TestAttempt{{Status: TestStatusFailed}}},
 	}
 
 	if diff := cmp.Diff(mutedTests, wantMutedTest); diff != "" {
@@ -318,3 +341,85 @@ func TestParseTestEngineTestResult(t *testing.T) {
 		t.Errorf("len(results) = %d, want 2", len(results))
 	}
 }
+
+func TestTestEngineTestAttempt(t *testing.T) {
+	results, err := parseTestEngineTestResult("testdata/test-engine-result.json")
+	if err != nil {
+		t.Fatalf("parseTestEngineTestResult() error = %v", err)
+	}
+
+	passed := results[0].Attempt()
+	if passed.Status != TestStatusPassed || passed.Failure != nil {
+		t.Errorf("Attempt() = %+v, want a passed attempt without failure", passed)
+	}
+	if passed.Duration != 726232000*time.Nanosecond {
+		t.Errorf("Attempt().Duration = %s, want %s", passed.Duration, 726232000*time.Nanosecond)
+	}
+
+	failed := results[1].Attempt()
+	want := &TestFailure{
+		Message: "Failure/Error: expect(true).to eq false\n  expected: false\n       got: true\n\n  (compared using ==)\n\n  Diff:\n  @@ -1 +1 @@\n  -false\n  +true",
+		Backtrace: []string{
+			"./spec/models/analytics/upload_spec.rb:25:in `block (3 levels) in <top (required)>'",
+			"./spec/support/log.rb:17:in `run'",
+			"./spec/support/log.rb:66:in `block (2 levels) in <top (required)>'",
+			"./spec/support/database.rb:19:in `block (2 levels) in <top (required)>'",
+			"/Users/abc/Documents/rspec-buildkite-analytics/lib/rspec/buildkite/analytics/uploader.rb:153:in `block (2 levels) in configure'",
+			"-e:1:in `<main>'",
+		},
+	}
+	if diff := cmp.Diff(want, failed.Failure); diff != "" {
+		t.Errorf("Attempt().Failure diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSlowestTests(t *testing.T) {
+	r := NewRunResult([]plan.TestCase{})
+
+	apple := plan.TestCase{Scope: "apple", Name: "is red", Path: "a.rb"}
+	banana := plan.TestCase{Scope: "banana", Name: "is yellow", Path: "b.rb"}
+	cherry := plan.TestCase{Scope: "cherry", Name: "is small", Path: "c.rb"}
+	durian := plan.TestCase{Scope: "durian", Name: "smells", Path: "d.rb"}
+	r.RecordTestAttempt(apple, TestAttempt{Status: TestStatusPassed, Duration: 2 * time.Second})
+	r.RecordTestAttempt(banana, TestAttempt{Status: TestStatusFailed, Duration: 1500 * time.Millisecond})
+	r.RecordTestAttempt(banana, TestAttempt{Status: TestStatusPassed, Duration: 1500 * time.Millisecond})
+	r.RecordTestAttempt(cherry, TestAttempt{Status: TestStatusPassed, Duration: time.Second})
+	r.RecordTestResult(durian, TestStatusPassed)
+
+	var got []string
+	for _, test := range r.SlowestTests(2) {
+		got = append(got, fmt.Sprintf("%s %s", test.Scope, test.Duration()))
+	}
+
+	want := []string{"banana 3s", "apple 2s"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SlowestTests(2) diff (-want +got):\n%s", diff)
+	}
+
+	if got := r.SlowestTests(10); len(got) != 3 {
+		t.Errorf("len(SlowestTests(10)) = %d, want 3 tests with a duration", len(got))
+	}
+}
+
+func TestMuteQuarantinedTests(t *testing.T) {
+	q := Quarantine{Tests: []QuarantinedTest{{Path: "spec/system/*"}}}
+	if err := q.Tests[0].compile(); err != nil {
+		t.Fatalf("compile() error = %v", err)
+	}
+
+	r := NewRunResult([]plan.TestCase{})
+	r.MuteQuarantinedTests(q)
+
+	r.RecordTestResult(plan.TestCase{Scope: "Checkout", Name: "pays", Path: "spec/system/checkout_spec.rb:3"}, TestStatusFailed)
+	r.RecordTestResult(plan.TestCase{Scope: "User", Name: "saves", Path: "spec/models/user_spec.rb:1"}, TestStatusPassed)
+
+	if got := len(r.MutedTests()); got != 1 {
+		t.Errorf("len(MutedTests()) = %d, want 1", got)
+	}
+	if !r.OnlyMutedFailures() {
+		t.Errorf("OnlyMutedFailures() is false, want true")
+	}
+	if r.Status() != RunStatusPassed {
+		t.Errorf("Status() is %s, want %s", r.Status(), RunStatusPassed)
+	}
+}
@@ -17,6 +17,14 @@ type RunnerConfig struct {
 
 	// SelectorListPath points at a file containing the selectors to run.
 	SelectorListPath string
+
+	// GoTestBinaryDir is the directory the Go runner compiles test binaries into.
+	// It is only used by the Go runner.
+	GoTestBinaryDir string
+
+	// PluginCommand is the command running the executable of a runner plugin.
+	// It is only used by the plugin runner.
+	PluginCommand string
 }
 
 // splitBySelectorList reports whether the runner is splitting work using a
@@ -0,0 +1,204 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Result formats of a runner definition.
+const (
+	ResultFormatJUnit          = "junit"
+	ResultFormatTAP            = "tap"
+	ResultFormatTestEngineJSON = "test-engine-json"
+	ResultFormatCTRF           = "ctrf"
+)
+
+var resultFormats = []string{ResultFormatJUnit, ResultFormatTAP, ResultFormatTestEngineJSON, ResultFormatCTRF}
+
+// RunnerDefinitions are the test runners defined in a runner definitions file,
+// which are available alongside the built-in runners.
+type RunnerDefinitions struct {
+	Runners []RunnerDefinition `yaml:"runners"`
+}
+
+// RunnerDefinition describes how to discover, run and retry the tests of a test runner without a Go change.
+type RunnerDefinition struct {
+	// Name is the value of BUILDKITE_TEST_ENGINE_TEST_RUNNER that selects the runner.
+	Name string `yaml:"name"`
+	// DisplayName is the name of the runner shown to users. It defaults to Name.
+	DisplayName string `yaml:"display_name"`
+	// TestCommand is the command that runs the tests, with the same placeholders as the
+	// test command of the built-in runners: "{{testExamples}}", "{{testListFile}}" and "{{resultPath}}".
+	TestCommand string `yaml:"test_command"`
+	// RetryCommand is the command that runs the failed tests. In addition to the placeholders of the
+	// test command, "{{testFilter}}" is replaced with the filter built by RetryFilter.
+	// It defaults to TestCommand.
+	RetryCommand string `yaml:"retry_command"`
+	// ResultPath is the default result path of the runner.
+	ResultPath string `yaml:"result_path"`
+	// ResultFormat is the format of the report at the result path: junit, tap, test-engine-json or ctrf.
+	ResultFormat string                   `yaml:"result_format"`
+	Discover     RunnerDefinitionDiscover `yaml:"discover"`
+	RetryFilter  *RetryFilterDefinition   `yaml:"retry_filter"`
+	Features     RunnerDefinitionFeatures `yaml:"features"`
+}
+
+// RunnerDefinitionDiscover describes how the test files, or selectors, of a defined runner are discovered:
+// either with a glob pattern, or with a command printing one per line.
+type RunnerDefinitionDiscover struct {
+	Pattern        string `yaml:"pattern"`
+	ExcludePattern string `yaml:"exclude_pattern"`
+	Command        string `yaml:"command"`
+}
+
+// RetryFilterDefinition describes how the "{{testFilter}}" placeholder of the retry command is built
+// from the failed tests. Test is expanded for each failed test, with "{{scope}}", "{{name}}" and
+// "{{path}}" replaced by the test, and the filters of the tests are joined with Separator between
+// Prefix and Suffix. For example, a regular expression matching the failed tests exactly:
+//
+//	retry_filter:
+//	  test: "{{scope}} {{name}}"
+//	  escape: regexp
+//	  separator: "|"
+//	  prefix: "^("
+//	  suffix: ")$"
+type RetryFilterDefinition struct {
+	Test      string `yaml:"test"`
+	Separator string `yaml:"separator"`
+	Prefix    string `yaml:"prefix"`
+	Suffix    string `yaml:"suffix"`
+	// Escape is how the scope, name and path are escaped: "none", the default, or "regexp".
+	Escape string `yaml:"escape"`
+}
+
+// RunnerDefinitionFeatures are the features of a defined runner. Features that aren't set take their
+// default: splitting by file and by selector, auto retry and mute are supported, and filtering test files
+// is supported when the test files are discovered with a pattern. Splitting by example, filtering tests
+// by tag and skipping tests need runner-specific support, so they can't be enabled.
+type RunnerDefinitionFeatures struct {
+	SplitByFile     *bool `yaml:"split_by_file"`
+	SplitByExample  *bool `yaml:"split_by_example"`
+	SplitBySelector *bool `yaml:"split_by_selector"`
+	FilterTestFiles *bool `yaml:"filter_test_files"`
+	FilterTestByTag *bool `yaml:"filter_test_by_tag"`
+	AutoRetry       *bool `yaml:"auto_retry"`
+	Mute            *bool `yaml:"mute"`
+	Skip            *bool `yaml:"skip"`
+}
+
+// LoadRunnerDefinitions reads a runner definitions file in YAML or JSON, for example:
+//
+//	runners:
+//	  - name: bats
+//	    display_name: Bats
+//	    test_command: bats --report-formatter junit --output tmp {{testExamples}}
+//	    result_path: tmp/report.xml
+//	    result_format: junit
+//	    discover:
+//	      pattern: test/**/*.bats
+//	    retry_command: bats --report-formatter junit --output tmp --filter {{testFilter}} {{testExamples}}
+//	    retry_filter:
+//	      test: "{{name}}"
+//	      escape: regexp
+//	      separator: "|"
+//	      prefix: "^("
+//	      suffix: ")$"
+func LoadRunnerDefinitions(path string) (RunnerDefinitions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RunnerDefinitions{}, fmt.Errorf("failed to read runner definitions file: %w", err)
+	}
+
+	// JSON is a subset of YAML, so both formats are read by the YAML decoder.
+	var definitions RunnerDefinitions
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&definitions); err != nil {
+		return RunnerDefinitions{}, fmt.Errorf("failed to parse runner definitions file %s: %w", path, err)
+	}
+
+	var names []string
+	for i, definition := range definitions.Runners {
+		if err := definition.validate(); err != nil {
+			return RunnerDefinitions{}, fmt.Errorf("invalid runner %d in runner definitions file %s: %w", i+1, path, err)
+		}
+
+		if slices.Contains(names, definition.Name) {
+			return RunnerDefinitions{}, fmt.Errorf("invalid runner %d in runner definitions file %s: runner %q is defined more than once", i+1, path, definition.Name)
+		}
+		names = append(names, definition.Name)
+	}
+
+	return definitions, nil
+}
+
+// Find returns the definition of the runner with the given name.
+func (d RunnerDefinitions) Find(name string) (RunnerDefinition, bool) {
+	for _, definition := range d.Runners {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return RunnerDefinition{}, false
+}
+
+func (d RunnerDefinition) validate() error {
+	if d.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if slices.Contains(builtInRunners, d.Name) {
+		return fmt.Errorf("name %q is the name of a built-in runner", d.Name)
+	}
+
+	if d.TestCommand == "" {
+		return errors.New("test_command is required")
+	}
+
+	if strings.Contains(d.TestCommand, "{{testFilter}}") {
+		return errors.New("test_command can't use {{testFilter}}, which is only available in retry_command")
+	}
+
+	if !slices.Contains(resultFormats, d.ResultFormat) {
+		return fmt.Errorf("result_format %q is invalid, possible values are %s", d.ResultFormat, strings.Join(resultFormats, ", "))
+	}
+
+	if d.Discover.Pattern != "" && d.Discover.Command != "" {
+		return errors.New("discover can have a pattern or a command, but not both")
+	}
+
+	if d.RetryFilter != nil {
+		if d.RetryFilter.Test == "" {
+			return errors.New("retry_filter.test is required")
+		}
+		if d.RetryFilter.Escape != "" && d.RetryFilter.Escape != "none" && d.RetryFilter.Escape != "regexp" {
+			return fmt.Errorf("retry_filter.escape %q is invalid, possible values are none, regexp", d.RetryFilter.Escape)
+		}
+		if !strings.Contains(d.RetryCommand, "{{testFilter}}") {
+			return errors.New("retry_command must use {{testFilter}} when retry_filter is set")
+		}
+	}
+
+	// TAP test points only have a description, so the failed tests can only be retried with a filter.
+	if d.ResultFormat == ResultFormatTAP && d.RetryFilter == nil && featureEnabled(d.Features.AutoRetry, true) {
+		return errors.New("result_format tap needs a retry_filter to retry the failed tests, as TAP reports don't include the paths of the tests, or set features.auto_retry to false")
+	}
+
+	unsupported := map[string]*bool{
+		"split_by_example":   d.Features.SplitByExample,
+		"filter_test_by_tag": d.Features.FilterTestByTag,
+		"skip":               d.Features.Skip,
+	}
+	for _, feature := range []string{"split_by_example", "filter_test_by_tag", "skip"} {
+		if enabled := unsupported[feature]; enabled != nil && *enabled {
+			return fmt.Errorf("feature %s isn't supported by runner definitions", feature)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRunnerDefinitionsFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "runners.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("os.WriteFile(%q) error = %v", path, err)
+	}
+	return path
+}
+
+func TestLoadRunnerDefinitions(t *testing.T) {
+	definitions, err := LoadRunnerDefinitions("./testdata/definitions/runners.yml")
+	if err != nil {
+		t.Fatalf("LoadRunnerDefinitions() error = %v", err)
+	}
+
+	if len(definitions.Runners) != 2 {
+		t.Fatalf("len(Runners) = %d, want 2", len(definitions.Runners))
+	}
+
+	bats, ok := definitions.Find("bats")
+	if !ok {
+		t.Fatalf("Find(%q) ok = false, want true", "bats")
+	}
+	if bats.ResultFormat != ResultFormatJUnit {
+		t.Errorf("bats.ResultFormat = %q, want %q", bats.ResultFormat, ResultFormatJUnit)
+	}
+	if bats.RetryFilter == nil || bats.RetryFilter.Separator != "|" {
+		t.Errorf("bats.RetryFilter = %+v, want separator %q", bats.RetryFilter, "|")
+	}
+
+	prove, ok := definitions.Find("prove")
+	if !ok {
+		t.Fatalf("Find(%q) ok = false, want true", "prove")
+	}
+	if prove.Features.AutoRetry == nil || *prove.Features.AutoRetry {
+		t.Errorf("prove.Features.AutoRetry = %v, want false", prove.Features.AutoRetry)
+	}
+
+	if _, ok := definitions.Find("rspec"); ok {
+		t.Errorf("Find(%q) ok = true, want false", "rspec")
+	}
+}
+
+func TestLoadRunnerDefinitions_JSON(t *testing.T) {
+	path := writeRunnerDefinitionsFile(t, `{"runners": [{"name": "hurl", "test_command": "hurl --test --report-ctrf tmp/ctrf.json {{testExamples}}", "result_path": "tmp/ctrf.json", "result_format": "ctrf"}]}`)
+
+	definitions, err := LoadRunnerDefinitions(path)
+	if err != nil {
+		t.Fatalf("LoadRunnerDefinitions() error = %v", err)
+	}
+
+	if _, ok := definitions.Find("hurl"); !ok {
+		t.Errorf("Find(%q) ok = false, want true", "hurl")
+	}
+}
+
+func TestLoadRunnerDefinitions_Invalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing name",
+			content: "runners:\n  - test_command: bats\n    result_format: junit\n",
+			wantErr: "invalid runner 1 in runner definitions file",
+		},
+		{
+			name:    "built-in runner",
+			content: "runners:\n  - name: rspec\n    test_command: bin/rspec\n    result_format: junit\n",
+			wantErr: `name "rspec" is the name of a built-in runner`,
+		},
+		{
+			name:    "duplicate runner",
+			content: "runners:\n  - name: bats\n    test_command: bats\n    result_format: junit\n  - name: bats\n    test_command: bats\n    result_format: junit\n",
+			wantErr: `invalid runner 2 in runner definitions file`,
+		},
+		{
+			name:    "missing test command",
+			content: "runners:\n  - name: bats\n    result_format: junit\n",
+			wantErr: "test_command is required",
+		},
+		{
+			name:    "test filter in test command",
+			content: "runners:\n  - name: bats\n    test_command: bats --filter {{testFilter}}\n    result_format: junit\n",
+			wantErr: "test_command can't use {{testFilter}}",
+		},
+		{
+			name:    "invalid result format",
+			content: "runners:\n  - name: bats\n    test_command: bats\n    result_format: xunit\n",
+			wantErr: `result_format "xunit" is invalid, possible values are junit, tap, test-engine-json, ctrf`,
+		},
+		{
+			name:    "pattern and command",
+			content: "runners:\n  - name: bats\n    test_command: bats\n    result_format: junit\n    discover:\n      pattern: tests/*.bats\n      command: ls tests\n",
+			wantErr: "discover can have a pattern or a command, but not both",
+		},
+		{
+			name:    "retry filter without placeholder",
+			content: "runners:\n  - name: bats\n    test_command: bats\n    result_format: junit\n    retry_filter:\n      test: \"{{name}}\"\n",
+			wantErr: "retry_command must use {{testFilter}} when retry_filter is set",
+		},
+		{
+			name:    "invalid escape",
+			content: "runners:\n  - name: bats\n    test_command: bats\n    retry_command: bats --filter {{testFilter}}\n    result_format: junit\n    retry_filter:\n      test: \"{{name}}\"\n      escape: shell\n",
+			wantErr: `retry_filter.escape "shell" is invalid`,
+		},
+		{
+			name:    "tap without retry filter",
+			content: "runners:\n  - name: prove\n    test_command: prove {{testExamples}}\n    result_format: tap\n",
+			wantErr: "result_format tap needs a retry_filter to retry the failed tests",
+		},
+		{
+			name:    "unsupported feature",
+			content: "runners:\n  - name: bats\n    test_command: bats\n    result_format: junit\n    features:\n      split_by_example: true\n",
+			wantErr: "feature split_by_example isn't supported by runner definitions",
+		},
+		{
+			name:    "unknown field",
+			content: "runners:\n  - name: bats\n    test_cmd: bats\n",
+			wantErr: "field test_cmd not found",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeRunnerDefinitionsFile(t, tc.content)
+
+			_, err := LoadRunnerDefinitions(path)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LoadRunnerDefinitions() error = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRunnerDefinitions_MissingFile(t *testing.T) {
+	_, err := LoadRunnerDefinitions(filepath.Join(t.TempDir(), "runners.yml"))
+	if err == nil {
+		t.Errorf("LoadRunnerDefinitions() error = nil, want an error")
+	}
+}
@@ -26,6 +26,14 @@ func TestSupportedFeatures_SplitBySelectorSupportedRunners(t *testing.T) {
 	gotest := NewGoTest(runnerConfig)
 	cucumber := NewCucumber(runnerConfig)
 	vitest := NewVitest(runnerConfig)
+	minitest := NewMinitest(runnerConfig)
+	mocha := NewMocha(runnerConfig)
+	phpunit := NewPHPUnit(runnerConfig)
+	nextest := NewNextest(runnerConfig)
+	gradle := NewGradle(runnerConfig)
+	maven := NewMaven(runnerConfig)
+	dotnet := NewDotnet(runnerConfig)
+	exunit := NewExUnit(runnerConfig)
 
 	runners := []TestRunner{
 		custom,
@@ -37,6 +45,14 @@ func TestSupportedFeatures_SplitBySelectorSupportedRunners(t *testing.T) {
 		gotest,
 		cucumber,
 		vitest,
+		minitest,
+		mocha,
+		phpunit,
+		nextest,
+		gradle,
+		maven,
+		dotnet,
+		exunit,
 	}
 
 	supportedRunners := []string{
@@ -49,6 +65,14 @@ func TestSupportedFeatures_SplitBySelectorSupportedRunners(t *testing.T) {
 		pytest.Name(),
 		cucumber.Name(),
 		vitest.Name(),
+		minitest.Name(),
+		mocha.Name(),
+		phpunit.Name(),
+		nextest.Name(),
+		gradle.Name(),
+		maven.Name(),
+		dotnet.Name(),
+		exunit.Name(),
 	}
 
 	for _, runner := range runners {
@@ -81,6 +105,14 @@ func TestSupportedFeatures_SplitByExampleRequiresExampleDiscoverer(t *testing.T)
 		NewGoTest(runnerConfig),
 		NewCucumber(runnerConfig),
 		NewVitest(runnerConfig),
+		NewMinitest(runnerConfig),
+		NewMocha(runnerConfig),
+		NewPHPUnit(runnerConfig),
+		NewNextest(runnerConfig),
+		NewGradle(runnerConfig),
+		NewMaven(runnerConfig),
+		NewDotnet(runnerConfig),
+		NewExUnit(runnerConfig),
 	}
 
 	for _, testRunner := range runners {
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TAPTestResult represents a test point of a TAP (Test Anything Protocol) report, e.g.
+// "not ok 2 - validates the email".
+type TAPTestResult struct {
+	Number      int
+	Description string
+	Result      TestStatus // passed | failed | skipped
+	// Directive is the SKIP or TODO directive of the test point, followed by its reason, e.g. "SKIP not implemented".
+	Directive string
+	// Diagnostics are the comment lines and the YAML block following the test point,
+	// which usually describe why the test failed.
+	Diagnostics []string
+}
+
+// Attempt returns the result of the test point, including the failure described by its diagnostics.
+// TAP doesn't report the duration of tests.
+func (r TAPTestResult) Attempt() TestAttempt {
+	attempt := TestAttempt{Status: r.Result}
+	if r.Result == TestStatusFailed {
+		attempt.Failure = parseTestFailure("", strings.Join(r.Diagnostics, "\n"))
+	}
+	return attempt
+}
+
+var (
+	// tapTestPointPattern matches a test point, with an optional number and description.
+	tapTestPointPattern = regexp.MustCompile(`^(not ok|ok)\b(?:\s+(\d+))?(?:\s+-)?\s*(.*)$`)
+	// tapDirectivePattern matches the SKIP or TODO directive at the end of the description of a test point.
+	tapDirectivePattern = regexp.MustCompile(`(?i)\s*(?:^|\s)#\s*((SKIP|TODO)\S*(?:\s+.*)?)$`)
+)
+
+func loadAndParseTAP(path string) ([]TAPTestResult, error) {
+	tapFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open TAP file %s: %w", path, err)
+	}
+	defer tapFile.Close()
+
+	var results []TAPTestResult
+	inYAML := false
+	scanner := bufio.NewScanner(tapFile)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		// The YAML block of a test point is indented, and ends with "...".
+		if inYAML {
+			if trimmed == "..." {
+				inYAML = false
+			} else if len(results) > 0 {
+				results[len(results)-1].Diagnostics = append(results[len(results)-1].Diagnostics, trimmed)
+			}
+			continue
+		}
+
+		if matches := tapTestPointPattern.FindStringSubmatch(line); matches != nil {
+			results = append(results, parseTAPTestPoint(matches))
+			continue
+		}
+
+		switch {
+		case len(results) == 0:
+			// The version, plan and comments before the first test point don't belong to a test.
+		case trimmed == "---" && line != trimmed:
+			inYAML = true
+		case strings.HasPrefix(line, "#"):
+			results[len(results)-1].Diagnostics = append(results[len(results)-1].Diagnostics, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read TAP file %s: %w", path, err)
+	}
+
+	return results, nil
+}
+
+// parseTAPTestPoint builds a TAPTestResult from the matches of tapTestPointPattern.
+// A skipped test point is skipped, as well as a failed test point with a TODO directive,
+// which is a known failure that doesn't fail the run.
+func parseTAPTestPoint(matches []string) TAPTestResult {
+	result := TAPTestResult{Description: matches[3]}
+	result.Number, _ = strconv.Atoi(matches[2])
+
+	if directive := tapDirectivePattern.FindStringSubmatchIndex(result.Description); directive != nil {
+		result.Directive = result.Description[directive[2]:directive[3]]
+		result.Description = strings.TrimSpace(result.Description[:directive[0]])
+	}
+
+	kind := strings.ToUpper(strings.SplitN(result.Directive, " ", 2)[0])
+	switch {
+	case strings.HasPrefix(kind, "SKIP"):
+		result.Result = TestStatusSkipped
+	case matches[1] == "ok":
+		result.Result = TestStatusPassed
+	case strings.HasPrefix(kind, "TODO"):
+		result.Result = TestStatusSkipped
+	default:
+		result.Result = TestStatusFailed
+	}
+
+	return result
+}
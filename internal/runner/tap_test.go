@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadAndParseTAP(t *testing.T) {
+	results, err := loadAndParseTAP("./testdata/definitions/results/report.tap")
+	if err != nil {
+		t.Fatalf("loadAndParseTAP() error = %v", err)
+	}
+
+	want := []TAPTestResult{
+		{Number: 1, Description: "adds two numbers", Result: TestStatusPassed},
+		{
+			Number:      2,
+			Description: "divides by zero",
+			Result:      TestStatusFailed,
+			Diagnostics: []string{`message: "expected an error"`, "severity: fail"},
+		},
+		{Number: 3, Description: "rounds", Result: TestStatusSkipped, Directive: "SKIP not implemented"},
+		{Number: 4, Description: "parses dates", Result: TestStatusSkipped, Directive: "TODO timezone support"},
+		{
+			Number:      5,
+			Description: "joins strings",
+			Result:      TestStatusFailed,
+			Diagnostics: []string{`expected "ab"`, `got "a b"`},
+		},
+	}
+
+	if diff := cmp.Diff(results, want); diff != "" {
+		t.Errorf("loadAndParseTAP() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestParseTAPTestPoint(t *testing.T) {
+	cases := []struct {
+		line string
+		want TAPTestResult
+	}{
+		{
+			line: "ok",
+			want: TAPTestResult{Result: TestStatusPassed},
+		},
+		{
+			line: "not ok 12",
+			want: TAPTestResult{Number: 12, Result: TestStatusFailed},
+		},
+		{
+			line: "ok 3 # skip no database",
+			want: TAPTestResult{Number: 3, Result: TestStatusSkipped, Directive: "skip no database"},
+		},
+		{
+			line: "ok 7 - handles # in the description",
+			want: TAPTestResult{Number: 7, Description: "handles # in the description", Result: TestStatusPassed},
+		},
+		{
+			line: "ok 8 - passes unexpectedly # TODO not fixed yet",
+			want: TAPTestResult{Number: 8, Description: "passes unexpectedly", Result: TestStatusPassed, Directive: "TODO not fixed yet"},
+		},
+	}
+
+	for _, tc := range cases {
+		matches := tapTestPointPattern.FindStringSubmatch(tc.line)
+		if matches == nil {
+			t.Errorf("tapTestPointPattern doesn't match %q", tc.line)
+			continue
+		}
+
+		if diff := cmp.Diff(parseTAPTestPoint(matches), tc.want); diff != "" {
+			t.Errorf("parseTAPTestPoint(%q) diff (-got +want):\n%s", tc.line, diff)
+		}
+	}
+}
+
+func TestTAPTestResult_Attempt(t *testing.T) {
+	result := TAPTestResult{
+		Description: "joins strings",
+		Result:      TestStatusFailed,
+		Diagnostics: []string{`expected "ab"`, `got "a b"`},
+	}
+
+	want := TestAttempt{
+		Status:  TestStatusFailed,
+		Failure: &TestFailure{Message: "expected \"ab\"\ngot \"a b\""},
+	}
+
+	if diff := cmp.Diff(result.Attempt(), want); diff != "" {
+		t.Errorf("TAPTestResult.Attempt() diff (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+)
+
+// testListFilePlaceholder is replaced with the path of a file listing the test paths, one per line.
+// Commands taking the paths in a file instead of as arguments can run any number of tests,
+// without exceeding the argument limit of the OS.
+//
+// CommandNameAndArgs leaves the placeholder in the command, so measuring or printing a command doesn't
+// write anything. The file is only written by buildCommand, right before the command is run.
+const testListFilePlaceholder = "{{testListFile}}"
+
+// testLister is implemented by the runners whose command can take the tests in a "{{testListFile}}".
+type testLister interface {
+	// testListPaths returns the paths written to the test list file, which are the same paths that
+	// replace "{{testExamples}}" in the command.
+	testListPaths(testCases []plan.TestCase, retry bool) []string
+}
+
+// hasTestListFile reports whether any word of the command has the "{{testListFile}}" placeholder.
+func hasTestListFile(words []string) bool {
+	for _, word := range words {
+		if strings.Contains(word, testListFilePlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTestListFile writes the paths to a temporary file, one per line, and replaces the
+// "{{testListFile}}" placeholder in the words with the path of the file. The cleanup func removes
+// the file, and must be called once the command has run.
+// The words are returned unchanged, and nothing is written, when they don't have the placeholder.
+func writeTestListFile(words []string, paths []string) ([]string, func(), error) {
+	if !hasTestListFile(words) {
+		return words, func() {}, nil
+	}
+
+	file, err := os.CreateTemp("", "bktec-test-list-*.txt")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create test list file: %w", err)
+	}
+	cleanup := func() { os.Remove(file.Name()) }
+	defer file.Close()
+
+	for _, path := range paths {
+		if _, err := fmt.Fprintln(file, path); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to write test list file: %w", err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write test list file: %w", err)
+	}
+
+	replaced := make([]string, len(words))
+	for i, word := range words {
+		replaced[i] = strings.ReplaceAll(word, testListFilePlaceholder, file.Name())
+	}
+	return replaced, cleanup, nil
+}
@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteTestListFile(t *testing.T) {
+	words, cleanup, err := writeTestListFile([]string{"bin/test", "--file-list={{testListFile}}"}, []string{"tests/a_test.sh", "tests/b_test.sh"})
+	if err != nil {
+		t.Fatalf("writeTestListFile() error = %v", err)
+	}
+
+	if len(words) != 2 || words[0] != "bin/test" {
+		t.Fatalf("writeTestListFile() = %v, want [bin/test --file-list=<file>]", words)
+	}
+
+	file, found := strings.CutPrefix(words[1], "--file-list=")
+	if !found {
+		t.Fatalf("writeTestListFile() = %v, want [bin/test --file-list=<file>]", words)
+	}
+
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) error = %v", file, err)
+	}
+
+	want := "tests/a_test.sh\ntests/b_test.sh\n"
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("test list file diff (-got +want):\n%s", diff)
+	}
+
+	cleanup()
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(%q) error = %v, want the test list file to be removed", file, err)
+	}
+}
+
+func TestWriteTestListFile_WithoutPlaceholder(t *testing.T) {
+	words := []string{"bin/test", "tests/a_test.sh"}
+	got, cleanup, err := writeTestListFile(words, []string{"tests/a_test.sh"})
+	if err != nil {
+		t.Fatalf("writeTestListFile() error = %v", err)
+	}
+	defer cleanup()
+
+	if diff := cmp.Diff(got, words); diff != "" {
+		t.Errorf("writeTestListFile() diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestBuildCommand_TestListFile(t *testing.T) {
+	custom, err := NewCustom(RunnerConfig{
+		TestCommand:     "bin/test --file-list {{testListFile}}",
+		TestFilePattern: "tests/**/*_test.sh",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Custom runner: %v", err)
+	}
+
+	testCases := []plan.TestCase{{Path: "tests/a_test.sh"}, {Path: "tests/b_test.sh"}}
+
+	// The command is only measured or printed, so the file isn't written.
+	_, args, err := custom.CommandNameAndArgs(testCases, false)
+	if err != nil {
+		t.Fatalf("Custom.CommandNameAndArgs() error = %v", err)
+	}
+	if diff := cmp.Diff(args, []string{"--file-list", "{{testListFile}}"}); diff != "" {
+		t.Errorf("Custom.CommandNameAndArgs() args diff (-got +want):\n%s", diff)
+	}
+
+	cmd, cleanup, err := buildCommand(custom, testCases, false)
+	if err != nil {
+		t.Fatalf("buildCommand() error = %v", err)
+	}
+
+	file := cmd.Args[2]
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) error = %v", file, err)
+	}
+
+	want := "tests/a_test.sh\ntests/b_test.sh\n"
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("test list file diff (-got +want):\n%s", diff)
+	}
+
+	cleanup()
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(%q) error = %v, want the test list file to be removed", file, err)
+	}
+}
+
+func TestBuildCommand_TestListFileNotSupported(t *testing.T) {
+	phpunit := NewPHPUnit(RunnerConfig{
+		TestCommand: "vendor/bin/phpunit --log-junit {{resultPath}} --file-list {{testListFile}}",
+	})
+
+	_, _, err := buildCommand(phpunit, []plan.TestCase{{Path: "tests/Unit/UserTest.php"}}, false)
+	if err == nil || !strings.Contains(err.Error(), "doesn't support {{testListFile}}") {
+		t.Errorf("buildCommand() error = %v, want the placeholder to be rejected", err)
+	}
+}
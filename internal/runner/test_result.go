@@ -1,6 +1,12 @@
 package runner
 
-import "github.com/buildkite/test-engine-client/v3/internal/plan"
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/buildkite/test-engine-client/v3/internal/plan"
+)
 
 type TestStatus string
 
@@ -17,6 +23,107 @@ type TestResult struct {
 	Status         TestStatus
 	ExecutionCount int
 	Muted          bool
+	// Attempts are the results of each execution of the test case, in the order they were run.
+	Attempts []TestAttempt
+}
+
+// TestAttempt is the result of a single execution of a test case.
+type TestAttempt struct {
+	Status TestStatus
+	// Duration is how long the test took to run, as reported by the test runner.
+	// It is zero when the runner doesn't report it.
+	Duration time.Duration
+	// Failure describes why the test failed. It is nil when the attempt didn't fail,
+	// or the runner doesn't report failure details.
+	Failure *TestFailure
+}
+
+// TestFailure describes why a test failed, as reported by the test runner.
+type TestFailure struct {
+	Message string
+	// Exception is the class or type of the error, e.g. "RSpec::Expectations::ExpectationNotMetError".
+	Exception string
+	// Backtrace is the stack trace of the error, one frame per line.
+	Backtrace []string
+}
+
+// LastAttempt returns the most recent execution of the test case.
+func (t TestResult) LastAttempt() TestAttempt {
+	if len(t.Attempts) == 0 {
+		return TestAttempt{Status: t.Status}
+	}
+	return t.Attempts[len(t.Attempts)-1]
+}
+
+// Duration returns the total duration of all attempts of the test case.
+func (t TestResult) Duration() time.Duration {
+	var duration time.Duration
+	for _, attempt := range t.Attempts {
+		duration += attempt.Duration
+	}
+	return duration
+}
+
+// Failure returns the failure details of the most recent failed attempt,
+// or nil if none of the attempts reported any.
+func (t TestResult) Failure() *TestFailure {
+	for i := len(t.Attempts) - 1; i >= 0; i-- {
+		if t.Attempts[i].Failure != nil {
+			return t.Attempts[i].Failure
+		}
+	}
+	return nil
+}
+
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// parseTestFailure builds a TestFailure from the failure output of a test runner,
+// which usually starts with the error message followed by the stack trace.
+// Lines that look like stack frames are moved to the backtrace, and terminal colors are removed.
+// It returns nil if the output is empty.
+func parseTestFailure(exception, output string) *TestFailure {
+	output = ansiEscapePattern.ReplaceAllString(output, "")
+
+	var message []string
+	var backtrace []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if isStackFrame(line) {
+			backtrace = append(backtrace, strings.TrimSpace(line))
+			continue
+		}
+		message = append(message, line)
+	}
+
+	failure := &TestFailure{
+		Message:   strings.TrimSpace(strings.Join(message, "\n")),
+		Exception: exception,
+		Backtrace: backtrace,
+	}
+	if failure.Message == "" && failure.Exception == "" && len(failure.Backtrace) == 0 {
+		return nil
+	}
+	return failure
+}
+
+// rubyStackFramePattern matches a frame of a Ruby backtrace, e.g. "./app/user.rb:12:in `save'".
+var rubyStackFramePattern = regexp.MustCompile(`^\S+:\d+:in `)
+
+// isStackFrame reports whether the line is a frame of a JavaScript, Java, Python or Ruby stack trace.
+func isStackFrame(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "at ") || strings.HasPrefix(line, `File "`) || rubyStackFramePattern.MatchString(line)
+}
+
+// secondsToDuration converts a duration in seconds, as reported by most test runners, to a time.Duration.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// millisecondsToDuration converts a duration in milliseconds, as reported by JavaScript test runners,
+// to a time.Duration.
+func millisecondsToDuration(milliseconds float64) time.Duration {
+	return time.Duration(milliseconds * float64(time.Millisecond))
 }
 
 // testIdentifier returns a unique identifier for a test case based on its scope, name and path.
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTestFailure(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   *TestFailure
+	}{
+		{
+			name:   "JavaScript",
+			output: "\x1b[31mError: expect(received).toBe(expected)\x1b[39m\n\nExpected: 2\nReceived: 1\n    at Object.<anonymous> (src/math.spec.js:4:15)\n    at Promise.then.completed (node_modules/jest-circus/build/utils.js:298:28)",
+			want: &TestFailure{
+				Message: "Error: expect(received).toBe(expected)\n\nExpected: 2\nReceived: 1",
+				Backtrace: []string{
+					"at Object.<anonymous> (src/math.spec.js:4:15)",
+					"at Promise.then.completed (node_modules/jest-circus/build/utils.js:298:28)",
+				},
+			},
+		},
+		{
+			name:   "Python",
+			output: "Traceback (most recent call last):\n  File \"test_math.py\", line 4, in test_add\n    assert add(1, 1) == 3\nAssertionError",
+			want: &TestFailure{
+				Message:   "Traceback (most recent call last):\n    assert add(1, 1) == 3\nAssertionError",
+				Backtrace: []string{`File "test_math.py", line 4, in test_add`},
+			},
+		},
+		{
+			name:   "Ruby",
+			output: "expected true (RuntimeError)\n./features/step_definitions/steps.rb:5:in `/^it works$/'\nfeatures/math.feature:4:in `it works'",
+			want: &TestFailure{
+				Message: "expected true (RuntimeError)",
+				Backtrace: []string{
+					"./features/step_definitions/steps.rb:5:in `/^it works$/'",
+					"features/math.feature:4:in `it works'",
+				},
+			},
+		},
+		{
+			name:   "Empty",
+			output: " \n",
+			want:   nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseTestFailure("", tc.output)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("parseTestFailure(%q) diff (-want +got):\n%s", tc.output, diff)
+			}
+		})
+	}
+}
+
+func TestTestResultFailure(t *testing.T) {
+	first := &TestFailure{Message: "first"}
+	second := &TestFailure{Message: "second"}
+
+	result := TestResult{
+		Status: TestStatusPassed,
+		Attempts: []TestAttempt{
+			{Status: TestStatusFailed, Failure: first},
+			{Status: TestStatusFailed, Failure: second},
+			{Status: TestStatusPassed},
+		},
+	}
+
+	if got := result.Failure(); got != second {
+		t.Errorf("Failure() = %v, want %v", got, second)
+	}
+
+	if diff := cmp.Diff(TestAttempt{Status: TestStatusPassed}, result.LastAttempt()); diff != "" {
+		t.Errorf("LastAttempt() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestTestResultFailure_NoAttempts(t *testing.T) {
+	result := TestResult{Status: TestStatusFailed}
+
+	if got := result.Failure(); got != nil {
+		t.Errorf("Failure() = %v, want nil", got)
+	}
+
+	if diff := cmp.Diff(TestAttempt{Status: TestStatusFailed}, result.LastAttempt()); diff != "" {
+		t.Errorf("LastAttempt() diff (-want +got):\n%s", diff)
+	}
+}
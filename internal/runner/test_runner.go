@@ -25,6 +25,12 @@ type ExampleDiscoverer interface {
 	GetExamples(files []string) ([]plan.TestCase, error)
 }
 
+// TestFailureExitCoder is implemented by runners that don't exit with status 1
+// when tests fail, so bktec can still tell whether only muted tests failed.
+type TestFailureExitCoder interface {
+	IsTestFailureExitCode(code int) bool
+}
+
 type TestRunnerWithTargetDiscovery interface {
 	TestRunner
 	TestTargetDiscoverer
@@ -34,15 +40,36 @@ var (
 	_ TestTargetDiscoverer = (*Custom)(nil)
 	_ TestTargetDiscoverer = (*Cucumber)(nil)
 	_ TestTargetDiscoverer = (*Cypress)(nil)
+	_ TestTargetDiscoverer = (*DefinedRunner)(nil)
+	_ TestTargetDiscoverer = (*Dotnet)(nil)
+	_ TestTargetDiscoverer = (*ExUnit)(nil)
 	_ TestTargetDiscoverer = (*GoTest)(nil)
+	_ TestTargetDiscoverer = (*Gradle)(nil)
 	_ TestTargetDiscoverer = (*Jest)(nil)
+	_ TestTargetDiscoverer = (*Maven)(nil)
+	_ TestTargetDiscoverer = (*Minitest)(nil)
+	_ TestTargetDiscoverer = (*Mocha)(nil)
+	_ TestTargetDiscoverer = (*Nextest)(nil)
+	_ TestTargetDiscoverer = (*PHPUnit)(nil)
 	_ TestTargetDiscoverer = (*Playwright)(nil)
+	_ TestTargetDiscoverer = (*Plugin)(nil)
 	_ TestTargetDiscoverer = (*Pytest)(nil)
 	_ TestTargetDiscoverer = (*Rspec)(nil)
 	_ TestTargetDiscoverer = (*Vitest)(nil)
 
+	_ TestFailureExitCoder = (*Cypress)(nil)
+	_ TestFailureExitCoder = (*ExUnit)(nil)
+	_ TestFailureExitCoder = (*Nextest)(nil)
+	_ TestFailureExitCoder = (*PHPUnit)(nil)
+	_ TestFailureExitCoder = (*Mocha)(nil)
+
 	_ ExampleDiscoverer = (*Cucumber)(nil)
+	_ ExampleDiscoverer = (*ExUnit)(nil)
+	_ ExampleDiscoverer = (*GoTest)(nil)
+	_ ExampleDiscoverer = (*Minitest)(nil)
+	_ ExampleDiscoverer = (*Nextest)(nil)
 	_ ExampleDiscoverer = (*Playwright)(nil)
+	_ ExampleDiscoverer = (*Plugin)(nil)
 	_ ExampleDiscoverer = (*Pytest)(nil)
 	_ ExampleDiscoverer = (*Rspec)(nil)
 )
@@ -0,0 +1,5 @@
+#!/usr/bin/env bats
+
+@test "joins" {
+  [ "a$(echo b)" = "ab" ]
+}
@@ -0,0 +1,19 @@
+using Xunit;
+
+namespace Acme.Tests.Billing;
+
+public class InvoiceTests
+{
+    [Theory]
+    [InlineData("1.005")]
+    [InlineData("2.5")]
+    public void Rounds(string amount)
+    {
+        Assert.Equal(2, decimal.Parse(amount).Scale);
+    }
+
+    [Fact(Skip = "currency conversion isn't implemented yet")]
+    public void ConvertsCurrencies()
+    {
+    }
+}
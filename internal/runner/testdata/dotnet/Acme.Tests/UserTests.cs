@@ -0,0 +1,27 @@
+using Xunit;
+
+namespace Acme.Tests;
+
+public class UserTests
+{
+    [Fact]
+    public void SavesTheUser()
+    {
+        Assert.True(new User("harry@hogwarts.edu").Save());
+    }
+
+    [Fact]
+    public void ValidatesTheEmail()
+    {
+        Assert.True(new User("harry").IsValid());
+    }
+
+    public class Validation
+    {
+        [Fact]
+        public void RejectsBlankNames()
+        {
+            Assert.False(new User("").IsValid());
+        }
+    }
+}
@@ -0,0 +1,11 @@
+import Config
+
+if config_env() == :test do
+  result_path = System.get_env("BUILDKITE_TEST_ENGINE_RESULT_PATH", "_build/test/junit.xml")
+
+  config :junit_formatter,
+    report_dir: Path.dirname(result_path),
+    report_file: Path.basename(result_path),
+    include_filename?: true,
+    include_file_line?: true
+end
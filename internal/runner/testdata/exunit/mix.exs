@@ -0,0 +1,12 @@
+defmodule MyApp.MixProject do
+  use Mix.Project
+
+  def project do
+    [
+      app: :my_app,
+      version: "0.1.0",
+      elixir: "~> 1.15",
+      deps: [{:junit_formatter, "~> 3.3", only: :test}]
+    ]
+  end
+end
@@ -0,0 +1,12 @@
+defmodule MyApp.PostTest do
+  use ExUnit.Case, async: true
+
+  test "publishes the post" do
+    assert MyApp.Post.publish(%{title: "Quidditch"}) == :ok
+  end
+
+  @tag :skip
+  test "schedules the post" do
+    assert MyApp.Post.schedule(%{title: "Quidditch"}) == :ok
+  end
+end
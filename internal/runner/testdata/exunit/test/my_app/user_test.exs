@@ -0,0 +1,13 @@
+defmodule MyApp.UserTest do
+  use ExUnit.Case, async: true
+
+  test "saves the user" do
+    assert MyApp.User.save(%{email: "harry@hogwarts.edu"}) == :ok
+  end
+
+  describe "validate/1" do
+    test "rejects invalid emails" do
+      assert MyApp.User.validate(%{email: "harry"}) == {:error, :invalid_email}
+    end
+  end
+end
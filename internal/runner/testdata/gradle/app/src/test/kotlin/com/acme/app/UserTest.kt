@@ -0,0 +1,26 @@
+package com.acme.app
+
+import org.junit.jupiter.api.Assertions.assertFalse
+import org.junit.jupiter.api.Assertions.assertTrue
+import org.junit.jupiter.api.Nested
+import org.junit.jupiter.api.Test
+
+class UserTest {
+    @Test
+    fun savesTheUser() {
+        assertTrue(User("harry@hogwarts.edu").save())
+    }
+
+    @Test
+    fun validatesTheEmail() {
+        assertTrue(User("harry").valid())
+    }
+
+    @Nested
+    inner class Validation {
+        @Test
+        fun rejectsBlankNames() {
+            assertFalse(User("").valid())
+        }
+    }
+}
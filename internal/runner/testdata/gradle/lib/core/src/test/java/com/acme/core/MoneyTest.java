@@ -0,0 +1,26 @@
+package com.acme.core;
+
+import static org.junit.jupiter.api.Assertions.assertEquals;
+
+import org.junit.jupiter.api.Disabled;
+import org.junit.jupiter.api.Test;
+import org.junit.jupiter.params.ParameterizedTest;
+import org.junit.jupiter.params.provider.ValueSource;
+
+class MoneyTest {
+    @Test
+    void addsAmounts() {
+        assertEquals(Money.of(3), Money.of(1).plus(Money.of(2)));
+    }
+
+    @ParameterizedTest
+    @ValueSource(strings = {"1.005"})
+    void roundsHalfUp(String amount) {
+        assertEquals("1.01", Money.parse(amount).toString());
+    }
+
+    @Test
+    @Disabled("currency conversion isn't implemented yet")
+    void convertsCurrencies() {
+    }
+}
@@ -0,0 +1,3 @@
+rootProject.name = "acme"
+
+include("app", "lib:core")
@@ -0,0 +1,11 @@
+require "test_helper"
+
+class PostTest < ActiveSupport::TestCase
+  test "has a title" do
+    assert Post.new(title: "Hello").title
+  end
+
+  test "is published?" do
+    skip "not implemented yet"
+  end
+end
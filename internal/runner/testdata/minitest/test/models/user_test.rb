@@ -0,0 +1,11 @@
+require "test_helper"
+
+class UserTest < ActiveSupport::TestCase
+  test "saves the user" do
+    assert User.new(email: "user@example.com").save
+  end
+
+  test "validates the email" do
+    assert_not User.new(email: "").valid?
+  end
+end
@@ -0,0 +1,11 @@
+const assert = require("assert");
+
+describe("Hook", function () {
+  before(function () {
+    throw new Error("database is not available");
+  });
+
+  it("is never run", function () {
+    assert.ok(true);
+  });
+});
@@ -0,0 +1,13 @@
+const assert = require("assert");
+
+describe("Spell", function () {
+  describe("Expelliarmus", function () {
+    it("disarms the opponent", function () {
+      assert.ok(true);
+    });
+
+    it.skip("works underwater", function () {
+      assert.ok(true);
+    });
+  });
+});
@@ -0,0 +1,11 @@
+const assert = require("assert");
+
+describe("User", function () {
+  it("saves the user", function () {
+    assert.ok(true);
+  });
+
+  it("validates the email", function () {
+    assert.strictEqual("harry@hogwarts", "harry@hogwarts.edu");
+  });
+});
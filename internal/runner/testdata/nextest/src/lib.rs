@@ -0,0 +1,26 @@
+pub fn damage(spell: &str) -> u32 {
+    match spell {
+        "expelliarmus" => 0,
+        "stupefy" => 10,
+        _ => 1,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn expelliarmus_disarms() {
+        assert_eq!(damage("expelliarmus"), 0);
+    }
+
+    #[test]
+    fn stupefy_stuns() {
+        assert_eq!(damage("stupefy"), 20);
+    }
+
+    #[test]
+    #[ignore]
+    fn avada_kedavra_is_forbidden() {}
+}
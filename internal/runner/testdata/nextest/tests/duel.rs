@@ -0,0 +1,4 @@
+#[test]
+fn duel_ends() {
+    assert_eq!(spells::damage("lumos"), 1);
+}
@@ -0,0 +1,18 @@
+<?php
+
+namespace Tests\Feature;
+
+use PHPUnit\Framework\TestCase;
+
+final class CheckoutTest extends TestCase
+{
+    public function testChargesTheCard(): void
+    {
+        $this->assertSame(100, 50 + 50);
+    }
+
+    public function testSendsTheReceipt(): void
+    {
+        $this->markTestSkipped('The mailer is not configured');
+    }
+}
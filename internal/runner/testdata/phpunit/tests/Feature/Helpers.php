@@ -0,0 +1,8 @@
+<?php
+
+namespace Tests\Feature;
+
+function cents(int $dollars): int
+{
+    return $dollars * 100;
+}
@@ -0,0 +1,28 @@
+<?php
+
+namespace Tests\Unit;
+
+use PHPUnit\Framework\Attributes\DataProvider;
+use PHPUnit\Framework\TestCase;
+
+final class UserTest extends TestCase
+{
+    public function testSavesTheUser(): void
+    {
+        $this->assertTrue(true);
+    }
+
+    public static function emailProvider(): array
+    {
+        return [
+            ['harry@hogwarts.edu'],
+            ['harry@hogwarts'],
+        ];
+    }
+
+    #[DataProvider('emailProvider')]
+    public function testValidatesTheEmail(string $email): void
+    {
+        $this->assertStringEndsWith('.edu', $email);
+    }
+}
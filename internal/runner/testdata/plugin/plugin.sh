@@ -0,0 +1,69 @@
+#!/bin/sh
+# A runner plugin for the tests of the plugin runner. It saves each request to
+# $PLUGIN_REQUEST_DIR/<type>.json when set, and fails the request named by
+# $PLUGIN_ERROR with an error response.
+set -e
+
+request=$(cat)
+if [ -n "$PLUGIN_REQUEST_DIR" ]; then
+  printf '%s' "$request" > "$PLUGIN_REQUEST_DIR/$1.json"
+fi
+
+if [ "$1" = "$PLUGIN_ERROR" ]; then
+  echo '{"protocol_version": 1, "error": "something went wrong"}'
+  exit 0
+fi
+
+case "$1" in
+  handshake)
+    cat <<JSON
+{
+  "protocol_version": ${PLUGIN_PROTOCOL_VERSION:-1},
+  "name": "Fake",
+  "features": {"split_by_file": true, "split_by_example": true, "auto_retry": true, "mute": true},
+  "result_path": "tmp/results.json",
+  "result_format": "json"
+}
+JSON
+    ;;
+  discover)
+    echo '{"protocol_version": 1, "targets": ["//math:test", "//strings:test"]}'
+    ;;
+  examples)
+    cat <<JSON
+{
+  "protocol_version": 1,
+  "examples": [
+    {"scope": "math", "name": "adds", "path": "//math:test"},
+    {"scope": "math", "name": "divides", "path": "//math:test"},
+    {"scope": "strings", "name": "joins", "path": "//strings:test"}
+  ]
+}
+JSON
+    ;;
+  command)
+    echo '{"protocol_version": 1, "command": ["sh", "-c", "echo $FAKE_TARGET; exit 3"], "env": {"FAKE_TARGET": "//math:test"}}'
+    ;;
+  parse-results)
+    cat <<JSON
+{
+  "protocol_version": 1,
+  "results": [
+    {"scope": "math", "name": "adds", "path": "//math:test", "status": "passed", "duration": 0.5},
+    {
+      "scope": "math",
+      "name": "divides",
+      "path": "//math:test",
+      "status": "failed",
+      "duration": 1.25,
+      "failure": {"message": "division by zero", "backtrace": ["math_test.go:12"]}
+    }
+  ]
+}
+JSON
+    ;;
+  *)
+    echo "unknown request $1" >&2
+    exit 1
+    ;;
+esac
@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TRXTestResult represents a single <UnitTestResult> element of a TRX (Visual Studio test results) file,
+// joined with the <UnitTest> definition of the test.
+type TRXTestResult struct {
+	// TestName is the display name of the test. xUnit includes the class in it, e.g.
+	// "Acme.Tests.UserTests.SavesTheUser", while NUnit and MSTest only write the method,
+	// followed by the arguments of a parameterized test, e.g. "Rounds(1.005m)".
+	TestName string
+	// ClassName is the fully qualified name of the test class, e.g. "Acme.Tests.UserTests".
+	ClassName string
+	// Outcome is the outcome attribute of the result, e.g. "Passed", "Failed" or "NotExecuted".
+	Outcome  string
+	Result   TestStatus // passed | failed | skipped
+	Duration time.Duration
+	Message  string
+	// StackTrace is the stack trace of a failed test, one frame per line.
+	StackTrace string
+}
+
+// Attempt returns the result of the test, including its duration and failure.
+func (r TRXTestResult) Attempt() TestAttempt {
+	attempt := TestAttempt{Status: r.Result, Duration: r.Duration}
+	if r.Result == TestStatusFailed {
+		attempt.Failure = trxTestFailure(r.Message, r.StackTrace)
+	}
+	return attempt
+}
+
+// trxTestFailure builds a TestFailure from the <ErrorInfo> element of a failed test.
+func trxTestFailure(message, stackTrace string) *TestFailure {
+	var backtrace []string
+	for _, line := range strings.Split(strings.TrimSpace(stackTrace), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			backtrace = append(backtrace, line)
+		}
+	}
+
+	// Reports written on Windows have CRLF line endings.
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	if message == "" && len(backtrace) == 0 {
+		return nil
+	}
+	return &TestFailure{Message: message, Backtrace: backtrace}
+}
+
+type trxUnitTestResult struct {
+	TestID    string `xml:"testId,attr"`
+	TestName  string `xml:"testName,attr"`
+	Outcome   string `xml:"outcome,attr"`
+	Duration  string `xml:"duration,attr"`
+	ErrorInfo struct {
+		Message    string `xml:"Message"`
+		StackTrace string `xml:"StackTrace"`
+	} `xml:"Output>ErrorInfo"`
+}
+
+type trxUnitTest struct {
+	ID         string `xml:"id,attr"`
+	TestMethod struct {
+		ClassName string `xml:"className,attr"`
+	} `xml:"TestMethod"`
+}
+
+type trxTestRun struct {
+	XMLName         xml.Name            `xml:"TestRun"`
+	Results         []trxUnitTestResult `xml:"Results>UnitTestResult"`
+	TestDefinitions []trxUnitTest       `xml:"TestDefinitions>UnitTest"`
+}
+
+func loadAndParseTRX(path string) ([]TRXTestResult, error) {
+	trxFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open TRX file %s: %w", path, err)
+	}
+	defer trxFile.Close()
+
+	byteValue, err := io.ReadAll(trxFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TRX file %s: %w", path, err)
+	}
+
+	var testRun trxTestRun
+	if err := xml.Unmarshal(byteValue, &testRun); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal TRX file %s: %w", path, err)
+	}
+
+	definitions := make(map[string]trxUnitTest, len(testRun.TestDefinitions))
+	for _, definition := range testRun.TestDefinitions {
+		definitions[definition.ID] = definition
+	}
+
+	results := make([]TRXTestResult, 0, len(testRun.Results))
+	for _, r := range testRun.Results {
+		definition := definitions[r.TestID]
+		results = append(results, TRXTestResult{
+			TestName:   r.TestName,
+			ClassName:  definition.TestMethod.ClassName,
+			Outcome:    r.Outcome,
+			Result:     trxTestStatus(r.Outcome),
+			Duration:   parseTRXDuration(r.Duration),
+			Message:    r.ErrorInfo.Message,
+			StackTrace: r.ErrorInfo.StackTrace,
+		})
+	}
+
+	return results, nil
+}
+
+// trxTestStatus maps the outcome of a TRX test result to a test status.
+// Outcomes that don't tell whether the test passed, such as "Aborted" or "Timeout", are failures.
+func trxTestStatus(outcome string) TestStatus {
+	switch outcome {
+	case "Passed", "PassedButRunAborted", "Completed", "Warning":
+		return TestStatusPassed
+	case "NotExecuted", "NotRunnable", "Inconclusive", "Pending":
+		return TestStatusSkipped
+	default:
+		return TestStatusFailed
+	}
+}
+
+// parseTRXDuration parses the duration of a TRX test result, which is written as "hh:mm:ss.fffffff".
+// It returns zero if the duration is missing or malformed.
+func parseTRXDuration(duration string) time.Duration {
+	parts := strings.Split(duration, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + secondsToDuration(seconds)
+}
@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadAndParseTRX(t *testing.T) {
+	results, err := loadAndParseTRX("./testdata/dotnet/results.trx")
+	if err != nil {
+		t.Fatalf("loadAndParseTRX() error = %v", err)
+	}
+
+	want := []TRXTestResult{
+		{
+			TestName:  "Acme.Tests.UserTests.SavesTheUser",
+			ClassName: "Acme.Tests.UserTests",
+			Outcome:   "Passed",
+			Result:    TestStatusPassed,
+			Duration:  3125 * time.Microsecond,
+		},
+		{
+			TestName:   "Acme.Tests.UserTests.ValidatesTheEmail",
+			ClassName:  "Acme.Tests.UserTests",
+			Outcome:    "Failed",
+			Result:     TestStatusFailed,
+			Duration:   12500 * time.Microsecond,
+			Message:    "Assert.True() Failure\r\nExpected: True\r\nActual:   False",
+			StackTrace: "   at Acme.Tests.UserTests.ValidatesTheEmail() in /home/buildkite/acme/Acme.Tests/UserTests.cs:line 16\r\n   at System.RuntimeMethodHandle.InvokeMethod(Object target, Void** arguments, Signature sig, Boolean isConstructor)\r\n   at System.Reflection.MethodBaseInvoker.InvokeWithNoArgs(Object obj, BindingFlags invokeAttr)",
+		},
+		{
+			TestName:  "Acme.Tests.UserTests+Validation.RejectsBlankNames",
+			ClassName: "Acme.Tests.UserTests+Validation",
+			Outcome:   "Passed",
+			Result:    TestStatusPassed,
+			Duration:  800 * time.Microsecond,
+		},
+		{
+			TestName:   `Acme.Tests.Billing.InvoiceTests.Rounds(amount: "1.005")`,
+			ClassName:  "Acme.Tests.Billing.InvoiceTests",
+			Outcome:    "Failed",
+			Result:     TestStatusFailed,
+			Duration:   4 * time.Millisecond,
+			Message:    "Assert.Equal() Failure: Values differ\r\nExpected: 2\r\nActual:   3",
+			StackTrace: "   at Acme.Tests.Billing.InvoiceTests.Rounds(String amount) in /home/buildkite/acme/Acme.Tests/Billing/InvoiceTests.cs:line 12",
+		},
+		{
+			TestName:  `Acme.Tests.Billing.InvoiceTests.Rounds(amount: "2.5")`,
+			ClassName: "Acme.Tests.Billing.InvoiceTests",
+			Outcome:   "Passed",
+			Result:    TestStatusPassed,
+			Duration:  200 * time.Microsecond,
+		},
+		{
+			TestName:  "Acme.Tests.Billing.InvoiceTests.ConvertsCurrencies",
+			ClassName: "Acme.Tests.Billing.InvoiceTests",
+			Outcome:   "NotExecuted",
+			Result:    TestStatusSkipped,
+		},
+	}
+	if diff := cmp.Diff(want, results); diff != "" {
+		t.Errorf("loadAndParseTRX() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadAndParseTRX_FileNotFound(t *testing.T) {
+	_, err := loadAndParseTRX("./testdata/dotnet/missing.trx")
+	if err == nil {
+		t.Errorf("loadAndParseTRX() error = nil, want an error")
+	}
+}
+
+func TestTRXTestResultAttempt(t *testing.T) {
+	result := TRXTestResult{
+		Result:     TestStatusFailed,
+		Duration:   4 * time.Millisecond,
+		Message:    "Assert.Equal() Failure: Values differ\r\nExpected: 2\r\nActual:   3",
+		StackTrace: "   at Acme.Tests.Billing.InvoiceTests.Rounds(String amount) in /home/buildkite/acme/Acme.Tests/Billing/InvoiceTests.cs:line 12\r\n",
+	}
+
+	want := TestAttempt{
+		Status:   TestStatusFailed,
+		Duration: 4 * time.Millisecond,
+		Failure: &TestFailure{
+			Message:   "Assert.Equal() Failure: Values differ\nExpected: 2\nActual:   3",
+			Backtrace: []string{"at Acme.Tests.Billing.InvoiceTests.Rounds(String amount) in /home/buildkite/acme/Acme.Tests/Billing/InvoiceTests.cs:line 12"},
+		},
+	}
+	if diff := cmp.Diff(want, result.Attempt()); diff != "" {
+		t.Errorf("TRXTestResult.Attempt() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseTRXDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"00:00:00.0031250": 3125 * time.Microsecond,
+		"01:02:03.5":       time.Hour + 2*time.Minute + 3500*time.Millisecond,
+		"00:00:00":         0,
+		"":                 0,
+		"not a duration":   0,
+	}
+
+	for duration, want := range cases {
+		if got := parseTRXDuration(duration); got != want {
+			t.Errorf("parseTRXDuration(%q) = %v, want %v", duration, got, want)
+		}
+	}
+}
@@ -79,10 +79,11 @@ func (v Vitest) DiscoverTestTargets() ([]string, error) {
 }
 
 func (v Vitest) Run(result *RunResult, testCases []plan.TestCase, retry bool) error {
-	cmd, err := buildCommand(v, testCases, retry)
+	cmd, cleanup, err := buildCommand(v, testCases, retry)
 	if err != nil {
 		return err
 	}
+	defer cleanup()
 
 	cmdErr := runAndForwardSignal(cmd)
 
@@ -150,13 +151,19 @@ func (v Vitest) Run(result *RunResult, testCases []plan.TestCase, retry bool) er
 				continue
 			}
 
-			result.RecordTestResult(testCase, status)
+			result.RecordTestAttempt(testCase, example.attempt(status))
 		}
 	}
 
 	return cmdErr
 }
 
+func (v Vitest) testListPaths(testCases []plan.TestCase, retry bool) []string {
+	testPaths := pathsFromTestCases(testCases)
+	slices.Sort(testPaths)
+	return slices.Compact(testPaths)
+}
+
 func (v Vitest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (string, []string, error) {
 	cmd := v.TestCommand
 	if retry {
@@ -168,9 +175,7 @@ func (v Vitest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (strin
 		return "", []string{}, err
 	}
 
-	testPaths := pathsFromTestCases(testCases)
-	slices.Sort(testPaths)
-	testPaths = slices.Compact(testPaths)
+	testPaths := v.testListPaths(testCases, retry)
 
 	if retry {
 		idx := slices.Index(words, "{{testNamePattern}}")
@@ -196,10 +201,10 @@ func (v Vitest) CommandNameAndArgs(testCases []plan.TestCase, retry bool) (strin
 		}
 	} else {
 		idx := slices.Index(words, "{{testExamples}}")
-		if idx < 0 {
-			words = append(words, testPaths...)
-		} else {
+		if idx >= 0 {
 			words = slices.Replace(words, idx, idx+1, testPaths...)
+		} else if !hasTestListFile(words) {
+			words = append(words, testPaths...)
 		}
 	}
 
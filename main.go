@@ -72,6 +72,16 @@ func backfillCommitMetadata(ctx context.Context, cmd *cli.Command) error {
 	return command.BackfillCommitMetadata(ctx, &cfg, &git.ExecGitRunner{})
 }
 
+func checkRunnerPlugin(ctx context.Context, cmd *cli.Command) error {
+	debug.SetDebug(cmd.Root().Bool("debug"))
+
+	if cfg.RunnerPlugin == "" {
+		return errors.New("bktec tools check-runner-plugin: --runner-plugin (or BUILDKITE_TEST_ENGINE_RUNNER_PLUGIN) must not be blank")
+	}
+
+	return command.CheckRunnerPlugin(&cfg, os.Stdout, cmd.Bool("run"))
+}
+
 func printVersion(ctx context.Context, cmd *cli.Command, versionFlag bool) error {
 	// Flag will be true if called with `bktec [...] --version`
 	if !versionFlag {
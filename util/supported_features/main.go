@@ -48,6 +48,14 @@ func main() {
 		runner.NewPytest(runnerConfig),
 		runner.NewGoTest(runnerConfig),
 		runner.NewCucumber(runnerConfig),
+		runner.NewMinitest(runnerConfig),
+		runner.NewMocha(runnerConfig),
+		runner.NewPHPUnit(runnerConfig),
+		runner.NewNextest(runnerConfig),
+		runner.NewGradle(runnerConfig),
+		runner.NewMaven(runnerConfig),
+		runner.NewDotnet(runnerConfig),
+		runner.NewExUnit(runnerConfig),
 		custom,
 	}
 